@@ -2,17 +2,20 @@ module github.com/jimmyflamingo/pkg
 
 go 1.17
 
+require (
+	github.com/coreos/pkg v0.0.0-20180928190104-399ea9e2e55f
+	github.com/hashicorp/errwrap v1.1.0
+	github.com/hashicorp/go-hclog v0.16.2
+	github.com/hashicorp/go-multierror v1.1.1
+	github.com/mitchellh/panicwrap v1.0.0
+	github.com/zclconf/go-cty v1.9.1
+)
+
 require (
 	github.com/coreos/go-systemd v0.0.0-20191104093116-d3cd4ed1dbcf // indirect
-	github.com/coreos/pkg v0.0.0-20180928190104-399ea9e2e55f // indirect
 	github.com/fatih/color v1.7.0 // indirect
-	github.com/hashicorp/errwrap v1.1.0 // indirect
-	github.com/hashicorp/go-hclog v0.16.2 // indirect
-	github.com/hashicorp/go-multierror v1.1.1 // indirect
 	github.com/mattn/go-colorable v0.1.4 // indirect
 	github.com/mattn/go-isatty v0.0.10 // indirect
-	github.com/mitchellh/panicwrap v1.0.0 // indirect
-	github.com/zclconf/go-cty v1.9.1 // indirect
 	golang.org/x/sys v0.0.0-20191008105621-543471e840be // indirect
 	golang.org/x/text v0.3.5 // indirect
 )
@@ -0,0 +1,78 @@
+package tbdiags
+
+// AcknowledgementKey returns a string that identifies the given diagnostic
+// for the purposes of Diagnostics.Acknowledge. This is the same as its
+// MatchKey, so that a warning is still recognized as acknowledged if it is
+// produced again with only volatile details changed.
+func AcknowledgementKey(diag Diagnostic) string {
+	return MatchKey(diag)
+}
+
+// Acknowledge returns a copy of the receiver where each warning whose
+// AcknowledgementKey is one of the given keys is marked as acknowledged.
+//
+// Acknowledged warnings have already been reported to the user, so they are
+// excluded by ErrWithWarnings, NonFatalErr and ErrWith. This allows one layer
+// of a pipeline to report its warnings and then pass its diagnostics further
+// up without the same warnings being reported again at every layer.
+//
+// Errors cannot be acknowledged, and are returned unchanged.
+func (diags Diagnostics) Acknowledge(keys ...string) Diagnostics {
+	want := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		want[key] = true
+	}
+	return diags.acknowledge(func(diag Diagnostic) bool {
+		return want[AcknowledgementKey(diag)]
+	})
+}
+
+// AcknowledgeAll is like Acknowledge except that it marks all warnings in the
+// receiver as acknowledged.
+func (diags Diagnostics) AcknowledgeAll() Diagnostics {
+	return diags.acknowledge(func(Diagnostic) bool {
+		return true
+	})
+}
+
+func (diags Diagnostics) acknowledge(match func(Diagnostic) bool) Diagnostics {
+	if len(diags) == 0 {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		if !diag.Severity().isError() && !IsAcknowledged(diag) && match(diag) {
+			diag = acknowledgedWarning{diag}
+		}
+		ret[i] = diag
+	}
+	return ret
+}
+
+// IsAcknowledged returns true if the given diagnostic is a warning that has
+// been marked as acknowledged using Diagnostics.Acknowledge.
+func IsAcknowledged(diag Diagnostic) bool {
+	return findDiagnostic(diag, func(diag Diagnostic) bool {
+		_, ok := diag.(acknowledgedWarning)
+		return ok
+	})
+}
+
+func (diags Diagnostics) withoutAcknowledged() Diagnostics {
+	var ret Diagnostics
+	for _, diag := range diags {
+		if !IsAcknowledged(diag) {
+			ret = append(ret, diag)
+		}
+	}
+	return ret
+}
+
+// acknowledgedWarning wraps a warning that has been acknowledged.
+type acknowledgedWarning struct {
+	Diagnostic
+}
+
+func (d acknowledgedWarning) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,36 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestDiagnosticsAcknowledge(t *testing.T) {
+	first := Sourceless(Warning, "First warning", "")
+	second := Sourceless(Warning, "Second warning", "")
+	diags := Diagnostics{first, second}
+
+	acked := diags.Acknowledge(AcknowledgementKey(first))
+	if !IsAcknowledged(acked[0]) {
+		t.Errorf("first warning is not acknowledged")
+	}
+	if IsAcknowledged(acked[1]) {
+		t.Errorf("second warning is acknowledged")
+	}
+	if IsAcknowledged(diags[0]) {
+		t.Errorf("receiver was modified")
+	}
+
+	err := acked.NonFatalErr()
+	if got, want := err.Error(), "Second warning"; got != want {
+		t.Errorf("wrong error %q; want %q", got, want)
+	}
+
+	if err := diags.AcknowledgeAll().ErrWithWarnings(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	withErr := diags.Append(Sourceless(Error, "Bad thing", "")).AcknowledgeAll()
+	if got, want := withErr.ErrWithWarnings().Error(), "Bad thing"; got != want {
+		t.Errorf("wrong error %q; want %q", got, want)
+	}
+}
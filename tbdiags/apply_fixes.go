@@ -0,0 +1,261 @@
+package tbdiags
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// WritableFS is the file system that ApplyFixes reads and changes source
+// files in, using the filenames from source ranges.
+type WritableFS interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+}
+
+// DirFS returns a WritableFS that resolves relative filenames against the
+// given directory and uses absolute filenames as they are. Files that are
+// written keep their existing permissions.
+func DirFS(dir string) WritableFS {
+	return dirFS(dir)
+}
+
+type dirFS string
+
+func (d dirFS) path(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(string(d), name)
+}
+
+func (d dirFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(d.path(name))
+}
+
+func (d dirFS) WriteFile(name string, data []byte) error {
+	perm := os.FileMode(0666)
+	if info, err := os.Stat(d.path(name)); err == nil {
+		perm = info.Mode().Perm()
+	}
+	return os.WriteFile(d.path(name), data, perm)
+}
+
+// ApplyFixesOptions configures ApplyFixes.
+type ApplyFixesOptions struct {
+	// DryRun causes ApplyFixes to decide which fixes it would apply, and
+	// return the result, without changing any files.
+	DryRun bool
+
+	// BackupSuffix, if set, causes the original content of each file to
+	// be written to a file with the same name followed by the suffix,
+	// such as ".orig", before the file is changed.
+	BackupSuffix string
+
+	// Resolve decides between conflicting fixes, as described for
+	// FixResolver. If nil, the fix that was accepted first is kept.
+	Resolve FixResolver
+}
+
+// FixResolver decides between the suggested fixes of two diagnostics whose
+// fixes conflict, returning true if the fix of the candidate should be
+// applied instead of the fix of the diagnostic that was already accepted.
+// A resolver might prompt the user, for interactive tools, or compare
+// priorities, as PreferMoreSevere does.
+type FixResolver func(accepted, candidate Diagnostic) bool
+
+// PreferMoreSevere is a FixResolver that prefers the fix of the more severe
+// diagnostic, keeping the accepted fix if they are equally severe.
+func PreferMoreSevere(accepted, candidate Diagnostic) bool {
+	return candidate.Severity().MoreSevereThan(accepted.Severity())
+}
+
+// ApplyFixes applies the preferred suggested fix of each of the given
+// diagnostics to the files in the given file system, so that tools can
+// offer a "--fix" option.
+//
+// Fixes are considered in the order of the diagnostics. A fix is skipped
+// if any of its edits has a range that is not a PrecisionExact range
+// within a file, or that ClampRange would change because it doesn't fit
+// the current contents of the file, or if any of its edits overlaps an
+// edit of a fix that was accepted earlier, unless opts.Resolve prefers it
+// to all of the fixes it conflicts with, in which case those are skipped
+// instead. The fixable
+// diagnostics whose fixes were applied and skipped are returned in the
+// order they were given. Diagnostics without fixes are in neither result.
+//
+// The error is from reading or writing a file. Files are only written
+// once all fixes have been checked, but if writing one file fails the
+// others may already have been changed.
+func ApplyFixes(fsys WritableFS, diags Diagnostics, opts ApplyFixesOptions) (applied, skipped Diagnostics, err error) {
+	srcs := make(map[string][]byte)
+	var candidates []*fixCandidate
+	for _, diag := range diags {
+		fixes := FixesOf(diag)
+		if len(fixes) == 0 {
+			continue
+		}
+		c := &fixCandidate{diag: diag, fix: fixes[0]}
+		candidates = append(candidates, c)
+
+		ok, err := fixValid(fsys, c.fix, srcs)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !ok {
+			continue
+		}
+		conflicts := conflictingFixes(c, candidates)
+		for _, other := range conflicts {
+			if opts.Resolve == nil || !opts.Resolve(other.diag, c.diag) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		for _, other := range conflicts {
+			other.accepted = false
+		}
+		c.accepted = true
+	}
+
+	edits := make(map[string][]TextEdit)
+	for _, c := range candidates {
+		if !c.accepted {
+			skipped = append(skipped, c.diag)
+			continue
+		}
+		applied = append(applied, c.diag)
+		for _, edit := range c.fix.Edits {
+			edits[edit.Range.Filename] = append(edits[edit.Range.Filename], edit)
+		}
+	}
+	if opts.DryRun {
+		return applied, skipped, nil
+	}
+
+	names := make([]string, 0, len(edits))
+	for name := range edits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if opts.BackupSuffix != "" {
+			if err := fsys.WriteFile(name+opts.BackupSuffix, srcs[name]); err != nil {
+				return applied, skipped, err
+			}
+		}
+		if err := fsys.WriteFile(name, applyEdits(srcs[name], edits[name])); err != nil {
+			return applied, skipped, err
+		}
+	}
+	return applied, skipped, nil
+}
+
+// fixCandidate is the preferred fix of a diagnostic given to ApplyFixes.
+type fixCandidate struct {
+	diag     Diagnostic
+	fix      SuggestedFix
+	accepted bool
+}
+
+// conflictingFixes returns the accepted candidates whose fixes overlap the
+// fix of the given candidate.
+func conflictingFixes(c *fixCandidate, candidates []*fixCandidate) []*fixCandidate {
+	var ret []*fixCandidate
+	for _, other := range candidates {
+		if other.accepted && fixesOverlap(c.fix, other.fix) {
+			ret = append(ret, other)
+		}
+	}
+	return ret
+}
+
+// fixValid returns true if all of the edits of the given fix are valid for
+// the sources of their files, which are read into srcs as needed, and
+// don't overlap each other.
+func fixValid(fsys WritableFS, fix SuggestedFix, srcs map[string][]byte) (bool, error) {
+	if len(fix.Edits) == 0 {
+		return false, nil
+	}
+	for i, edit := range fix.Edits {
+		rng := edit.Range
+		if rng.Kind != SubjectFile || rng.Precision != PrecisionExact {
+			return false, nil
+		}
+		src, ok := srcs[rng.Filename]
+		if !ok {
+			var err error
+			src, err = fsys.ReadFile(rng.Filename)
+			if err != nil {
+				return false, err
+			}
+			srcs[rng.Filename] = src
+		}
+		// A range that ClampRange would change doesn't fit the source, such
+		// as because it extends past the end or splits a character, so
+		// the fix was made for a different version of the file.
+		if ClampRange(rng, src) != rng {
+			return false, nil
+		}
+		for _, other := range fix.Edits[:i] {
+			if editsOverlap(edit, other) {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// fixesOverlap returns true if any edit of one of the given fixes overlaps
+// any edit of the other.
+func fixesOverlap(a, b SuggestedFix) bool {
+	_, _, ok := overlappingEdits(a, b)
+	return ok
+}
+
+// overlappingEdits returns the first pair of overlapping edits from the
+// given fixes, or false if there is none.
+func overlappingEdits(a, b SuggestedFix) (TextEdit, TextEdit, bool) {
+	for _, aEdit := range a.Edits {
+		for _, bEdit := range b.Edits {
+			if editsOverlap(aEdit, bEdit) {
+				return aEdit, bEdit, true
+			}
+		}
+	}
+	return TextEdit{}, TextEdit{}, false
+}
+
+// editsOverlap returns true if the two edits change overlapping parts of
+// the same file. Insertions at the same position overlap, because the
+// order of the inserted text would be ambiguous.
+func editsOverlap(a, b TextEdit) bool {
+	if a.Range.Filename != b.Range.Filename {
+		return false
+	}
+	aStart, aEnd := a.Range.Start.Byte, a.Range.End.Byte
+	bStart, bEnd := b.Range.Start.Byte, b.Range.End.Byte
+	return aStart == bStart || (aStart < bEnd && bStart < aEnd)
+}
+
+// applyEdits returns the result of applying the given non-overlapping
+// edits to the given source.
+func applyEdits(src []byte, edits []TextEdit) []byte {
+	sorted := make([]TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Range.Start.Byte < sorted[j].Range.Start.Byte
+	})
+
+	var ret []byte
+	pos := 0
+	for _, edit := range sorted {
+		ret = append(ret, src[pos:edit.Range.Start.Byte]...)
+		ret = append(ret, edit.NewText...)
+		pos = edit.Range.End.Byte
+	}
+	return append(ret, src[pos:]...)
+}
@@ -0,0 +1,116 @@
+package tbdiags
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// memFS is a WritableFS for tests, holding files in memory.
+type memFS map[string]string
+
+func (m memFS) ReadFile(name string) ([]byte, error) {
+	src, ok := m[name]
+	if !ok {
+		return nil, errors.New("no such file")
+	}
+	return []byte(src), nil
+}
+
+func (m memFS) WriteFile(name string, data []byte) error {
+	m[name] = string(data)
+	return nil
+}
+
+// editRange returns an exact range covering the given bytes of a file.
+func editRange(filename string, start, end int) SourceRange {
+	return SourceRange{
+		Filename: filename,
+		Start:    SourcePos{Line: 1, Column: start + 1, Byte: start},
+		End:      SourcePos{Line: 1, Column: end + 1, Byte: end},
+	}
+}
+
+func TestApplyFixes(t *testing.T) {
+	fixable := func(summary string, edits ...TextEdit) Diagnostic {
+		return WithFixes(Sourceless(Warning, summary, ""), SuggestedFix{Message: "Fix it", Edits: edits})
+	}
+	diags := Diagnostics{
+		fixable("Rename", TextEdit{editRange("a.tb", 0, 3), "bar"}),
+		Sourceless(Warning, "Not fixable", ""),
+		fixable("Overlapping", TextEdit{editRange("a.tb", 2, 5), "x"}),
+		fixable("Delete", TextEdit{editRange("a.tb", 7, 13), ""}, TextEdit{editRange("b.tb", 0, 0), "# "}),
+		fixable("Out of range", TextEdit{editRange("b.tb", 3, 99), ""}),
+		fixable("Imprecise", TextEdit{LineRange("b.tb", 1), ""}),
+		fixable("Split character", TextEdit{editRange("c.tb", 1, 2), ""}),
+	}
+	summaries := func(diags Diagnostics) []string {
+		var ret []string
+		for _, diag := range diags {
+			ret = append(ret, diag.Description().Summary)
+		}
+		return ret
+	}
+
+	fsys := memFS{"a.tb": "foo = 1 # old\n", "b.tb": "x = 2\n", "c.tb": "\u00e9 = 3\n"}
+	applied, skipped, err := ApplyFixes(fsys, diags, ApplyFixesOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := summaries(applied), []string{"Rename", "Delete"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong applied fixes\ngot:  %q\nwant: %q", got, want)
+	}
+	if got, want := summaries(skipped), []string{"Overlapping", "Out of range", "Imprecise", "Split character"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong skipped fixes\ngot:  %q\nwant: %q", got, want)
+	}
+	if fsys["a.tb"] != "foo = 1 # old\n" {
+		t.Errorf("dry run changed a file")
+	}
+
+	if _, _, err := ApplyFixes(fsys, diags, ApplyFixesOptions{BackupSuffix: ".orig"}); err != nil {
+		t.Fatal(err)
+	}
+	want := memFS{
+		"a.tb":      "bar = 1\n",
+		"a.tb.orig": "foo = 1 # old\n",
+		"b.tb":      "# x = 2\n",
+		"b.tb.orig": "x = 2\n",
+	}
+	for name, content := range want {
+		if got := fsys[name]; got != content {
+			t.Errorf("wrong content for %s\ngot:  %q\nwant: %q", name, got, content)
+		}
+	}
+
+	if _, _, err := ApplyFixes(memFS{}, diags, ApplyFixesOptions{}); err == nil {
+		t.Errorf("no error for a missing file")
+	}
+}
+
+func TestDirFS(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.tb"), []byte("a = 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	diags := Diagnostics{
+		WithFixes(Sourceless(Warning, "Rename", ""), SuggestedFix{
+			Message: "Rename a to b",
+			Edits:   []TextEdit{{editRange("main.tb", 0, 1), "b"}},
+		}),
+	}
+	if _, _, err := ApplyFixes(DirFS(dir), diags, ApplyFixesOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(filepath.Join(dir, "main.tb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(src), "b = 1\n"; got != want {
+		t.Errorf("wrong content\ngot:  %q\nwant: %q", got, want)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "main.tb")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("permissions not preserved: %v", info.Mode())
+	}
+}
@@ -0,0 +1,51 @@
+package tbdiags
+
+import (
+	"strings"
+)
+
+// ArchiveEntryRange returns a SourceRange of kind SubjectArchiveEntry for the
+// given entry of the given archive, starting at the given byte offset within
+// the archive. Use a negative offset if the offset is not known.
+//
+// The range's filename is a pseudo-path such as "module.zip!path/inside.tb",
+// so that problems found while extracting an archive point at the actual
+// offending entry rather than at the archive as a whole.
+func ArchiveEntryRange(archive, entry string, offset int64) SourceRange {
+	pos := SourcePos{Byte: int(offset)}
+	return SourceRange{
+		Filename: archive + "!" + entry,
+		Start:    pos,
+		End:      pos,
+		Kind:     SubjectArchiveEntry,
+	}
+}
+
+// ArchiveError returns an error diagnostic for the given error, which was
+// returned while reading the given entry of the given archive, such as an
+// error from archive/zip or archive/tar.
+//
+// The diagnostic's summary and detail are derived from the error message in
+// the same way as for errors passed to Diagnostics.Append, and its subject
+// is the range returned by ArchiveEntryRange.
+func ArchiveError(archive, entry string, offset int64, err error) Diagnostic {
+	summary, detail := SplitMessage(FormatError(err), MaxErrorSummaryLen)
+	rng := ArchiveEntryRange(archive, entry, offset)
+	return sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{
+			severity: Error,
+			summary:  summary,
+			detail:   detail,
+		},
+		subject: &rng,
+	}
+}
+
+// splitArchiveEntry splits the filename of a range of kind
+// SubjectArchiveEntry into the archive path and the entry name.
+func splitArchiveEntry(filename string) (archive, entry string) {
+	if i := strings.Index(filename, "!"); i >= 0 {
+		return filename[:i], filename[i+1:]
+	}
+	return filename, ""
+}
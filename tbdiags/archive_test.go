@@ -0,0 +1,41 @@
+package tbdiags
+
+import (
+	"archive/zip"
+	"strings"
+	"testing"
+)
+
+func TestArchiveError(t *testing.T) {
+	diag := ArchiveError("module.zip", "path/inside.tb", 1234, zip.ErrChecksum)
+
+	if got, want := diag.Description().Summary, "zip: checksum error"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	subj := diag.Source().Subject
+	if subj == nil {
+		t.Fatalf("no subject")
+	}
+	if got, want := subj.StartString(), "module.zip!path/inside.tb (byte 1234)"; got != want {
+		t.Errorf("wrong position\ngot:  %s\nwant: %s", got, want)
+	}
+	id := fileIDCache{}.SubjectID(subj)
+	if want := "archive:" + string(FileIDOf("module.zip")) + "!path/inside.tb"; string(id) != want {
+		t.Errorf("wrong subject ID %q; want %q", id, want)
+	}
+
+	js, err := Diagnostics{diag}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(js), `"kind":"archive"`) {
+		t.Errorf("JSON has no archive kind: %s", js)
+	}
+}
+
+func TestArchiveEntryRangeUnknownOffset(t *testing.T) {
+	rng := ArchiveEntryRange("module.tar", "main.tb", -1)
+	if got, want := rng.StartString(), "module.tar!main.tb"; got != want {
+		t.Errorf("wrong position\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+package tbdiags
+
+// DiagnosticAttributes is an optional interface implemented by diagnostics
+// that carry structured key/value data, such as resource IDs or retry
+// counts, for serializers and tooling, rather than only describing it in
+// the prose of their detail.
+//
+// The values should be JSON-compatible, such as strings, numbers, booleans
+// and slices and maps of those, so that all serializers can represent them.
+// Diagnostics.MarshalJSON encodes them as the "attributes" property.
+type DiagnosticAttributes interface {
+	Attributes() map[string]interface{}
+}
+
+// WithAttributes returns a diagnostic that is the same as the given
+// diagnostic except that it also implements DiagnosticAttributes, returning
+// the attributes of the given diagnostic, if any, merged with the given
+// attributes, which take precedence. The given map is copied.
+func WithAttributes(diag Diagnostic, attrs map[string]interface{}) Diagnostic {
+	merged := make(map[string]interface{}, len(attrs))
+	for k, v := range AttributesOf(diag) {
+		merged[k] = v
+	}
+	for k, v := range attrs {
+		merged[k] = v
+	}
+	return withAttributes{diag, merged}
+}
+
+// AttributesOf returns the attributes of the given diagnostic, if it
+// implements DiagnosticAttributes, or nil otherwise. Callers must not
+// modify the result.
+func AttributesOf(diag Diagnostic) map[string]interface{} {
+	var ret map[string]interface{}
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		a, ok := diag.(DiagnosticAttributes)
+		if ok {
+			ret = a.Attributes()
+		}
+		return ok
+	})
+	return ret
+}
+
+type withAttributes struct {
+	Diagnostic
+	attrs map[string]interface{}
+}
+
+func (d withAttributes) Attributes() map[string]interface{} {
+	return d.attrs
+}
+
+func (d withAttributes) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
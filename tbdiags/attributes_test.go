@@ -0,0 +1,32 @@
+package tbdiags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAttributes(t *testing.T) {
+	diag := WithAttributes(Sourceless(Error, "Resource failed", ""), map[string]interface{}{
+		"resource_id": "i-123",
+		"attempts":    2,
+	})
+	diag = WithOrigin(diag, "provisioner")
+	diag = WithAttributes(diag, map[string]interface{}{"attempts": 3})
+
+	want := map[string]interface{}{"resource_id": "i-123", "attempts": 3}
+	if got := AttributesOf(diag); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong attributes\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := AttributesOf(Sourceless(Error, "Other", "")); got != nil {
+		t.Errorf("unexpected attributes %#v", got)
+	}
+
+	src, err := Diagnostics{diag}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), `"attributes":{"attempts":3,"resource_id":"i-123"}`) {
+		t.Errorf("attributes missing from JSON: %s", src)
+	}
+}
@@ -0,0 +1,57 @@
+package tbdiags
+
+// Audience is the intended audience of rendered diagnostics, which decides
+// whether operator-only information is included.
+type Audience int
+
+const (
+	// AudienceUser is for end users, who are shown only the summary and
+	// detail of each diagnostic.
+	AudienceUser Audience = iota
+
+	// AudienceOperator is for support engineers and operators, who are
+	// also shown operator-only information such as stack traces and
+	// internal identifiers.
+	AudienceOperator
+)
+
+// DiagnosticOperatorDetail is an optional interface implemented by
+// diagnostics that have additional detail intended only for operators, so
+// that a single diagnostic can serve both end users and support engineers.
+type DiagnosticOperatorDetail interface {
+	OperatorDetail() string
+}
+
+// WithOperatorDetail returns a diagnostic that is the same as the given
+// diagnostic except that it also implements DiagnosticOperatorDetail,
+// returning the given detail.
+func WithOperatorDetail(diag Diagnostic, detail string) Diagnostic {
+	return withOperatorDetail{diag, detail}
+}
+
+// OperatorDetail returns the operator-only detail of the given diagnostic,
+// or an empty string if it doesn't implement DiagnosticOperatorDetail.
+func OperatorDetail(diag Diagnostic) string {
+	var ret string
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		od, ok := diag.(DiagnosticOperatorDetail)
+		if ok {
+			ret = od.OperatorDetail()
+		}
+		return ok
+	})
+	return ret
+}
+
+type withOperatorDetail struct {
+	Diagnostic
+	detail string
+}
+
+func (d withOperatorDetail) OperatorDetail() string {
+	return d.detail
+}
+
+func (d withOperatorDetail) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
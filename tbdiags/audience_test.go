@@ -0,0 +1,57 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestWithOperatorDetail(t *testing.T) {
+	diags := Diagnostics{
+		WithOperatorDetail(
+			Sourceless(Error, "Request failed", "Please try again later."),
+			"request id: 1234",
+		),
+	}
+
+	t.Run("user", func(t *testing.T) {
+		got := (&Renderer{}).RenderString(diags)
+		want := `Error: Request failed
+
+Please try again later.
+
+`
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+	t.Run("operator", func(t *testing.T) {
+		got := (&Renderer{Audience: AudienceOperator}).RenderString(diags)
+		want := `Error: Request failed
+
+Please try again later.
+
+Operator detail:
+request id: 1234
+
+`
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
+
+func TestStackedWrappers(t *testing.T) {
+	diag := Sourceless(Error, "Invalid mode", "")
+	diag = WithValidValues(diag, []string{"fast", "slow"})
+	diag = Escalate(diag, Error, "strict")
+	diag = WithOperatorDetail(diag, "request id: 1234")
+
+	if got, want := OperatorDetail(diag), "request id: 1234"; got != want {
+		t.Errorf("wrong operator detail %q; want %q", got, want)
+	}
+	if got := ValidValues(diag); len(got) != 2 {
+		t.Errorf("wrong valid values %#v", got)
+	}
+	if esc, ok := EscalationOf(diag); !ok || esc.Policy != "strict" {
+		t.Errorf("wrong escalation %#v, %t", esc, ok)
+	}
+}
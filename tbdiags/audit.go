@@ -0,0 +1,138 @@
+package tbdiags
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+)
+
+// AuditAction is the kind of change that a policy made to a diagnostic.
+type AuditAction string
+
+const (
+	AuditPromoted   AuditAction = "promoted"
+	AuditDemoted    AuditAction = "demoted"
+	AuditSuppressed AuditAction = "suppressed"
+)
+
+// AuditEntry records one change that a policy made to a diagnostic.
+type AuditEntry struct {
+	Action AuditAction
+
+	// Rule is a user-facing description of the policy or rule that made
+	// the change, such as "strict mode".
+	Rule string
+
+	// From and To are the severities before and after the change. To is
+	// the same as From for AuditSuppressed.
+	From, To Severity
+
+	// Summary, Address and Subject identify the diagnostic that was
+	// changed. Subject is the start of its subject range as returned by
+	// SourceRange.StartString, or empty if it has none.
+	Summary, Address, Subject string
+}
+
+// AuditLog accumulates a record of the changes that policies made to the
+// severities of diagnostics during a run, such as for compliance review of
+// the policies applied in CI.
+//
+// The zero value is an empty log, ready to use. An AuditLog is safe for
+// concurrent use.
+type AuditLog struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+// Record adds an entry for the given change to the given diagnostic.
+func (l *AuditLog) Record(diag Diagnostic, action AuditAction, from, to Severity, rule string) {
+	desc := diag.Description()
+	entry := AuditEntry{
+		Action:  action,
+		Rule:    rule,
+		From:    from,
+		To:      to,
+		Summary: desc.Summary,
+		Address: desc.Address,
+	}
+	if subject := subjectOf(diag); subject != nil {
+		entry.Subject = subject.StartString()
+	}
+
+	l.mu.Lock()
+	l.entries = append(l.entries, entry)
+	l.mu.Unlock()
+}
+
+// RecordEscalations adds an entry for each of the given diagnostics whose
+// severity was changed using ChangeSeverity or Escalate, as a promotion or
+// a demotion depending on the direction of the change.
+//
+// This is typically called with the final diagnostics of a run, so that
+// the log reflects every policy that affected them.
+func (l *AuditLog) RecordEscalations(diags Diagnostics) {
+	for _, diag := range diags {
+		esc, ok := EscalationOf(diag)
+		if !ok || esc.From == diag.Severity() {
+			continue
+		}
+		action := AuditDemoted
+		if diag.Severity().Level() > esc.From.Level() {
+			action = AuditPromoted
+		}
+		l.Record(diag, action, esc.From, diag.Severity(), esc.Policy)
+	}
+}
+
+// RecordSuppressions adds an AuditSuppressed entry for each of the given
+// diagnostics, such as those returned by Diagnostics.Suppress, attributed to
+// the given rule.
+func (l *AuditLog) RecordSuppressions(diags Diagnostics, rule string) {
+	for _, diag := range diags {
+		l.Record(diag, AuditSuppressed, diag.Severity(), diag.Severity(), rule)
+	}
+}
+
+// Entries returns all of the entries recorded so far, in the order they
+// were recorded.
+func (l *AuditLog) Entries() []AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.entries) == 0 {
+		return nil
+	}
+	ret := make([]AuditEntry, len(l.entries))
+	copy(ret, l.entries)
+	return ret
+}
+
+// MarshalJSON returns a JSON representation of the log's entries, as an
+// array of objects with the properties "action", "rule", "from", "to",
+// "summary", "address" and "subject". The severities are "error" or
+// "warning", and "address" and "subject" are omitted if empty.
+func (l *AuditLog) MarshalJSON() ([]byte, error) {
+	entries := l.Entries()
+	ret := make([]jsonAuditEntry, len(entries))
+	for i, entry := range entries {
+		ret[i] = jsonAuditEntry{
+			Action:  string(entry.Action),
+			Rule:    entry.Rule,
+			From:    strings.ToLower(entry.From.String()),
+			To:      strings.ToLower(entry.To.String()),
+			Summary: entry.Summary,
+			Address: entry.Address,
+			Subject: entry.Subject,
+		}
+	}
+	return json.Marshal(ret)
+}
+
+type jsonAuditEntry struct {
+	Action  string `json:"action"`
+	Rule    string `json:"rule"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Summary string `json:"summary"`
+	Address string `json:"address,omitempty"`
+	Subject string `json:"subject,omitempty"`
+}
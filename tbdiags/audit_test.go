@@ -0,0 +1,37 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	rng := LineRange("main.tb", 3)
+	diags := Diagnostics{
+		Escalate(
+			sourcedDiagnostic{
+				diagnosticBase: diagnosticBase{severity: Warning, summary: "Deprecated argument"},
+				subject:        &rng,
+			},
+			Error, "strict mode",
+		),
+		Escalate(Sourceless(Error, "Unused variable", ""), Warning, "lenient mode"),
+		Sourceless(Warning, "Untouched", ""),
+	}
+
+	var log AuditLog
+	log.RecordEscalations(diags)
+	log.Record(Sourceless(Warning, "Noisy check", ""), AuditSuppressed, Warning, Warning, "suppressions file")
+
+	got, err := log.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[` +
+		`{"action":"promoted","rule":"strict mode","from":"warning","to":"error","summary":"Deprecated argument","subject":"main.tb:3"},` +
+		`{"action":"demoted","rule":"lenient mode","from":"error","to":"warning","summary":"Unused variable"},` +
+		`{"action":"suppressed","rule":"suppressions file","from":"warning","to":"warning","summary":"Noisy check"}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
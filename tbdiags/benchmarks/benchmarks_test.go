@@ -0,0 +1,108 @@
+package benchmarks
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+)
+
+// largeSet returns n diagnostics spread over a few files, in an order that
+// requires sorting.
+func largeSet(n int) tbdiags.Diagnostics {
+	diags := make(tbdiags.Diagnostics, 0, n)
+	for i := 0; i < n; i++ {
+		line := (n - i) % 1000
+		rng := tbdiags.LineRange(fmt.Sprintf("file%d.tb", i%7), line+1)
+		diags = diags.Append(&benchDiagnostic{
+			severity: severityFor(i),
+			summary:  fmt.Sprintf("Problem %d", i),
+			subject:  &rng,
+		})
+	}
+	return diags
+}
+
+func severityFor(i int) tbdiags.Severity {
+	if i%3 == 0 {
+		return tbdiags.Error
+	}
+	return tbdiags.Warning
+}
+
+func BenchmarkAppend(b *testing.B) {
+	diag := tbdiags.Sourceless(tbdiags.Error, "Bad thing", "It went wrong.")
+	list := tbdiags.Diagnostics{diag, diag, diag, diag}
+	err := errors.New("it went wrong")
+	wrapped := fmt.Errorf("loading: %w", list.Err())
+
+	inputs := map[string]interface{}{
+		"Diagnostic":    diag,
+		"Diagnostics":   list,
+		"error":         err,
+		"wrapped error": wrapped,
+	}
+	for name, input := range inputs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var diags tbdiags.Diagnostics
+				diags = diags.Append(input)
+			}
+		})
+	}
+}
+
+func BenchmarkSort(b *testing.B) {
+	for _, n := range []int{1000, 100000} {
+		orig := largeSet(n)
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			diags := make(tbdiags.Diagnostics, n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				copy(diags, orig)
+				diags.Sort()
+			}
+		})
+	}
+}
+
+func BenchmarkErr(b *testing.B) {
+	diags := largeSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = diags.Err().Error()
+	}
+}
+
+func BenchmarkMarshalJSON(b *testing.B) {
+	diags := largeSet(1000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := diags.MarshalJSON(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+type benchDiagnostic struct {
+	severity tbdiags.Severity
+	summary  string
+	subject  *tbdiags.SourceRange
+}
+
+func (d *benchDiagnostic) Severity() tbdiags.Severity {
+	return d.severity
+}
+
+func (d *benchDiagnostic) Description() tbdiags.Description {
+	return tbdiags.Description{Summary: d.summary}
+}
+
+func (d *benchDiagnostic) Source() tbdiags.Source {
+	return tbdiags.Source{Subject: d.subject}
+}
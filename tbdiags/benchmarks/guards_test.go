@@ -0,0 +1,49 @@
+package benchmarks
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+)
+
+func TestAllocations(t *testing.T) {
+	diag := tbdiags.Sourceless(tbdiags.Error, "Bad thing", "It went wrong.")
+	err := errors.New("it went wrong")
+	sorted := largeSet(1000)
+	sorted.Sort()
+
+	tests := map[string]struct {
+		Func func()
+		Max  float64
+	}{
+		"Append Diagnostic": {
+			func() {
+				var diags tbdiags.Diagnostics
+				diags = diags.Append(diag)
+			},
+			1,
+		},
+		"Append error": {
+			func() {
+				var diags tbdiags.Diagnostics
+				diags = diags.Append(err)
+			},
+			4,
+		},
+		"Sort": {
+			func() {
+				sorted.Sort()
+			},
+			1,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := testing.AllocsPerRun(100, test.Func); got > test.Max {
+				t.Errorf("%v allocations per run; want at most %v", got, test.Max)
+			}
+		})
+	}
+}
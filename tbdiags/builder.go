@@ -0,0 +1,168 @@
+package tbdiags
+
+import (
+	"fmt"
+	"time"
+)
+
+// Builder builds a diagnostic from its parts, for producers that would
+// otherwise define their own Diagnostic implementations or nest several
+// calls such as WithCode and WithRelated. Each method modifies and returns
+// the receiver, so that calls can be chained:
+//
+//	diag := tbdiags.NewDiagnostic(tbdiags.Error, "Duplicate name").
+//		WithDetailf("The name %q is already in use.", name).
+//		WithSubject(rng).
+//		WithRelated("first defined here", prev).
+//		Build()
+type Builder struct {
+	base       diagnosticBase
+	subject    *SourceRange
+	context    *SourceRange
+	related    []RelatedInfo
+	tags       []Tag
+	attributes map[string]interface{}
+	fixes      []SuggestedFix
+	timestamp  time.Time
+}
+
+// NewDiagnostic returns a Builder for a diagnostic with the given severity
+// and summary and no other parts.
+func NewDiagnostic(severity Severity, summary string) *Builder {
+	return &Builder{
+		base: diagnosticBase{severity: severity, summary: summary},
+	}
+}
+
+// WithSeverity sets the severity.
+func (b *Builder) WithSeverity(severity Severity) *Builder {
+	b.base.severity = severity
+	return b
+}
+
+// WithSummary sets the summary.
+func (b *Builder) WithSummary(summary string) *Builder {
+	b.base.summary = summary
+	return b
+}
+
+// WithDetail sets the detail.
+func (b *Builder) WithDetail(detail string) *Builder {
+	b.base.detail = detail
+	return b
+}
+
+// WithDetailf sets the detail to the result of formatting the given
+// arguments, as for fmt.Sprintf.
+func (b *Builder) WithDetailf(format string, args ...interface{}) *Builder {
+	b.base.detail = fmt.Sprintf(format, args...)
+	return b
+}
+
+// WithAddress sets the address.
+func (b *Builder) WithAddress(address string) *Builder {
+	b.base.address = address
+	return b
+}
+
+// WithSubject sets the subject range.
+func (b *Builder) WithSubject(rng SourceRange) *Builder {
+	b.subject = &rng
+	return b
+}
+
+// WithContext sets the context range.
+func (b *Builder) WithContext(rng SourceRange) *Builder {
+	b.context = &rng
+	return b
+}
+
+// WithCode sets the code. If the code is registered and no help URL has
+// been set, the help URL is set to the code's documentation URL, as for
+// Coded.
+func (b *Builder) WithCode(code string) *Builder {
+	b.base.code = code
+	if info, ok := LookupCode(code); ok && b.base.helpURL == "" {
+		b.base.helpURL = info.DocURL
+	}
+	return b
+}
+
+// WithHelpURL sets the help URL.
+func (b *Builder) WithHelpURL(url string) *Builder {
+	b.base.helpURL = url
+	return b
+}
+
+// WithRelated adds a secondary location, as for the WithRelated function.
+func (b *Builder) WithRelated(message string, rng SourceRange) *Builder {
+	b.related = append(b.related, RelatedInfo{Message: message, Range: rng})
+	return b
+}
+
+// WithTags adds tags, as for the WithTags function.
+func (b *Builder) WithTags(tags ...Tag) *Builder {
+	b.tags = append(b.tags, tags...)
+	return b
+}
+
+// WithAttribute sets an attribute, as for WithAttributes.
+func (b *Builder) WithAttribute(key string, value interface{}) *Builder {
+	if b.attributes == nil {
+		b.attributes = make(map[string]interface{})
+	}
+	b.attributes[key] = value
+	return b
+}
+
+// WithFix adds a suggested fix, as for WithFixes.
+func (b *Builder) WithFix(fix SuggestedFix) *Builder {
+	b.fixes = append(b.fixes, fix)
+	return b
+}
+
+// WithTimestamp sets the time the diagnostic was produced, as for the
+// WithTimestamp function.
+func (b *Builder) WithTimestamp(t time.Time) *Builder {
+	b.timestamp = t
+	return b
+}
+
+// Build returns the diagnostic. The builder can continue to be used
+// afterwards without affecting diagnostics it already built.
+func (b *Builder) Build() Diagnostic {
+	var diag Diagnostic = b.base
+	if b.subject != nil || b.context != nil {
+		diag = sourcedDiagnostic{
+			diagnosticBase: b.base,
+			subject:        copyRange(b.subject),
+			context:        copyRange(b.context),
+		}
+	}
+	if len(b.related) > 0 {
+		diag = WithRelated(diag, b.related...)
+	}
+	if len(b.tags) > 0 {
+		diag = WithTags(diag, b.tags...)
+	}
+	if len(b.attributes) > 0 {
+		diag = WithAttributes(diag, b.attributes)
+	}
+	if len(b.fixes) > 0 {
+		diag = WithFixes(diag, b.fixes...)
+	}
+	if !b.timestamp.IsZero() {
+		diag = WithTimestamp(diag, b.timestamp)
+	}
+	return withCaller(diag)
+}
+
+// copyRange returns a pointer to a copy of the given range, or nil if it's
+// nil.
+func copyRange(rng *SourceRange) *SourceRange {
+	if rng == nil {
+		return nil
+	}
+	ret := *rng
+	return &ret
+}
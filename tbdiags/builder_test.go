@@ -0,0 +1,77 @@
+package tbdiags
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	defer func() {
+		codesMu.Lock()
+		delete(codes, "TEST010")
+		codesMu.Unlock()
+	}()
+	RegisterCode("TEST010", Error, "https://example.com/TEST010")
+
+	subject := LineRange("main.tb", 3)
+	prev := LineRange("main.tb", 1)
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	b := NewDiagnostic(Warning, "Duplicate").
+		WithSeverity(Error).
+		WithSummary("Duplicate name").
+		WithDetailf("The name %q is already in use.", "web").
+		WithAddress("resource.web").
+		WithSubject(subject).
+		WithContext(FileRange("main.tb")).
+		WithCode("TEST010").
+		WithRelated("first defined here", prev).
+		WithTags(TagDeprecated).
+		WithAttribute("name", "web").
+		WithTimestamp(at)
+	diag := b.Build()
+
+	if got := diag.Severity(); got != Error {
+		t.Errorf("wrong severity %s", got)
+	}
+	wantDesc := Description{
+		Summary: "Duplicate name",
+		Detail:  `The name "web" is already in use.`,
+		Address: "resource.web",
+		Code:    "TEST010",
+		HelpURL: "https://example.com/TEST010",
+	}
+	if got := diag.Description(); got != wantDesc {
+		t.Errorf("wrong description\ngot:  %#v\nwant: %#v", got, wantDesc)
+	}
+	if src := diag.Source(); *src.Subject != subject || *src.Context != FileRange("main.tb") {
+		t.Errorf("wrong source %#v", src)
+	}
+	if got, want := RelatedOf(diag), []RelatedInfo{{Message: "first defined here", Range: prev}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong related information %#v", got)
+	}
+	if !HasTag(diag, TagDeprecated) {
+		t.Errorf("missing tag")
+	}
+	if got := AttributesOf(diag)["name"]; got != "web" {
+		t.Errorf("wrong attribute %#v", got)
+	}
+	if got, _ := TimestampOf(diag); !got.Equal(at) {
+		t.Errorf("wrong timestamp %s", got)
+	}
+
+	// Later changes to the builder don't affect diagnostics it already
+	// built.
+	b.WithRelated("also used here", subject).WithAttribute("name", "api")
+	if got := len(RelatedOf(diag)); got != 1 {
+		t.Errorf("built diagnostic changed to have %d related locations", got)
+	}
+	if got := AttributesOf(diag)["name"]; got != "web" {
+		t.Errorf("built diagnostic's attribute changed to %#v", got)
+	}
+
+	plain := NewDiagnostic(Hint, "Consider upgrading").Build()
+	if src := plain.Source(); src.Subject != nil || src.Context != nil {
+		t.Errorf("unexpected source %#v", src)
+	}
+}
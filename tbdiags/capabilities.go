@@ -0,0 +1,169 @@
+package tbdiags
+
+import (
+	"strings"
+)
+
+// Capabilities describes which optional parts of the diagnostic model are
+// supported by a consumer of diagnostics, such as a plugin host using an
+// older protocol version, so that diagnostics can be downgraded to suit it
+// using Diagnostics.ForCapabilities.
+//
+// Each field is true if the consumer supports the corresponding feature, so
+// the zero value describes a consumer that supports only the required
+// parts of the model. New fields will be added as the model grows, so
+// consumers that support everything should use AllCapabilities rather than
+// constructing a value themselves.
+type Capabilities struct {
+	// Address is whether Description.Address is supported.
+	Address bool
+
+	// Context is whether Source.Context is supported.
+	Context bool
+
+	// SubjectKinds is whether subjects with a SubjectKind other than
+	// SubjectFile are supported.
+	SubjectKinds bool
+
+	// Related is whether DiagnosticRelated is supported.
+	Related bool
+
+	// Fixes is whether DiagnosticFixes is supported.
+	Fixes bool
+
+	// Children is whether DiagnosticChildren is supported.
+	Children bool
+
+	// ExtraInfo is whether DiagnosticExtraInfo is supported.
+	ExtraInfo bool
+
+	// Tags is whether DiagnosticTags is supported.
+	Tags bool
+
+	// Attributes is whether DiagnosticAttributes is supported.
+	Attributes bool
+}
+
+// AllCapabilities returns the Capabilities of a consumer that supports the
+// entire diagnostic model.
+func AllCapabilities() Capabilities {
+	return Capabilities{
+		Address:      true,
+		Context:      true,
+		SubjectKinds: true,
+		Related:      true,
+		Fixes:        true,
+		Children:     true,
+		ExtraInfo:    true,
+		Tags:         true,
+		Attributes:   true,
+	}
+}
+
+// Intersect returns the capabilities supported by both the receiver and the
+// given other capabilities, such as when negotiating between two parties
+// that each announce what they support.
+func (c Capabilities) Intersect(other Capabilities) Capabilities {
+	return Capabilities{
+		Address:      c.Address && other.Address,
+		Context:      c.Context && other.Context,
+		SubjectKinds: c.SubjectKinds && other.SubjectKinds,
+		Related:      c.Related && other.Related,
+		Fixes:        c.Fixes && other.Fixes,
+		Children:     c.Children && other.Children,
+		ExtraInfo:    c.ExtraInfo && other.ExtraInfo,
+		Tags:         c.Tags && other.Tags,
+		Attributes:   c.Attributes && other.Attributes,
+	}
+}
+
+// ForCapabilities returns a copy of the receiver where any features that
+// are not included in the given capabilities have been removed.
+//
+// Where possible, information from removed features is preserved in the
+// detail of the diagnostic instead. For example, a diagnostic about an
+// environment variable given to a consumer that doesn't support
+// SubjectKinds will lose its subject but gain a sentence naming the
+// variable in its detail, and the related information of a diagnostic
+// given to a consumer that doesn't support Related is listed in its detail.
+// For a consumer that doesn't support Children, the children of each
+// diagnostic follow it in the result, as for Diagnostics.Flatten.
+func (diags Diagnostics) ForCapabilities(caps Capabilities) Diagnostics {
+	if caps == AllCapabilities() {
+		return append(Diagnostics(nil), diags...)
+	}
+
+	if !caps.Children {
+		diags = diags.Flatten()
+	}
+	var ret Diagnostics
+	for _, diag := range diags {
+		ret = append(ret, downgradeDiagnostic(diag, caps))
+	}
+	return ret
+}
+
+func downgradeDiagnostic(diag Diagnostic, caps Capabilities) Diagnostic {
+	desc := diag.Description()
+	src := diag.Source()
+	newDesc, newSrc := desc, src
+
+	if !caps.Address {
+		newDesc.Address = ""
+	}
+	if !caps.Context {
+		newSrc.Context = nil
+	}
+	if !caps.SubjectKinds {
+		if subject := src.Subject; subject != nil && subject.Kind != SubjectFile {
+			newSrc.Subject = nil
+			if newSrc.Context != nil && newSrc.Context.Kind != SubjectFile {
+				newSrc.Context = nil
+			}
+			newDesc.Detail = appendNote(newDesc.Detail, "This problem relates to "+subject.Kind.Describe(subject.Filename)+".")
+		}
+	}
+	if related := RelatedOf(diag); !caps.Related && len(related) > 0 {
+		lines := make([]string, len(related))
+		for i, info := range related {
+			lines[i] = info.Message
+			if at := info.Range.StartString(); at != "" {
+				lines[i] += ": " + at
+			}
+		}
+		newDesc.Detail = appendNote(newDesc.Detail, strings.Join(lines, "\n"))
+		diag = withRelated{diag, nil}
+	}
+	if !caps.Fixes && len(FixesOf(diag)) > 0 {
+		diag = withFixes{diag, nil}
+	}
+	if children := ChildrenOf(diag); caps.Children && len(children) > 0 {
+		diag = withChildren{diag, children.ForCapabilities(caps)}
+	}
+	if !caps.ExtraInfo && len(ExtraInfos(diag)) > 0 {
+		diag = withoutExtraInfo{diag}
+	}
+	if !caps.Tags && len(TagsOf(diag)) > 0 {
+		diag = withTags{diag, nil}
+	}
+	if !caps.Attributes && len(AttributesOf(diag)) > 0 {
+		diag = withAttributes{diag, nil}
+	}
+
+	if newDesc != desc {
+		diag = overrideDescription{diag, newDesc}
+	}
+	if newSrc != src {
+		diag = overrideSource{diag, newSrc}
+	}
+	return diag
+}
+
+// appendNote returns the given detail with the given note added as a
+// separate paragraph.
+func appendNote(detail, note string) string {
+	if detail == "" {
+		return note
+	}
+	return detail + "\n\n" + note
+}
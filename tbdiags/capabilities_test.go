@@ -0,0 +1,109 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsForCapabilities(t *testing.T) {
+	diags := Diagnostics{
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  "Invalid token",
+				address:  "credentials",
+			},
+			subject: &SourceRange{
+				Filename: "TB_TOKEN",
+				Kind:     SubjectEnvVar,
+			},
+		},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		got := diags.ForCapabilities(AllCapabilities())
+		if got[0] != diags[0] {
+			t.Errorf("diagnostic was modified")
+		}
+		got[0] = nil
+		if diags[0] == nil {
+			t.Errorf("result shares its backing array with the receiver")
+		}
+	})
+	t.Run("none", func(t *testing.T) {
+		got := diags.ForCapabilities(Capabilities{})
+		desc := got[0].Description()
+		if desc.Address != "" {
+			t.Errorf("address was not removed")
+		}
+		if got, want := desc.Detail, "This problem relates to environment variable TB_TOKEN."; got != want {
+			t.Errorf("wrong detail %q; want %q", got, want)
+		}
+		if got[0].Source().Subject != nil {
+			t.Errorf("subject was not removed")
+		}
+		if got, want := got[0].Severity(), Error; got != want {
+			t.Errorf("wrong severity %s; want %s", got, want)
+		}
+	})
+}
+
+func TestDiagnosticsForCapabilitiesOptional(t *testing.T) {
+	var diag Diagnostic = Sourceless(Error, "Duplicate name", "Names must be unique.")
+	diag = WithRelated(diag, RelatedInfo{
+		Message: "Also defined here",
+		Range:   SourceRange{Filename: "main.tb", Start: SourcePos{Line: 3, Column: 1}},
+	})
+	diag = WithFixes(diag, SuggestedFix{Message: "Rename it"})
+	diag = WithExtraInfo(diag, "payload")
+	diag = WithTags(diag, TagUnnecessary)
+	diag = WithAttributes(diag, map[string]interface{}{"name": "web"})
+	diag = WithChildren(diag, Diagnostics{WithFixes(Sourceless(Warning, "Unused", ""), SuggestedFix{Message: "Remove it"})})
+	diags := Diagnostics{diag}
+
+	t.Run("all", func(t *testing.T) {
+		got := diags.ForCapabilities(AllCapabilities())
+		if len(RelatedOf(got[0])) != 1 || len(FixesOf(got[0])) != 1 || len(ExtraInfos(got[0])) != 1 ||
+			len(TagsOf(got[0])) != 1 || len(AttributesOf(got[0])) != 1 || len(ChildrenOf(got[0])) != 1 {
+			t.Errorf("optional features were removed")
+		}
+	})
+	t.Run("children only", func(t *testing.T) {
+		got := diags.ForCapabilities(Capabilities{Children: true})
+		if len(got) != 1 {
+			t.Fatalf("wrong number of diagnostics %d; want 1", len(got))
+		}
+		if len(RelatedOf(got[0])) != 0 || len(FixesOf(got[0])) != 0 || len(ExtraInfos(got[0])) != 0 ||
+			len(TagsOf(got[0])) != 0 || len(AttributesOf(got[0])) != 0 {
+			t.Errorf("optional features were not removed")
+		}
+		children := ChildrenOf(got[0])
+		if len(children) != 1 || len(FixesOf(children[0])) != 0 {
+			t.Errorf("children were not downgraded")
+		}
+		if got, want := got[0].Description().Detail, "Names must be unique.\n\nAlso defined here: main.tb:3,1"; got != want {
+			t.Errorf("wrong detail %q; want %q", got, want)
+		}
+	})
+	t.Run("none", func(t *testing.T) {
+		got := diags.ForCapabilities(Capabilities{})
+		var summaries []string
+		for _, diag := range got {
+			if len(ChildrenOf(diag)) != 0 {
+				t.Errorf("%q still has children", diag.Description().Summary)
+			}
+			summaries = append(summaries, diag.Description().Summary)
+		}
+		if got, want := strings.Join(summaries, ", "), "Duplicate name, Unused"; got != want {
+			t.Errorf("wrong diagnostics %s; want %s", got, want)
+		}
+	})
+}
+
+func TestCapabilitiesIntersect(t *testing.T) {
+	got := AllCapabilities().Intersect(Capabilities{Context: true})
+	want := Capabilities{Context: true}
+	if got != want {
+		t.Errorf("wrong result %#v; want %#v", got, want)
+	}
+}
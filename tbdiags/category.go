@@ -0,0 +1,156 @@
+package tbdiags
+
+// Category is a well-known category of cross-cutting warning, so that common
+// kinds of warning behave consistently across tools without each of them
+// defining its own conventions.
+type Category int
+
+const (
+	// CategoryNone is the category of diagnostics that don't belong to any
+	// of the well-known categories.
+	CategoryNone Category = iota
+
+	// CategoryDeprecation is for uses of features that will be removed in
+	// a future version.
+	CategoryDeprecation
+
+	// CategoryExperimental is for uses of features that are not yet
+	// covered by compatibility promises.
+	CategoryExperimental
+
+	// CategoryPerformance is for constructs that work but are likely to
+	// be slow or expensive.
+	CategoryPerformance
+
+	// CategorySecurity is for constructs that are likely to compromise
+	// the security of the system being configured.
+	CategorySecurity
+)
+
+// String returns the name of the category, such as "deprecation", or an
+// empty string for CategoryNone.
+func (c Category) String() string {
+	switch c {
+	case CategoryDeprecation:
+		return "deprecation"
+	case CategoryExperimental:
+		return "experimental"
+	case CategoryPerformance:
+		return "performance"
+	case CategorySecurity:
+		return "security"
+	default:
+		return ""
+	}
+}
+
+// DefaultSeverity returns the severity that diagnostics of the category
+// have under ApplyCategoryPolicies when no other severity is given. This is
+// Error for CategorySecurity and Warning for all other categories.
+func (c Category) DefaultSeverity() Severity {
+	if c == CategorySecurity {
+		return Error
+	}
+	return Warning
+}
+
+// DiagnosticCategory is an optional interface implemented by diagnostics
+// that belong to one of the well-known categories.
+//
+// Renderer follows the severities of these diagnostics with a badge naming
+// the category, such as "Warning (deprecation)", and Diagnostics.MarshalJSON
+// encodes the name of the category as the "category" property.
+type DiagnosticCategory interface {
+	Category() Category
+}
+
+// WithCategory returns a diagnostic that is the same as the given diagnostic
+// except that it also implements DiagnosticCategory, returning the given
+// category.
+func WithCategory(diag Diagnostic, category Category) Diagnostic {
+	return withCategory{diag, category}
+}
+
+// CategoryOf returns the category of the given diagnostic, or CategoryNone
+// if it doesn't implement DiagnosticCategory.
+func CategoryOf(diag Diagnostic) Category {
+	var ret Category
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		c, ok := diag.(DiagnosticCategory)
+		if ok {
+			ret = c.Category()
+		}
+		return ok
+	})
+	return ret
+}
+
+// ApplyCategoryPolicies returns a copy of the receiver in which each warning
+// that belongs to a category whose severity is Error or Fatal has been
+// escalated to that severity using Escalate.
+//
+// The severity of each category is taken from the given map if present, or
+// from the category's DefaultSeverity otherwise. A nil map therefore applies
+// the default policies, which escalate only security warnings.
+func (diags Diagnostics) ApplyCategoryPolicies(severities map[Category]Severity) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		category := CategoryOf(diag)
+		if category != CategoryNone && diag.Severity() == Warning {
+			severity, ok := severities[category]
+			if !ok {
+				severity = category.DefaultSeverity()
+			}
+			if severity.Level() > Warning.Level() {
+				diag = Escalate(diag, severity, category.String()+" policy")
+			}
+		}
+		ret[i] = diag
+	}
+	return ret
+}
+
+// IsDeprecation returns true if the given diagnostic is about a deprecated
+// feature, because it either belongs to CategoryDeprecation or is tagged
+// TagDeprecated. Producers may mark deprecations in either way, so
+// consumers should use this rather than checking for only one of them.
+func IsDeprecation(diag Diagnostic) bool {
+	return CategoryOf(diag) == CategoryDeprecation || HasTag(diag, TagDeprecated)
+}
+
+// Deprecations returns the subset of the receiver for which IsDeprecation
+// returns true, so that deprecation warnings can be filtered and aggregated
+// separately from other problems.
+func (diags Diagnostics) Deprecations() Diagnostics {
+	ret, _ := diags.partitionDeprecations()
+	return ret
+}
+
+// partitionDeprecations splits the receiver into the diagnostics for which
+// IsDeprecation returns true and all others, preserving their order.
+func (diags Diagnostics) partitionDeprecations() (deprecations, others Diagnostics) {
+	for _, diag := range diags {
+		if IsDeprecation(diag) {
+			deprecations = append(deprecations, diag)
+		} else {
+			others = append(others, diag)
+		}
+	}
+	return deprecations, others
+}
+
+type withCategory struct {
+	Diagnostic
+	category Category
+}
+
+func (d withCategory) Category() Category {
+	return d.category
+}
+
+func (d withCategory) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,71 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyCategoryPolicies(t *testing.T) {
+	diags := Diagnostics{
+		WithCategory(Sourceless(Warning, "Deprecated argument", ""), CategoryDeprecation),
+		WithCategory(Sourceless(Warning, "Unencrypted connection", ""), CategorySecurity),
+		Sourceless(Warning, "Plain warning", ""),
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		got := diags.ApplyCategoryPolicies(nil)
+		want := []Severity{Warning, Error, Warning}
+		for i, diag := range got {
+			if diag.Severity() != want[i] {
+				t.Errorf("diagnostic %d has severity %s; want %s", i, diag.Severity(), want[i])
+			}
+		}
+		if esc, ok := EscalationOf(got[1]); !ok || esc.Policy != "security policy" {
+			t.Errorf("wrong escalation %#v, %t", esc, ok)
+		}
+	})
+	t.Run("overrides", func(t *testing.T) {
+		got := diags.ApplyCategoryPolicies(map[Category]Severity{
+			CategoryDeprecation: Error,
+			CategorySecurity:    Warning,
+		})
+		want := []Severity{Error, Warning, Warning}
+		for i, diag := range got {
+			if diag.Severity() != want[i] {
+				t.Errorf("diagnostic %d has severity %s; want %s", i, diag.Severity(), want[i])
+			}
+		}
+	})
+	t.Run("render", func(t *testing.T) {
+		got := (&Renderer{}).RenderString(diags[:1])
+		if want := "Warning (deprecation): Deprecated argument\n"; !strings.HasPrefix(got, want) {
+			t.Errorf("wrong rendering\ngot:\n%s\nwant prefix:\n%s", got, want)
+		}
+	})
+}
+
+func TestDiagnosticsDeprecations(t *testing.T) {
+	diags := Diagnostics{
+		WithCategory(Sourceless(Warning, "Deprecated argument", ""), CategoryDeprecation),
+		Sourceless(Error, "Bad thing", ""),
+		WithTags(Sourceless(Warning, "Deprecated function", ""), TagDeprecated),
+	}
+	got := diags.Deprecations()
+	if len(got) != 2 || got[0].Description().Summary != "Deprecated argument" || got[1].Description().Summary != "Deprecated function" {
+		t.Errorf("wrong deprecations %#v", got)
+	}
+
+	rendered := (&Renderer{SeparateDeprecations: true}).RenderString(diags)
+	want := `Error: Bad thing
+
+--- Deprecations ---
+
+Warning (deprecation): Deprecated argument
+
+Warning: Deprecated function
+
+`
+	if rendered != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", rendered, want)
+	}
+}
@@ -0,0 +1,86 @@
+package tbdiags
+
+// DiagnosticChildren is an optional interface implemented by diagnostics
+// that have child diagnostics nested under them, such as a "Module
+// validation failed" error with a child for each problem found in the
+// module.
+//
+// Renderer renders the children after their parent, indented beneath it, and
+// Diagnostics.MarshalJSON encodes them as the "children" property, an array
+// in the same format as their parent.
+type DiagnosticChildren interface {
+	Children() Diagnostics
+}
+
+// WithChildren returns a diagnostic that is the same as the given
+// diagnostic except that it also implements DiagnosticChildren, returning
+// the given children after any that the given diagnostic already has.
+//
+// Children are not counted by methods such as Diagnostics.HasErrors, so the
+// parent should have the severity of the most severe of its children.
+func WithChildren(parent Diagnostic, children Diagnostics) Diagnostic {
+	all := make(Diagnostics, 0, len(children))
+	all = append(all, ChildrenOf(parent)...)
+	all = append(all, children...)
+	return withChildren{parent, all}
+}
+
+// ChildrenOf returns the children of the given diagnostic, if it implements
+// DiagnosticChildren, or nil otherwise.
+func ChildrenOf(diag Diagnostic) Diagnostics {
+	var ret Diagnostics
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		c, ok := diag.(DiagnosticChildren)
+		if ok {
+			ret = c.Children()
+		}
+		return ok
+	})
+	return ret
+}
+
+// Walk calls the given function for each of the diagnostics in the
+// receiver and, recursively, for each of their children, with the depth of
+// each diagnostic in the tree, which is zero for the diagnostics in the
+// receiver. Each diagnostic is visited before its children. If the
+// function returns false for a diagnostic, its children are skipped.
+func (diags Diagnostics) Walk(fn func(diag Diagnostic, depth int) bool) {
+	diags.walk(fn, 0)
+}
+
+func (diags Diagnostics) walk(fn func(diag Diagnostic, depth int) bool, depth int) {
+	for _, diag := range diags {
+		if fn(diag, depth) {
+			ChildrenOf(diag).walk(fn, depth+1)
+		}
+	}
+}
+
+// Flatten returns the diagnostics in the receiver and all of their
+// descendants, each followed by its children, for consumers that don't
+// understand nesting. The diagnostics in the result have no children, so
+// none is rendered or serialized twice.
+func (diags Diagnostics) Flatten() Diagnostics {
+	var ret Diagnostics
+	diags.Walk(func(diag Diagnostic, depth int) bool {
+		if len(ChildrenOf(diag)) > 0 {
+			diag = withChildren{diag, nil}
+		}
+		ret = append(ret, diag)
+		return true
+	})
+	return ret
+}
+
+type withChildren struct {
+	Diagnostic
+	children Diagnostics
+}
+
+func (d withChildren) Children() Diagnostics {
+	return d.children[:len(d.children):len(d.children)]
+}
+
+func (d withChildren) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,71 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChildren(t *testing.T) {
+	missing := Sourceless(Error, "Missing name", "Every resource needs a name.")
+	nested := WithChildren(
+		Sourceless(Error, "Invalid block", ""),
+		Diagnostics{Sourceless(Warning, "Deprecated argument", "")},
+	)
+	parent := WithChildren(
+		Sourceless(Error, "Module validation failed", ""),
+		Diagnostics{missing, nested},
+	)
+	diags := Diagnostics{parent, Sourceless(Hint, "Consider upgrading", "")}
+
+	if got := len(ChildrenOf(parent)); got != 2 {
+		t.Errorf("wrong number of children %d; want 2", got)
+	}
+
+	var walked []string
+	diags.Walk(func(diag Diagnostic, depth int) bool {
+		walked = append(walked, strings.Repeat("-", depth)+diag.Description().Summary)
+		return diag.Description().Summary != "Invalid block"
+	})
+	if got, want := strings.Join(walked, "\n"), `Module validation failed
+-Missing name
+-Invalid block
+Consider upgrading`; got != want {
+		t.Errorf("wrong walk\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	flat := diags.Flatten()
+	var summaries []string
+	for _, diag := range flat {
+		if len(ChildrenOf(diag)) > 0 {
+			t.Errorf("flattened diagnostic %q still has children", diag.Description().Summary)
+		}
+		summaries = append(summaries, diag.Description().Summary)
+	}
+	if got, want := strings.Join(summaries, ", "), "Module validation failed, Missing name, Invalid block, Deprecated argument, Consider upgrading"; got != want {
+		t.Errorf("wrong flattened diagnostics\ngot:  %s\nwant: %s", got, want)
+	}
+
+	got := (&Renderer{}).RenderString(diags[:1])
+	want := `Error: Module validation failed
+
+    Error: Missing name
+
+    Every resource needs a name.
+
+    Error: Invalid block
+
+        Warning: Deprecated argument
+
+`
+	if got != want {
+		t.Errorf("wrong rendering\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	js, err := diags[:1].MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"children":[{"severity":"error","summary":"Missing name","detail":"Every resource needs a name."},{"severity":"error","summary":"Invalid block","children":[{"severity":"warning","summary":"Deprecated argument"}]}]`; !strings.Contains(withoutReportedAt(string(js)), want) {
+		t.Errorf("JSON has wrong children\ngot:  %s\nwant: %s", js, want)
+	}
+}
@@ -0,0 +1,75 @@
+package tbdiags
+
+import (
+	"unicode/utf8"
+)
+
+// ClampRange returns a version of the given range that is valid for the
+// given source code, so that code which uses a range to extract part of the
+// source can't panic or produce garbage if the producer of the range made a
+// mistake.
+//
+// Byte offsets beyond the end of the source are moved to the end, an end
+// before the start is moved to the start, and offsets in the middle of a
+// UTF-8 sequence are moved outwards to the nearest character boundary. The
+// line and column of any position whose byte offset changes are
+// recalculated from the source.
+//
+// Ranges that are not PrecisionExact ranges in files are returned unchanged,
+// since their byte offsets are not meaningful.
+func ClampRange(rng SourceRange, src []byte) SourceRange {
+	if rng.Kind != SubjectFile || rng.Precision != PrecisionExact {
+		return rng
+	}
+
+	start := clampOffset(rng.Start.Byte, src, false)
+	end := clampOffset(rng.End.Byte, src, true)
+	if end < start {
+		end = start
+	}
+
+	if start != rng.Start.Byte {
+		rng.Start = posForOffset(src, start)
+	}
+	if end != rng.End.Byte {
+		rng.End = posForOffset(src, end)
+	}
+	return rng
+}
+
+// clampOffset returns the given byte offset constrained to be within the
+// given source and at a character boundary, moving forward to the next
+// boundary if forward is set or backward to the previous one otherwise.
+func clampOffset(offset int, src []byte, forward bool) int {
+	switch {
+	case offset < 0:
+		return 0
+	case offset >= len(src):
+		return len(src)
+	}
+	for offset > 0 && offset < len(src) && !utf8.RuneStart(src[offset]) {
+		if forward {
+			offset++
+		} else {
+			offset--
+		}
+	}
+	return offset
+}
+
+// posForOffset returns the position of the given byte offset in the given
+// source, with one-based lines and columns counted in characters.
+func posForOffset(src []byte, offset int) SourcePos {
+	pos := SourcePos{Line: 1, Column: 1, Byte: offset}
+	for i := 0; i < offset; {
+		r, size := utf8.DecodeRune(src[i:])
+		i += size
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
@@ -0,0 +1,69 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestClampRange(t *testing.T) {
+	src := []byte("a = 1\nb = \"héllo\"\n")
+
+	tests := map[string]struct {
+		Range SourceRange
+		Want  SourceRange
+	}{
+		"valid": {
+			SourceRange{
+				Start: SourcePos{Line: 1, Column: 1, Byte: 0},
+				End:   SourcePos{Line: 1, Column: 2, Byte: 1},
+			},
+			SourceRange{
+				Start: SourcePos{Line: 1, Column: 1, Byte: 0},
+				End:   SourcePos{Line: 1, Column: 2, Byte: 1},
+			},
+		},
+		"past EOF": {
+			SourceRange{
+				Start: SourcePos{Line: 2, Column: 1, Byte: 6},
+				End:   SourcePos{Line: 9, Column: 1, Byte: 100},
+			},
+			SourceRange{
+				Start: SourcePos{Line: 2, Column: 1, Byte: 6},
+				End:   SourcePos{Line: 3, Column: 1, Byte: 19},
+			},
+		},
+		"inside a character": {
+			// Bytes 12 and 13 are the two bytes of "é"
+			SourceRange{
+				Start: SourcePos{Line: 2, Column: 7, Byte: 13},
+				End:   SourcePos{Line: 2, Column: 7, Byte: 13},
+			},
+			SourceRange{
+				Start: SourcePos{Line: 2, Column: 7, Byte: 12},
+				End:   SourcePos{Line: 2, Column: 8, Byte: 14},
+			},
+		},
+		"end before start": {
+			SourceRange{
+				Start: SourcePos{Line: 1, Column: 5, Byte: 4},
+				End:   SourcePos{Line: 1, Column: 1, Byte: 0},
+			},
+			SourceRange{
+				Start: SourcePos{Line: 1, Column: 5, Byte: 4},
+				End:   SourcePos{Line: 1, Column: 5, Byte: 4},
+			},
+		},
+		"line only": {
+			LineRange("main.tb", 40),
+			LineRange("main.tb", 40),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := ClampRange(test.Range, src)
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, test.Want)
+			}
+		})
+	}
+}
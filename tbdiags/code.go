@@ -0,0 +1,275 @@
+package tbdiags
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CodeInfo describes a diagnostic code registered with RegisterCode.
+type CodeInfo struct {
+	// Code is the stable machine-readable identifier, such as "TB1001",
+	// which appears in the Code field of a diagnostic's Description. It
+	// may be qualified by the origin of the diagnostics, such as
+	// "linter/E102", so that tools whose codes collide can register them
+	// separately. See QualifiedCode.
+	Code string
+
+	// DefaultSeverity is the severity of diagnostics created with Coded.
+	DefaultSeverity Severity
+
+	// DocURL is the address of the documentation for the code, or empty
+	// if it has none.
+	DocURL string
+
+	// ValidUntil, if not empty, is the version of the program in which the
+	// code is due to be removed, such as for a temporary migration warning.
+	// See ExpiredCodes and Diagnostics.EscalateExpired.
+	ValidUntil string
+}
+
+var (
+	codes   = make(map[string]CodeInfo)
+	codesMu sync.RWMutex
+)
+
+// RegisterCode adds a diagnostic code to the central registry, so that
+// downstream tooling can enumerate the codes a program may report, such as
+// to generate documentation or validate suppression lists, using
+// LookupCode and RegisteredCodes.
+//
+// RegisterCode is intended to be called from the init functions of
+// packages that produce diagnostics. It panics if the code is empty or is
+// already registered, because codes are only useful if they're unique.
+// Packages whose codes might collide with another tool's should register
+// them qualified by their origin, such as "linter/E102".
+func RegisterCode(code string, defaultSeverity Severity, docURL string) {
+	registerCode(CodeInfo{
+		Code:            code,
+		DefaultSeverity: defaultSeverity,
+		DocURL:          docURL,
+	})
+}
+
+// RegisterExpiringCode is like RegisterCode except that the code is only
+// valid until the given version of the program, such as "2.0", after which
+// ExpiredCodes reports it and Diagnostics.EscalateExpired turns warnings
+// with it into errors. This is for temporary warnings, such as those about
+// a migration, that would otherwise linger long after they've served their
+// purpose.
+func RegisterExpiringCode(code string, defaultSeverity Severity, docURL, validUntil string) {
+	if validUntil == "" {
+		panic("tbdiags: RegisterExpiringCode requires a version")
+	}
+	registerCode(CodeInfo{
+		Code:            code,
+		DefaultSeverity: defaultSeverity,
+		DocURL:          docURL,
+		ValidUntil:      validUntil,
+	})
+}
+
+func registerCode(info CodeInfo) {
+	if info.Code == "" {
+		panic("tbdiags: RegisterCode requires a code")
+	}
+
+	codesMu.Lock()
+	defer codesMu.Unlock()
+	if _, exists := codes[info.Code]; exists {
+		panic(fmt.Sprintf("tbdiags: code %q is already registered", info.Code))
+	}
+	codes[info.Code] = info
+}
+
+// LookupCode returns the registered information for the given code, or
+// false if it's not registered.
+func LookupCode(code string) (CodeInfo, bool) {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	info, ok := codes[code]
+	return info, ok
+}
+
+// RegisteredCodes returns all of the registered codes, sorted by code.
+func RegisteredCodes() []CodeInfo {
+	codesMu.RLock()
+	defer codesMu.RUnlock()
+	ret := make([]CodeInfo, 0, len(codes))
+	for _, info := range codes {
+		ret = append(ret, info)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Code < ret[j].Code
+	})
+	return ret
+}
+
+// ExpiredCodes returns the registered codes that are no longer valid in the
+// given version of the program, sorted by code, which are definitions that
+// should have been removed. Programs can call it from a test with their
+// current version to fail the build until stale codes are cleaned up.
+//
+// Versions are compared as sequences of dot-separated numbers, ignoring a
+// leading "v", so "1.10" is later than "1.9". Any part that isn't a number
+// is compared as a string.
+func ExpiredCodes(version string) []CodeInfo {
+	var ret []CodeInfo
+	for _, info := range RegisteredCodes() {
+		if info.expiredIn(version) {
+			ret = append(ret, info)
+		}
+	}
+	return ret
+}
+
+// EscalateExpired returns a copy of the receiver in which each warning whose
+// code is no longer valid in the given version of the program, as reported
+// by ExpiredCodes, is escalated to an error by the "expired warning" policy,
+// so that temporary warnings can't outlive their planned removal unnoticed.
+func (diags Diagnostics) EscalateExpired(version string) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		ret[i] = diag
+		if diag.Severity() != Warning {
+			continue
+		}
+		if info, ok := lookupCodeOf(diag); ok && info.expiredIn(version) {
+			ret[i] = Escalate(diag, Error, "expired warning")
+		}
+	}
+	return ret
+}
+
+// lookupCodeOf returns the registered information for the code of the given
+// diagnostic, preferring its code qualified by its origin, if any, to its
+// bare code.
+func lookupCodeOf(diag Diagnostic) (CodeInfo, bool) {
+	code := diag.Description().Code
+	if code == "" {
+		return CodeInfo{}, false
+	}
+	if qualified := QualifiedCode(diag); qualified != code {
+		if info, ok := LookupCode(qualified); ok {
+			return info, true
+		}
+	}
+	return LookupCode(code)
+}
+
+// QualifiedCode returns the code of the given diagnostic qualified by its
+// origin, such as "linter/E102", or just its code if it has no origin. It
+// returns an empty string if the diagnostic has no code.
+func QualifiedCode(diag Diagnostic) string {
+	code := diag.Description().Code
+	if code == "" {
+		return ""
+	}
+	if origin := OriginOf(diag); origin != "" {
+		return origin + "/" + code
+	}
+	return code
+}
+
+// matchesCode returns true if the given diagnostic has the given code, which
+// may be qualified by an origin, such as "linter/E102", to match only the
+// diagnostics from that origin. A bare code matches regardless of origin.
+func matchesCode(diag Diagnostic, code string) bool {
+	if code == "" {
+		return false
+	}
+	if strings.Contains(code, "/") {
+		return QualifiedCode(diag) == code
+	}
+	return diag.Description().Code == code
+}
+
+// expiredIn returns true if the code has a ValidUntil version that is not
+// later than the given version.
+func (info CodeInfo) expiredIn(version string) bool {
+	return info.ValidUntil != "" && compareVersions(info.ValidUntil, version) <= 0
+}
+
+// compareVersions returns -1, 0 or 1 depending on whether version a is
+// earlier than, the same as or later than version b, treating missing
+// trailing parts as zero.
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		ap, bp := "0", "0"
+		if i < len(as) {
+			ap = as[i]
+		}
+		if i < len(bs) {
+			bp = bs[i]
+		}
+		an, aErr := strconv.Atoi(ap)
+		bn, bErr := strconv.Atoi(bp)
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case ap != bp:
+			if ap < bp {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// Coded creates and returns a diagnostic with no source location
+// information, with the given code and with the code's registered default
+// severity and documentation URL, as its HelpURL. If the code is qualified
+// by an origin, such as "linter/E102", then the diagnostic has the bare code
+// and that origin. It panics if the code isn't registered, which is a bug in
+// the calling program.
+func Coded(code, summary, detail string) Diagnostic {
+	info, ok := LookupCode(code)
+	if !ok {
+		panic(fmt.Sprintf("tbdiags: code %q is not registered", code))
+	}
+	origin := ""
+	if i := strings.LastIndex(code, "/"); i >= 0 {
+		origin, code = code[:i], code[i+1:]
+	}
+	var diag Diagnostic = withCaller(diagnosticBase{
+		severity: info.DefaultSeverity,
+		summary:  summary,
+		detail:   detail,
+		code:     code,
+		helpURL:  info.DocURL,
+	})
+	if origin != "" {
+		diag = WithOrigin(diag, origin)
+	}
+	return diag
+}
+
+// WithHelpURL returns a diagnostic that is the same as the given diagnostic
+// except that the HelpURL field of its description is the given URL.
+func WithHelpURL(diag Diagnostic, url string) Diagnostic {
+	desc := diag.Description()
+	desc.HelpURL = url
+	return overrideDescription{diag, desc}
+}
+
+// WithCode returns a diagnostic that is the same as the given diagnostic
+// except that the Code field of its description is the given code. The code
+// need not be registered.
+func WithCode(diag Diagnostic, code string) Diagnostic {
+	desc := diag.Description()
+	desc.Code = code
+	return overrideDescription{diag, desc}
+}
@@ -0,0 +1,205 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterCode(t *testing.T) {
+	RegisterCode("TEST001", Warning, "https://example.com/codes/TEST001")
+	defer func() {
+		codesMu.Lock()
+		delete(codes, "TEST001")
+		codesMu.Unlock()
+	}()
+
+	info, ok := LookupCode("TEST001")
+	if !ok {
+		t.Fatalf("code not found")
+	}
+	if want := (CodeInfo{"TEST001", Warning, "https://example.com/codes/TEST001", ""}); info != want {
+		t.Errorf("wrong info\ngot:  %#v\nwant: %#v", info, want)
+	}
+	if _, ok := LookupCode("TEST002"); ok {
+		t.Errorf("found a code that isn't registered")
+	}
+	if got := RegisteredCodes(); len(got) != 1 || got[0] != info {
+		t.Errorf("wrong registered codes %#v", got)
+	}
+
+	diag := Coded("TEST001", "Suspicious value", "")
+	if got, want := diag.Severity(), Warning; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	if got := diag.Description().Code; got != "TEST001" {
+		t.Errorf("wrong code %q", got)
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("registering a duplicate code did not panic")
+			}
+		}()
+		RegisterCode("TEST001", Error, "")
+	})
+}
+
+func TestRegisterQualifiedCode(t *testing.T) {
+	RegisterCode("linter/TEST008", Warning, "https://example.com/linter/TEST008")
+	RegisterExpiringCode("planner/TEST008", Warning, "https://example.com/planner/TEST008", "2.0")
+	defer func() {
+		codesMu.Lock()
+		delete(codes, "linter/TEST008")
+		delete(codes, "planner/TEST008")
+		codesMu.Unlock()
+	}()
+
+	diag := Coded("planner/TEST008", "Replacing resource", "")
+	if got := diag.Description().Code; got != "TEST008" {
+		t.Errorf("wrong code %q", got)
+	}
+	if got := OriginOf(diag); got != "planner" {
+		t.Errorf("wrong origin %q", got)
+	}
+	if got := QualifiedCode(diag); got != "planner/TEST008" {
+		t.Errorf("wrong qualified code %q", got)
+	}
+	if got := diag.Description().HelpURL; got != "https://example.com/planner/TEST008" {
+		t.Errorf("wrong help URL %q", got)
+	}
+
+	diags := Diagnostics{
+		Coded("linter/TEST008", "Unused import", ""),
+		diag,
+	}.EscalateExpired("2.0")
+	if diags[0].Severity() != Warning || diags[1].Severity() != Error {
+		t.Errorf("wrong severities %s, %s", diags[0].Severity(), diags[1].Severity())
+	}
+}
+
+func TestExpiredCodes(t *testing.T) {
+	RegisterExpiringCode("TEST004", Warning, "", "1.10")
+	RegisterExpiringCode("TEST005", Warning, "", "v2")
+	RegisterCode("TEST006", Warning, "")
+	defer func() {
+		codesMu.Lock()
+		delete(codes, "TEST004")
+		delete(codes, "TEST005")
+		delete(codes, "TEST006")
+		codesMu.Unlock()
+	}()
+
+	tests := map[string][]string{
+		"1.9":     nil,
+		"1.10":    {"TEST004"},
+		"v1.10.1": {"TEST004"},
+		"2.0":     {"TEST004", "TEST005"},
+	}
+	for version, want := range tests {
+		var got []string
+		for _, info := range ExpiredCodes(version) {
+			got = append(got, info.Code)
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("wrong expired codes in %s\ngot:  %s\nwant: %s", version, got, want)
+		}
+	}
+
+	diags := Diagnostics{
+		diagnosticBase{severity: Warning, summary: "Old setting", code: "TEST004"},
+		diagnosticBase{severity: Warning, summary: "Other setting", code: "TEST006"},
+		diagnosticBase{severity: Error, summary: "Broken setting", code: "TEST004"},
+	}
+	if got := diags.EscalateExpired("1.9"); got[0].Severity() != Warning {
+		t.Errorf("escalated a warning that hasn't expired")
+	}
+	got := diags.EscalateExpired("1.10")
+	if got[0].Severity() != Error || got[1].Severity() != Warning || got[2].Severity() != Error {
+		t.Errorf("wrong severities %s, %s, %s", got[0].Severity(), got[1].Severity(), got[2].Severity())
+	}
+	if esc, ok := EscalationOf(got[0]); !ok || esc.From != Warning || esc.Policy != "expired warning" {
+		t.Errorf("wrong escalation %#v", esc)
+	}
+	if _, ok := EscalationOf(got[2]); ok {
+		t.Errorf("escalated an error")
+	}
+
+	t.Run("no version", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("registering an expiring code without a version did not panic")
+			}
+		}()
+		RegisterExpiringCode("TEST007", Warning, "", "")
+	})
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		A, B string
+		Want int
+	}{
+		{"1.2", "1.2", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.2", "1.2", 0},
+		{"1.9", "1.10", -1},
+		{"2", "1.99", 1},
+		{"1.2-rc1", "1.2-rc2", -1},
+	}
+	for _, test := range tests {
+		if got := compareVersions(test.A, test.B); got != test.Want {
+			t.Errorf("compareVersions(%q, %q) = %d; want %d", test.A, test.B, got, test.Want)
+		}
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	diag := WithCode(Sourceless(Error, "Bad thing", "Details."), "TB1001")
+	desc := diag.Description()
+	if desc.Code != "TB1001" || desc.Summary != "Bad thing" || desc.Detail != "Details." {
+		t.Errorf("wrong description %#v", desc)
+	}
+
+	got := (&Renderer{}).RenderString(Diagnostics{diag})
+	if want := "Error [TB1001]: Bad thing\n\nDetails.\n\n"; got != want {
+		t.Errorf("wrong rendering\ngot:  %q\nwant: %q", got, want)
+	}
+
+	src, err := Diagnostics{diag}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), `"code":"TB1001"`) {
+		t.Errorf("code missing from JSON: %s", src)
+	}
+}
+
+func TestWithHelpURL(t *testing.T) {
+	diag := WithHelpURL(Sourceless(Error, "Bad thing", "Details."), "https://example.com/bad-thing")
+
+	got := (&Renderer{}).RenderString(Diagnostics{diag})
+	want := "Error: Bad thing\n\nDetails.\n\nFor more information, see https://example.com/bad-thing\n\n"
+	if got != want {
+		t.Errorf("wrong rendering\ngot:  %q\nwant: %q", got, want)
+	}
+
+	err := Diagnostics{diag}.Err()
+	if got, want := err.Error(), "Bad thing: Details. (see https://example.com/bad-thing)"; got != want {
+		t.Errorf("wrong error message\ngot:  %s\nwant: %s", got, want)
+	}
+	err = Diagnostics{diag, Sourceless(Error, "Other thing", "")}.Err()
+	if got, want := err.Error(), "2 problems:\n\n- Bad thing: Details. (see https://example.com/bad-thing)\n- Other thing"; got != want {
+		t.Errorf("wrong error message\ngot:  %s\nwant: %s", got, want)
+	}
+
+	RegisterCode("TEST003", Error, "https://example.com/codes/TEST003")
+	defer func() {
+		codesMu.Lock()
+		delete(codes, "TEST003")
+		codesMu.Unlock()
+	}()
+	if got := Coded("TEST003", "Coded thing", "").Description().HelpURL; got != "https://example.com/codes/TEST003" {
+		t.Errorf("wrong help URL %q for coded diagnostic", got)
+	}
+}
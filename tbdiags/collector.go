@@ -0,0 +1,266 @@
+package tbdiags
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Collector is a GroupingSink that accumulates the diagnostics reported to
+// it, in the order they were reported, so that a producer can report
+// diagnostics as it goes and the caller can retrieve them all at the end.
+//
+// The zero value of Collector is ready to use, and retains all diagnostics
+// reported to it.
+//
+// A Collector is safe for concurrent use. Each call to Diagnostics returns
+// a new slice that belongs to the caller, and diagnostics are never modified
+// once reported, so a server can read, render or encode the result while
+// producers continue to report to the same collector.
+type Collector struct {
+	// MaxSize, if greater than zero, limits the memory retained by the
+	// collector, as estimated by Diagnostics.EstimatedSize, to protect
+	// long-running programs from unbounded growth when a producer reports
+	// far too many diagnostics.
+	//
+	// When the limit is exceeded, the collector discards its most recently
+	// reported diagnostic of the lowest severity it has retained, so
+	// warnings are discarded before errors and the first reports of a
+	// problem are kept. Diagnostics then ends with a warning that counts
+	// the discarded diagnostics.
+	//
+	// MaxSize must not be changed after the first call to Report.
+	MaxSize int
+
+	// UnknownSeverity decides what Append does with values of types that
+	// can't be converted to diagnostics. If zero, Append behaves as
+	// Diagnostics.Append does, which panics unless SetLenientAppend was
+	// used. Otherwise, each such value is collected as a diagnostic of this
+	// severity that describes it, so that misuse is visible without
+	// crashing a production service.
+	UnknownSeverity Severity
+
+	// TTL, if greater than zero, is how long the collector retains each
+	// diagnostic after it was reported, so that a long-lived sink in a
+	// daemon reflects only recent activity. Expired diagnostics are pruned
+	// whenever diagnostics are reported and by Compact.
+	//
+	// TTL must not be changed after the first call to Report.
+	TTL time.Duration
+
+	// Timestamp causes each reported diagnostic that doesn't already have a
+	// timestamp to be given the time it was reported, as for
+	// WithTimestamp.
+	Timestamp bool
+
+	// SummarizePruned causes Diagnostics to end with a hint that counts
+	// the diagnostics pruned because of TTL, if there were any.
+	SummarizePruned bool
+
+	mu        sync.Mutex
+	diags     Diagnostics
+	reported  []time.Time // parallel to diags, only if TTL is set
+	groups    []string
+	size      int
+	discarded map[Severity]int
+	pruned    map[Severity]int
+
+	// now returns the current time. If nil, time.Now is used.
+	now func() time.Time
+}
+
+var _ GroupingSink = (*Collector)(nil)
+
+// Report implements Sink. Diagnostics reported while any groups are open
+// are recorded as belonging to those groups.
+func (c *Collector) Report(diags Diagnostics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneExpired()
+	for _, diag := range diags {
+		if diag == nil {
+			continue
+		}
+		if len(c.groups) > 0 {
+			diag = withGroup{diag, c.groups}
+		}
+		now := c.clock()
+		if c.Timestamp {
+			if _, ok := TimestampOf(diag); !ok {
+				diag = withTimestamp{diag, now}
+			}
+		}
+		c.diags = c.diags.Append(diag)
+		if c.TTL > 0 {
+			c.reported = append(c.reported, now)
+		}
+		if c.MaxSize > 0 {
+			c.size += estimatedSize(diag)
+			for c.size > c.MaxSize && len(c.diags) > 0 {
+				c.discardOne()
+			}
+		}
+	}
+}
+
+// Append converts the given values to diagnostics in the same way as
+// Diagnostics.Append, except as configured by UnknownSeverity, and then
+// reports them as for Report.
+func (c *Collector) Append(new ...interface{}) {
+	c.Report(Diagnostics(nil).appendValues(new, c.UnknownSeverity))
+}
+
+// discardOne removes the most recently reported of the retained diagnostics
+// that have the lowest severity. The caller must hold c.mu.
+func (c *Collector) discardOne() {
+	victim := len(c.diags) - 1
+	for i := victim - 1; i >= 0; i-- {
+		if c.diags[i].Severity().Level() < c.diags[victim].Severity().Level() {
+			victim = i
+		}
+	}
+
+	diag := c.diags[victim]
+	c.diags = append(c.diags[:victim], c.diags[victim+1:]...)
+	if c.reported != nil {
+		c.reported = append(c.reported[:victim], c.reported[victim+1:]...)
+	}
+	c.size -= estimatedSize(diag)
+	if c.discarded == nil {
+		c.discarded = make(map[Severity]int)
+	}
+	c.discarded[diag.Severity()]++
+}
+
+// Compact prunes any diagnostics that have expired because of TTL and then
+// releases any memory that the collector no longer needs, for long-lived
+// collectors that have retained many more diagnostics in the past than
+// they do now.
+func (c *Collector) Compact() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneExpired()
+	if len(c.diags) == cap(c.diags) {
+		return
+	}
+	diags := make(Diagnostics, len(c.diags))
+	copy(diags, c.diags)
+	c.diags = diags
+	if c.reported != nil {
+		reported := make([]time.Time, len(c.reported))
+		copy(reported, c.reported)
+		c.reported = reported
+	}
+}
+
+// pruneExpired removes the diagnostics that were reported longer ago than
+// TTL. The caller must hold c.mu.
+func (c *Collector) pruneExpired() {
+	if c.TTL <= 0 || len(c.reported) == 0 {
+		return
+	}
+	// The diagnostics are in the order they were reported, so the expired
+	// ones are all at the start.
+	cutoff := c.clock().Add(-c.TTL)
+	n := sort.Search(len(c.reported), func(i int) bool {
+		return c.reported[i].After(cutoff)
+	})
+	if n == 0 {
+		return
+	}
+	if c.pruned == nil {
+		c.pruned = make(map[Severity]int)
+	}
+	for _, diag := range c.diags[:n] {
+		c.pruned[diag.Severity()]++
+		c.size -= estimatedSize(diag)
+	}
+	c.diags = c.diags[n:]
+	c.reported = c.reported[n:]
+}
+
+func (c *Collector) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
+
+// BeginGroup implements GroupingSink.
+//
+// Groups are shared by all producers reporting to the collector, so they
+// are intended for sequential phases of an operation rather than for
+// concurrent producers.
+func (c *Collector) BeginGroup(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Always copy, because diagnostics already recorded may share the
+	// backing array of the current group stack.
+	groups := make([]string, len(c.groups), len(c.groups)+1)
+	copy(groups, c.groups)
+	c.groups = append(groups, name)
+}
+
+// EndGroup implements GroupingSink. It does nothing if no groups are open.
+func (c *Collector) EndGroup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.groups) > 0 {
+		c.groups = c.groups[:len(c.groups)-1]
+	}
+}
+
+// Diagnostics returns all of the diagnostics reported so far, except for any
+// that were discarded because of MaxSize or pruned because of TTL.
+func (c *Collector) Diagnostics() Diagnostics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pruneExpired()
+	summarizePruned := c.SummarizePruned && len(c.pruned) > 0
+	if len(c.diags) == 0 && len(c.discarded) == 0 && !summarizePruned {
+		return nil
+	}
+	ret := make(Diagnostics, len(c.diags), len(c.diags)+2)
+	copy(ret, c.diags)
+	if len(c.discarded) > 0 {
+		ret = append(ret, Sourceless(
+			Warning,
+			"Some diagnostics were discarded",
+			fmt.Sprintf("To limit memory use, %s %s discarded.", joinCounts(c.discarded), wasOrWere(c.discarded)),
+		))
+	}
+	if summarizePruned {
+		ret = append(ret, Sourceless(
+			Hint,
+			"Some diagnostics expired",
+			fmt.Sprintf("%s reported more than %s ago %s pruned.", joinCounts(c.pruned), c.TTL, wasOrWere(c.pruned)),
+		))
+	}
+	return ret
+}
+
+// joinCounts describes the nonzero counts in the given map as a phrase,
+// such as "1 error and 2 warnings".
+func joinCounts(counts map[Severity]int) string {
+	parts := severityCounts(counts)
+	ret := parts[len(parts)-1]
+	if len(parts) > 1 {
+		ret = strings.Join(parts[:len(parts)-1], ", ") + " and " + ret
+	}
+	return ret
+}
+
+// wasOrWere returns the form of "to be" that agrees with the phrase that
+// joinCounts returns for the given counts.
+func wasOrWere(counts map[Severity]int) string {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total == 1 {
+		return "was"
+	}
+	return "were"
+}
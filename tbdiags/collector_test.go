@@ -0,0 +1,219 @@
+package tbdiags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCollectorGroups(t *testing.T) {
+	var c Collector
+	c.Report(Diagnostics{Sourceless(Warning, "Before", "")})
+	c.BeginGroup("phase: plan")
+	c.Report(Diagnostics{Sourceless(Warning, "Planning", "")})
+	c.BeginGroup("module.network")
+	c.Report(Diagnostics{Sourceless(Error, "Nested", "")})
+	c.EndGroup()
+	c.EndGroup()
+	c.Report(Diagnostics{Sourceless(Warning, "After", "")})
+
+	diags := c.Diagnostics()
+	var got [][]string
+	for _, diag := range diags {
+		got = append(got, GroupOf(diag))
+	}
+	want := [][]string{
+		nil,
+		{"phase: plan"},
+		{"phase: plan", "module.network"},
+		nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong groups\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	var headings []string
+	for _, line := range strings.Split((&Renderer{}).RenderString(diags), "\n") {
+		if strings.HasPrefix(line, "===") {
+			headings = append(headings, line)
+		}
+	}
+	wantHeadings := []string{
+		"=== phase: plan ===",
+		"=== phase: plan > module.network ===",
+		"=== (no group) ===",
+	}
+	if !reflect.DeepEqual(headings, wantHeadings) {
+		t.Errorf("wrong headings\ngot:  %#v\nwant: %#v", headings, wantHeadings)
+	}
+}
+
+func TestCollectorMaxSize(t *testing.T) {
+	errDiag := Sourceless(Error, "Bad thing", "")
+	warnDiag := Sourceless(Warning, "Dubious thing", "")
+	size := Diagnostics{errDiag}.EstimatedSize()
+	if got, want := (Diagnostics{warnDiag}).EstimatedSize(), size+4; got != want {
+		t.Fatalf("wrong estimated size %d; want %d", got, want)
+	}
+
+	c := Collector{MaxSize: size * 3}
+	// The second warning doesn't fit, and then the first is discarded in
+	// favor of the later errors.
+	c.Report(Diagnostics{errDiag, warnDiag, warnDiag})
+	c.Report(Diagnostics{errDiag, errDiag})
+	// Only errors remain, so the newest is discarded.
+	c.Report(Diagnostics{errDiag})
+
+	diags := c.Diagnostics()
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Description().Summary)
+	}
+	want := []string{"Bad thing", "Bad thing", "Bad thing", "Some diagnostics were discarded"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong summaries\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := diags[3].Description().Detail, "To limit memory use, 1 error and 2 warnings were discarded."; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestCollectorAppend(t *testing.T) {
+	c := &Collector{UnknownSeverity: Warning}
+	c.Append(errors.New("failed"), 42, Sourceless(Hint, "Consider this", ""))
+
+	diags := c.Diagnostics()
+	if len(diags) != 3 {
+		t.Fatalf("got %d diagnostics, want 3", len(diags))
+	}
+	unknown := diags[1]
+	if got, want := unknown.Severity(), Warning; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	if got, want := unknown.Description().Detail, "A value of type int can't be converted to diagnostics, which is a bug in the program that reported it. The value was: 42"; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("appending an unknown value did not panic")
+		}
+	}()
+	(&Collector{}).Append(42)
+}
+
+func TestCollectorTTL(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := &Collector{
+		TTL:             time.Hour,
+		SummarizePruned: true,
+		now:             func() time.Time { return now },
+	}
+
+	c.Report(Diagnostics{Sourceless(Error, "Old error", ""), Sourceless(Warning, "Old warning", "")})
+	now = now.Add(40 * time.Minute)
+	c.Report(Diagnostics{Sourceless(Warning, "Recent warning", "")})
+	now = now.Add(30 * time.Minute)
+
+	var got []string
+	for _, diag := range c.Diagnostics() {
+		got = append(got, diag.Description().Summary+": "+diag.Description().Detail)
+	}
+	want := []string{
+		"Recent warning: ",
+		"Some diagnostics expired: 1 error and 1 warning reported more than 1h0m0s ago were pruned.",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong result\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	now = now.Add(time.Hour)
+	c.Compact()
+	if len(c.diags) != 0 || cap(c.diags) != 0 {
+		t.Errorf("Compact retained %d diagnostics with capacity %d", len(c.diags), cap(c.diags))
+	}
+	c = &Collector{
+		TTL:             time.Hour,
+		SummarizePruned: true,
+		now:             func() time.Time { return now },
+	}
+	c.Report(Diagnostics{Sourceless(Warning, "Old warning", "")})
+	now = now.Add(2 * time.Hour)
+	if got, want := c.Diagnostics()[0].Description().Detail, "1 warning reported more than 1h0m0s ago was pruned."; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestCollectorReportNil(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := &Collector{
+		TTL:     time.Hour,
+		MaxSize: 1 << 20,
+		now:     func() time.Time { return now },
+	}
+
+	c.Report(Diagnostics{nil, nil, SimpleWarning("Recent warning")})
+	now = now.Add(2 * time.Hour)
+	if got := c.Diagnostics(); len(got) != 0 {
+		t.Errorf("wrong result after expiry: %#v", got)
+	}
+}
+
+// TestCollectorConcurrentReads is intended to be run with the race
+// detector, as by "make race", to check that diagnostics retrieved from a
+// collector can be used while producers continue to report to it.
+func TestCollectorConcurrentReads(t *testing.T) {
+	c := &Collector{MaxSize: 1 << 20, Timestamp: true}
+	r := &Renderer{}
+	c.BeginGroup("run")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.BeginGroup(fmt.Sprintf("producer %d", i))
+				c.Report(Diagnostics{
+					WithRelated(
+						WithTags(Sourceless(Warning, "Slow", ""), TagDeprecated),
+						RelatedInfo{Message: "here", Range: LineRange("main.tb", j)},
+					),
+				})
+				c.EndGroup()
+				c.Report(Diagnostics{Sourceless(Error, "Failed", "")})
+			}
+		}(i)
+	}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				diags := c.Diagnostics()
+				r.RenderString(diags)
+				if _, err := diags.MarshalJSON(); err != nil {
+					t.Error(err)
+					return
+				}
+				diags.Sort()
+				for _, diag := range diags {
+					// Appending to the results of accessors must not
+					// affect other diagnostics that share their storage.
+					_ = append(GroupOf(diag), "extra")
+					_ = append(TagsOf(diag), TagUnnecessary)
+					_ = append(RelatedOf(diag), RelatedInfo{})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := len(c.Diagnostics()), 800; got != want {
+		t.Errorf("wrong number of diagnostics %d; want %d", got, want)
+	}
+}
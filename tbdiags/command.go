@@ -0,0 +1,157 @@
+//go:build !tinygo && !tbdiags_minimal
+// +build !tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Command describes an external program, such as a linter or compiler, to
+// be run by Run, which converts its output and failures into diagnostics.
+//
+// Command is not available when building with TinyGo or with the
+// tbdiags_minimal build tag, such as for WebAssembly, because those
+// environments can't run subprocesses.
+type Command struct {
+	// Path and Args are the program to run and its arguments, as for
+	// exec.Command.
+	Path string
+	Args []string
+
+	// Dir and Env are the working directory and environment of the program,
+	// as for the fields of the same names of exec.Cmd.
+	Dir string
+	Env []string
+
+	// Parser, if not nil, is applied to both the standard output and the
+	// standard error of the program to find the diagnostics it reports.
+	Parser *LineParser
+
+	// Timeout, if positive, is the longest the program may run before it's
+	// killed and reported as an error. When built with Go 1.20 or later,
+	// Run also stops waiting shortly afterwards for any background
+	// processes that the program started, which would otherwise keep it
+	// waiting for their output.
+	Timeout time.Duration
+}
+
+// commandOutputLines is the maximum number of lines of a failed program's
+// standard error that Run includes in the detail of its error diagnostic.
+const commandOutputLines = 20
+
+// Run runs the command and waits for it to finish, returning its standard
+// output and the diagnostics describing the result.
+//
+// The diagnostics are those found by the command's parser, if any, with a
+// warning if the parser couldn't read all of the output, such as because a
+// line is too long, followed by an error if the program couldn't be
+// started, timed out, was cancelled by the given context, or exited with a
+// non-zero status without its parser finding any errors. The detail of an error for a non-zero status includes
+// the last lines of the program's standard error, because a program that
+// fails without reporting a recognizable diagnostic usually says why there.
+func (c *Command) Run(ctx context.Context) ([]byte, Diagnostics) {
+	// limit is how long the program may run, which is shorter than Timeout
+	// if the given context has an earlier deadline.
+	limit := c.Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline).Round(time.Millisecond); limit <= 0 || remaining < limit {
+			limit = remaining
+		}
+	}
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Path, c.Args...)
+	cmd.Dir = c.Dir
+	cmd.Env = c.Env
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setWaitDelay(cmd)
+	err := cmd.Run()
+	if isWaitDelay(err) && ctx.Err() == nil {
+		// The program succeeded, but left behind background processes
+		// that are still writing to its output.
+		err = nil
+	}
+
+	name := filepath.Base(c.Path)
+	commandLine := strings.Join(append([]string{c.Path}, c.Args...), " ")
+
+	var diags Diagnostics
+	if c.Parser != nil {
+		for _, output := range []struct {
+			name string
+			buf  *bytes.Buffer
+		}{{"output", &stdout}, {"error output", &stderr}} {
+			parsed, err := c.Parser.Parse(bytes.NewReader(output.buf.Bytes()))
+			diags = diags.Append(parsed)
+			if err != nil {
+				diags = diags.Append(Sourceless(
+					Warning,
+					fmt.Sprintf("Failed to parse the %s of %s", output.name, name),
+					fmt.Sprintf("The %s of the command %q couldn't be read completely, so some of the diagnostics it reports may be missing: %s.", output.name, commandLine, err),
+				))
+			}
+		}
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		diags = diags.Append(Sourceless(
+			Error,
+			fmt.Sprintf("%s timed out", name),
+			fmt.Sprintf("The command %q didn't finish within %s, so it was stopped.", commandLine, limit),
+		))
+	case ctx.Err() != nil:
+		diags = diags.Append(Sourceless(
+			Error,
+			fmt.Sprintf("%s was cancelled", name),
+			fmt.Sprintf("The command %q was stopped before it finished: %s.", commandLine, ctx.Err()),
+		))
+	case errors.As(err, &exitErr):
+		if diags.HasErrors() {
+			break
+		}
+		detail := fmt.Sprintf("The command %q exited with status %d.", commandLine, exitErr.ExitCode())
+		if tail := lastLines(stderr.String(), commandOutputLines); tail != "" {
+			detail += "\n\nIts error output was:\n" + tail
+		}
+		diags = diags.Append(Sourceless(Error, fmt.Sprintf("%s failed", name), detail))
+	default:
+		diags = diags.Append(Sourceless(
+			Error,
+			fmt.Sprintf("Failed to run %s", name),
+			fmt.Sprintf("The command %q couldn't be started: %s.", commandLine, err),
+		))
+	}
+	return stdout.Bytes(), diags
+}
+
+// lastLines returns at most the last n non-empty lines of s, preceded by a
+// line noting how many were omitted, if any.
+func lastLines(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ""
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) <= n {
+		return s
+	}
+	omitted := len(lines) - n
+	return fmt.Sprintf("(%d earlier lines omitted)\n", omitted) + strings.Join(lines[omitted:], "\n")
+}
@@ -0,0 +1,94 @@
+//go:build !tinygo && !tbdiags_minimal
+// +build !tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandRun(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell available")
+	}
+	parser := &LineParser{
+		Pattern: regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+): (?P<severity>\w+): (?P<message>.*)$`),
+	}
+
+	tests := map[string]struct {
+		Script  string
+		Timeout time.Duration
+		Want    []string
+	}{
+		"success": {
+			`echo "a.txt:1: warning: tabs"`,
+			0,
+			[]string{"Warning: tabs"},
+		},
+		"reported errors": {
+			`echo "a.txt:2: error: bad" >&2; exit 1`,
+			0,
+			[]string{"Error: bad"},
+		},
+		"unexplained failure": {
+			`echo "something broke" >&2; exit 3`,
+			0,
+			[]string{"Error: sh failed"},
+		},
+		"line too long to parse": {
+			`head -c 70000 /dev/zero | tr '\0' x; echo; echo "a.txt:1: warning: tabs"`,
+			0,
+			[]string{"Warning: Failed to parse the output of sh"},
+		},
+		"timeout": {
+			`exec sleep 5`,
+			50 * time.Millisecond,
+			[]string{"Error: sh timed out"},
+		},
+		"timeout with background process": {
+			`sleep 5 & wait`,
+			50 * time.Millisecond,
+			[]string{"Error: sh timed out"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := &Command{
+				Path:    sh,
+				Args:    []string{"-c", test.Script},
+				Parser:  parser,
+				Timeout: test.Timeout,
+			}
+			start := time.Now()
+			_, diags := cmd.Run(context.Background())
+			if elapsed := time.Since(start); elapsed > 3*time.Second {
+				t.Errorf("took %s to run", elapsed)
+			}
+			var got []string
+			for _, diag := range diags {
+				got = append(got, diag.Severity().String()+": "+diag.Description().Summary)
+			}
+			if strings.Join(got, "\n") != strings.Join(test.Want, "\n") {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+
+	_, diags := (&Command{Path: sh, Args: []string{"-c", `echo "something broke" >&2; exit 3`}}).Run(context.Background())
+	if detail := diags[0].Description().Detail; !strings.Contains(detail, "status 3") || !strings.Contains(detail, "something broke") {
+		t.Errorf("detail doesn't explain the failure: %s", detail)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, diags = (&Command{Path: sh, Args: []string{"-c", "exec sleep 5"}}).Run(ctx)
+	if detail := diags[0].Description().Detail; !regexp.MustCompile(`within (9\d|100)ms`).MatchString(detail) {
+		t.Errorf("detail doesn't give the deadline of the context: %s", detail)
+	}
+}
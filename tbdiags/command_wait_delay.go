@@ -0,0 +1,27 @@
+//go:build go1.20 && !tinygo && !tbdiags_minimal
+// +build go1.20,!tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"errors"
+	"os/exec"
+	"time"
+)
+
+// commandWaitDelay is how long Run waits for the output of a program to be
+// closed after the program exits or is stopped.
+const commandWaitDelay = 500 * time.Millisecond
+
+// setWaitDelay stops Run from waiting indefinitely for any background
+// processes started by the program, which share its output and so keep
+// Wait from returning even after the program itself has been killed.
+func setWaitDelay(cmd *exec.Cmd) {
+	cmd.WaitDelay = commandWaitDelay
+}
+
+// isWaitDelay returns true if the given error from running a program means
+// only that its output was still open when the wait delay expired.
+func isWaitDelay(err error) bool {
+	return errors.Is(err, exec.ErrWaitDelay)
+}
@@ -0,0 +1,17 @@
+//go:build !go1.20 && !tinygo && !tbdiags_minimal
+// +build !go1.20,!tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"os/exec"
+)
+
+// Before Go 1.20, exec.Cmd can't stop waiting for the output of background
+// processes started by a program, so Run waits for them to finish.
+
+func setWaitDelay(cmd *exec.Cmd) {}
+
+func isWaitDelay(err error) bool {
+	return false
+}
@@ -0,0 +1,135 @@
+package tbdiags
+
+import (
+	"strings"
+)
+
+// CompactByRange returns a new diagnostics list where all of the diagnostics
+// that have identical subject ranges are merged into a single diagnostic,
+// to reduce noise when several rules all flag the same token.
+//
+// A merged diagnostic takes its position in the result, its summary, its
+// address and its context from the first of the diagnostics it was merged
+// from, and it has a detail that lists the summary and detail of each of
+// them as bullet points, along with the related information of all of
+// them. It is an error if any of the original diagnostics was an error, or a
+// warning otherwise. It keeps the code, help URL and origin of the original
+// diagnostics only if they all have the same one, and only the tags they all
+// have. Any other information, such as extra info, fixes, categories and
+// attributes, is not carried over, because it describes one particular
+// problem rather than the merged one.
+//
+// Subject ranges are compared using FileID, so ranges in the same file
+// reached by different paths are considered identical. Diagnostics without a
+// subject range are never merged. The receiver is not modified.
+func (diags Diagnostics) CompactByRange() Diagnostics {
+	type rangeKey struct {
+		file       FileID
+		start, end SourcePos
+	}
+
+	var ret Diagnostics
+	ids := make(fileIDCache)
+	groups := make(map[rangeKey][]Diagnostic)
+	var order []rangeKey
+	for _, diag := range diags {
+		subject := subjectOf(diag)
+		if subject == nil {
+			ret = append(ret, diag)
+			continue
+		}
+		key := rangeKey{ids.SubjectID(subject), subject.Start, subject.End}
+		if _, exists := groups[key]; !exists {
+			order = append(order, key)
+			// We'll insert the merged result in this position later.
+			ret = append(ret, nil)
+		}
+		groups[key] = append(groups[key], diag)
+	}
+
+	next := 0
+	for i, diag := range ret {
+		if diag != nil {
+			continue
+		}
+		ret[i] = compactDiagnostics(groups[order[next]])
+		next++
+	}
+	return ret
+}
+
+func compactDiagnostics(group []Diagnostic) Diagnostic {
+	if len(group) == 1 {
+		return group[0]
+	}
+
+	first := group[0]
+	firstDesc := first.Description()
+	severity := first.Severity()
+	code, helpURL, origin := firstDesc.Code, firstDesc.HelpURL, OriginOf(first)
+	tags := TagsOf(first)
+	var detail strings.Builder
+	var related []RelatedInfo
+	for i, diag := range group {
+		related = append(related, RelatedOf(diag)...)
+		if diag.Severity().Level() > severity.Level() {
+			severity = diag.Severity()
+		}
+		desc := diag.Description()
+		if desc.Code != code {
+			code = ""
+		}
+		if desc.HelpURL != helpURL {
+			helpURL = ""
+		}
+		if OriginOf(diag) != origin {
+			origin = ""
+		}
+		tags = sharedTags(tags, TagsOf(diag))
+		if i > 0 {
+			detail.WriteByte('\n')
+		}
+		detail.WriteString("- ")
+		detail.WriteString(desc.Summary)
+		if desc.Detail != "" {
+			detail.WriteString(": ")
+			detail.WriteString(desc.Detail)
+		}
+	}
+
+	src := first.Source()
+	var ret Diagnostic = sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{
+			severity: severity,
+			summary:  firstDesc.Summary,
+			detail:   detail.String(),
+			address:  firstDesc.Address,
+			code:     code,
+			helpURL:  helpURL,
+		},
+		subject: src.Subject,
+		context: src.Context,
+	}
+	if len(related) > 0 {
+		ret = WithRelated(ret, related...)
+	}
+	if origin != "" {
+		ret = WithOrigin(ret, origin)
+	}
+	if len(tags) > 0 {
+		ret = WithTags(ret, tags...)
+	}
+	return ret
+}
+
+// sharedTags returns the tags from a that are also in b, preserving their
+// order.
+func sharedTags(a, b []Tag) []Tag {
+	var ret []Tag
+	for _, tag := range a {
+		if containsTag(b, tag) {
+			ret = append(ret, tag)
+		}
+	}
+	return ret
+}
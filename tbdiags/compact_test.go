@@ -0,0 +1,101 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestDiagnosticsCompactByRange(t *testing.T) {
+	rng := func(start int) *SourceRange {
+		return &SourceRange{
+			Filename: "main.tb",
+			Start:    SourcePos{Line: 1, Column: start + 1, Byte: start},
+			End:      SourcePos{Line: 1, Column: start + 2, Byte: start + 1},
+		}
+	}
+	diag := func(severity Severity, summary, detail string, subject *SourceRange) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: severity,
+				summary:  summary,
+				detail:   detail,
+			},
+			subject: subject,
+		}
+	}
+
+	diags := Diagnostics{
+		diag(Warning, "Unusual name", "Names usually start with a letter.", rng(0)),
+		Sourceless(Warning, "Sourceless", ""),
+		diag(Warning, "Elsewhere", "", rng(5)),
+		diag(Error, "Reserved name", "", rng(0)),
+	}
+	got := diags.CompactByRange()
+
+	if len(got) != 3 {
+		t.Fatalf("wrong number of diagnostics %d; want 3", len(got))
+	}
+	if got, want := got[0].Severity(), Error; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	desc := got[0].Description()
+	if got, want := desc.Summary, "Unusual name"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	wantDetail := "- Unusual name: Names usually start with a letter.\n- Reserved name"
+	if desc.Detail != wantDetail {
+		t.Errorf("wrong detail\ngot:\n%s\nwant:\n%s", desc.Detail, wantDetail)
+	}
+	if got, want := *got[0].Source().Subject, *rng(0); got != want {
+		t.Errorf("wrong subject %#v; want %#v", got, want)
+	}
+	if got, want := got[1].Description().Summary, "Sourceless"; got != want {
+		t.Errorf("wrong second summary %q; want %q", got, want)
+	}
+	if got, want := got[2].Description().Summary, "Elsewhere"; got != want {
+		t.Errorf("wrong third summary %q; want %q", got, want)
+	}
+}
+
+func TestDiagnosticsCompactByRangeMetadata(t *testing.T) {
+	subject := &SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 1, Column: 1, Byte: 0},
+		End:      SourcePos{Line: 1, Column: 2, Byte: 1},
+	}
+	diag := func(summary, code string, tags ...Tag) Diagnostic {
+		var ret Diagnostic = sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Warning,
+				summary:  summary,
+				code:     code,
+				helpURL:  "https://example.com/" + code,
+			},
+			subject: subject,
+		}
+		return WithTags(WithOrigin(ret, "linter"), tags...)
+	}
+
+	got := Diagnostics{
+		diag("Unused", "TB2001", TagUnnecessary, TagDeprecated),
+		diag("Also unused", "TB2001", TagUnnecessary),
+	}.CompactByRange()
+	desc := got[0].Description()
+	if desc.Code != "TB2001" || desc.HelpURL != "https://example.com/TB2001" {
+		t.Errorf("shared code and help URL not kept: %q, %q", desc.Code, desc.HelpURL)
+	}
+	if got := OriginOf(got[0]); got != "linter" {
+		t.Errorf("wrong origin %q", got)
+	}
+	if got := TagsOf(got[0]); len(got) != 1 || got[0] != TagUnnecessary {
+		t.Errorf("wrong tags %v", got)
+	}
+
+	got = Diagnostics{
+		diag("Unused", "TB2001"),
+		diag("Reserved", "TB2002"),
+	}.CompactByRange()
+	desc = got[0].Description()
+	if desc.Code != "" || desc.HelpURL != "" {
+		t.Errorf("differing code and help URL kept: %q, %q", desc.Code, desc.HelpURL)
+	}
+}
@@ -0,0 +1,183 @@
+package conformance
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+)
+
+// cases are the diagnostics of each vector, in order of name. Each must have
+// a corresponding file in the directory for Version.
+var cases = []struct {
+	name  string
+	diags func() tbdiags.Diagnostics
+}{
+	{"archive", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.ArchiveError("module.zip", "main.tb", 120, errors.New("unexpected end of file")),
+		}
+	}},
+	{"attributes", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithAttributes(tbdiags.Sourceless(tbdiags.Error, "Resource failed", ""), map[string]interface{}{
+				"resource_id": "i-123",
+				"attempts":    3,
+				"retryable":   true,
+			}),
+		}
+	}},
+	{"children", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithChildren(
+				tbdiags.Sourceless(tbdiags.Error, "Module validation failed", ""),
+				tbdiags.Diagnostics{
+					tbdiags.Sourceless(tbdiags.Error, "Missing name", ""),
+					tbdiags.Sourceless(tbdiags.Warning, "Deprecated argument", ""),
+				},
+			),
+		}
+	}},
+	{"codes", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithCode(tbdiags.Sourceless(tbdiags.Error, "Unknown setting", ""), "TB1001"),
+		}
+	}},
+	{"emitters", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.Sourceless(tbdiags.Error, "Missing name", ""),
+		}.WithEmitterVersion("validator", "1.2.0").Append(
+			tbdiags.WithEmitter(
+				tbdiags.Sourceless(tbdiags.Warning, "Slow query", ""),
+				tbdiags.Emitter{Component: "storage"},
+			),
+		)
+	}},
+	{"empty", func() tbdiags.Diagnostics {
+		return nil
+	}},
+	{"exact_range", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			&diagnostic{
+				severity: tbdiags.Error,
+				summary:  "Invalid value",
+				detail:   "The value must be a number.",
+				address:  "settings.port",
+				subject: &tbdiags.SourceRange{
+					Filename: "main.tb",
+					Start:    tbdiags.SourcePos{Line: 3, Column: 10, Byte: 42},
+					End:      tbdiags.SourcePos{Line: 3, Column: 15, Byte: 47},
+				},
+				context: &tbdiags.SourceRange{
+					Filename: "main.tb",
+					Start:    tbdiags.SourcePos{Line: 3, Column: 3, Byte: 35},
+					End:      tbdiags.SourcePos{Line: 3, Column: 15, Byte: 47},
+				},
+			},
+		}
+	}},
+	{"fixes", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithFixes(
+				tbdiags.Sourceless(tbdiags.Warning, "Unused import", ""),
+				tbdiags.SuggestedFix{
+					Message: "Remove the unused import",
+					Edits: []tbdiags.TextEdit{{
+						Range: tbdiags.SourceRange{
+							Filename: "main.tb",
+							Start:    tbdiags.SourcePos{Line: 2, Column: 1, Byte: 10},
+							End:      tbdiags.SourcePos{Line: 3, Column: 1, Byte: 22},
+						},
+					}},
+				},
+			),
+		}
+	}},
+	{"help_urls", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithHelpURL(tbdiags.Sourceless(tbdiags.Warning, "Deprecated setting", ""), "https://example.com/migrate"),
+		}
+	}},
+	{"imprecise_ranges", func() tbdiags.Diagnostics {
+		line := tbdiags.LineRange("main.tb", 7)
+		file := tbdiags.FileRange("other.tb")
+		return tbdiags.Diagnostics{
+			&diagnostic{severity: tbdiags.Warning, summary: "Line-level problem", subject: &line},
+			&diagnostic{severity: tbdiags.Warning, summary: "File-level problem", subject: &file},
+		}
+	}},
+	{"metadata", func() tbdiags.Diagnostics {
+		var diag tbdiags.Diagnostic = &diagnostic{severity: tbdiags.Error, summary: "Unsupported mode"}
+		diag = tbdiags.WithValidValues(diag, []string{"fast", "safe"})
+		diag = tbdiags.WithOrigin(diag, "linter")
+		diag = tbdiags.WithCategory(diag, tbdiags.CategoryDeprecation)
+		return tbdiags.Diagnostics{diag}
+	}},
+	{"provenance", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.Sourceless(tbdiags.Error, "Build failed", ""),
+		}.WithHop(tbdiags.Hop{
+			Host:      "build-3",
+			Process:   "worker[1234]",
+			Component: "worker",
+			Time:      time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+		}).WithHop(tbdiags.Hop{Component: "coordinator"})
+	}},
+	{"related", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithRelated(
+				tbdiags.Sourceless(tbdiags.Error, "Duplicate name", ""),
+				tbdiags.RelatedInfo{Message: "first defined here", Range: tbdiags.LineRange("main.tb", 1)},
+				tbdiags.RelatedInfo{Message: "used here", Range: tbdiags.FileRange("other.tb")},
+			),
+		}
+	}},
+	{"severities", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.Sourceless(tbdiags.Hint, "A hint", ""),
+			tbdiags.Sourceless(tbdiags.Warning, "A warning", "With detail."),
+			tbdiags.Sourceless(tbdiags.Error, "An error", ""),
+			tbdiags.Sourceless(tbdiags.Fatal, "A fatal error", ""),
+		}
+	}},
+	{"tags", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithTags(tbdiags.Sourceless(tbdiags.Hint, "Unused import", ""), tbdiags.TagUnnecessary),
+		}
+	}},
+	{"timestamps", func() tbdiags.Diagnostics {
+		return tbdiags.Diagnostics{
+			tbdiags.WithTimestamp(
+				tbdiags.Sourceless(tbdiags.Warning, "Slow response", ""),
+				time.Date(2024, 3, 1, 9, 30, 0, 500000000, time.UTC),
+			),
+		}
+	}},
+}
+
+// diagnostic is a minimal implementation of tbdiags.Diagnostic, for vectors
+// with sources.
+type diagnostic struct {
+	severity                 tbdiags.Severity
+	summary, detail, address string
+	subject, context         *tbdiags.SourceRange
+}
+
+func (d *diagnostic) Severity() tbdiags.Severity {
+	return d.severity
+}
+
+func (d *diagnostic) Description() tbdiags.Description {
+	return tbdiags.Description{
+		Address: d.address,
+		Summary: d.summary,
+		Detail:  d.detail,
+	}
+}
+
+func (d *diagnostic) Source() tbdiags.Source {
+	return tbdiags.Source{
+		Subject: d.subject,
+		Context: d.context,
+	}
+}
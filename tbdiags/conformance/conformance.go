@@ -0,0 +1,130 @@
+package conformance
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+)
+
+// Version is the version of the JSON format described by the vectors
+// returned by Vectors.
+const Version = "v1"
+
+//go:embed v1/*.json
+var files embed.FS
+
+// Vector is a single golden vector: a list of diagnostics and its expected
+// JSON representation.
+type Vector struct {
+	// Name identifies the vector. It's also the name of the vector's file,
+	// without its ".json" suffix.
+	Name string
+
+	// Diagnostics are the diagnostics that the vector represents.
+	Diagnostics tbdiags.Diagnostics
+
+	// JSON is the expected representation of Diagnostics. It's indented for
+	// readability, so encoders must be compared with it using
+	// Equivalent rather than byte-for-byte.
+	JSON []byte
+}
+
+// Vectors returns all of the vectors of the current version, in order of
+// name.
+func Vectors() []Vector {
+	ret := make([]Vector, len(cases))
+	for i, c := range cases {
+		src, err := files.ReadFile(Version + "/" + c.name + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("missing golden vector %q", c.name))
+		}
+		ret[i] = Vector{
+			Name:        c.name,
+			Diagnostics: c.diags(),
+			JSON:        src,
+		}
+	}
+	return ret
+}
+
+// VerifyEncoder checks that the given encoder produces JSON equivalent to
+// each vector from the vector's diagnostics, returning an error describing
+// the first vector for which it doesn't.
+//
+// The "reported_at" properties of diagnostics are ignored, because they
+// depend on whether development mode is enabled and on where the
+// diagnostics were created.
+func VerifyEncoder(encode func(tbdiags.Diagnostics) ([]byte, error)) error {
+	for _, v := range Vectors() {
+		got, err := encode(v.Diagnostics)
+		if err != nil {
+			return fmt.Errorf("vector %q: failed to encode: %s", v.Name, err)
+		}
+		if err := compare(v, got); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyRoundTrip checks that the given function, which decodes the JSON of
+// each vector and then encodes the result again, produces JSON equivalent
+// to each vector, returning an error describing the first vector for which
+// it doesn't. As for VerifyEncoder, "reported_at" properties are ignored.
+func VerifyRoundTrip(roundTrip func([]byte) ([]byte, error)) error {
+	for _, v := range Vectors() {
+		got, err := roundTrip(v.JSON)
+		if err != nil {
+			return fmt.Errorf("vector %q: failed to round-trip: %s", v.Name, err)
+		}
+		if err := compare(v, got); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Equivalent returns true if the two given JSON documents represent the
+// same values, regardless of whitespace and the order of object properties.
+// Invalid JSON is equivalent to nothing.
+func Equivalent(a, b []byte) bool {
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func compare(v Vector, got []byte) error {
+	var gotv, wantv interface{}
+	if json.Unmarshal(got, &gotv) == nil && json.Unmarshal(v.JSON, &wantv) == nil {
+		withoutReportedAt(gotv)
+		withoutReportedAt(wantv)
+		if reflect.DeepEqual(gotv, wantv) {
+			return nil
+		}
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, got, "", "  ") == nil {
+		got = indented.Bytes()
+	}
+	return fmt.Errorf("vector %q: wrong result\ngot:\n%s\nwant:\n%s", v.Name, got, v.JSON)
+}
+
+// withoutReportedAt removes the "reported_at" properties from the given
+// decoded diagnostics and their children.
+func withoutReportedAt(v interface{}) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, elem := range v {
+			withoutReportedAt(elem)
+		}
+	case map[string]interface{}:
+		delete(v, "reported_at")
+		withoutReportedAt(v["children"])
+	}
+}
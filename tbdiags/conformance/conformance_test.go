@@ -0,0 +1,87 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden vectors of the current version from the encoder's output")
+
+func TestVerifyEncoder(t *testing.T) {
+	encode := func(diags tbdiags.Diagnostics) ([]byte, error) {
+		return diags.MarshalJSON()
+	}
+	if *update {
+		for _, c := range cases {
+			src, err := encode(c.diags())
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			json.Indent(&buf, src, "", "  ")
+			buf.WriteByte('\n')
+			if err := os.WriteFile(filepath.Join(Version, c.name+".json"), buf.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The vectors are embedded when the package is compiled, so the
+		// rewritten files can't be verified until the next run.
+		return
+	}
+
+	if err := VerifyEncoder(encode); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVerifyRoundTrip(t *testing.T) {
+	identity := func(src []byte) ([]byte, error) {
+		return src, nil
+	}
+	if err := VerifyRoundTrip(identity); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	lossy := func(src []byte) ([]byte, error) {
+		var diags []map[string]interface{}
+		if err := json.Unmarshal(src, &diags); err != nil {
+			return nil, err
+		}
+		for _, diag := range diags {
+			delete(diag, "detail")
+		}
+		return json.Marshal(diags)
+	}
+	if err := VerifyRoundTrip(lossy); err == nil {
+		t.Errorf("no error for a round trip that loses details")
+	}
+}
+
+func TestVerifyRoundTripDecoder(t *testing.T) {
+	roundTrip := func(src []byte) ([]byte, error) {
+		var diags tbdiags.Diagnostics
+		if err := json.Unmarshal(src, &diags); err != nil {
+			return nil, err
+		}
+		return diags.MarshalJSON()
+	}
+	if err := VerifyRoundTrip(roundTrip); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestVectorFiles(t *testing.T) {
+	names, err := filepath.Glob(filepath.Join(Version, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(names), len(Vectors()); got != want {
+		t.Errorf("%d vector files but %d cases", got, want)
+	}
+}
@@ -0,0 +1,19 @@
+// Package conformance contains versioned golden vectors for the JSON
+// representation of diagnostics produced by tbdiags.Diagnostics.MarshalJSON,
+// so that implementations in other languages can check that they encode and
+// decode diagnostics compatibly with this package.
+//
+// Each vector is a JSON file in a directory named for the version of the
+// format, such as v1/severities.json. A new version directory is added
+// whenever the format changes incompatibly, and the files of existing
+// versions never change, so other implementations can check against
+// whichever version they support. Properties added compatibly, which
+// decoders must ignore if they don't recognize them, are added to new
+// vectors within the current version.
+//
+// Implementations in other languages can use the files directly: an
+// encoder conforms if it produces JSON equivalent to each vector from the
+// same diagnostics, and a decoder conforms if decoding and re-encoding each
+// vector produces equivalent JSON. Go implementations can use VerifyEncoder
+// and VerifyRoundTrip, which make those comparisons.
+package conformance
@@ -0,0 +1,130 @@
+package tbdiags
+
+import (
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ContextualDiagnostic is implemented by diagnostics that refer to part of
+// a configuration by its logical path rather than by a source range, such
+// as those produced while validating decoded values, which carry no source
+// information of their own. Once the configuration is known, Elaborate
+// returns an equivalent diagnostic with a subject range.
+type ContextualDiagnostic interface {
+	Diagnostic
+
+	// AttributePath returns the path of the value that the diagnostic is
+	// about, relative to the configuration body.
+	AttributePath() cty.Path
+
+	// Elaborate returns a diagnostic with a subject range found using the
+	// given resolver. It returns the receiver unchanged if it already has
+	// a subject or if the resolver can find neither the path nor any of
+	// its ancestors.
+	Elaborate(resolver PathResolver) Diagnostic
+}
+
+// PathResolver returns the source range of the value at the given path in a
+// configuration body, or false if the path can't be found. Producers that
+// parse configuration with position information supply one to Elaborate.
+type PathResolver func(path cty.Path) (SourceRange, bool)
+
+// AttributeValue returns a diagnostic about the value at the given path in a
+// configuration body, which has no source range until it's elaborated using
+// Diagnostics.Elaborate.
+//
+// The summary and detail should make sense without a source range, because
+// the diagnostic may never be elaborated.
+func AttributeValue(severity Severity, summary, detail string, path cty.Path) Diagnostic {
+	return withCaller(attributeDiagnostic{
+		diagnosticBase: diagnosticBase{
+			severity: severity,
+			summary:  summary,
+			detail:   detail,
+		},
+		path: path.Copy(),
+	})
+}
+
+// AttributePathOf returns the configuration path of the given diagnostic, or
+// false if it's not a ContextualDiagnostic.
+func AttributePathOf(diag Diagnostic) (cty.Path, bool) {
+	var ret cty.Path
+	found := findDiagnostic(diag, func(diag Diagnostic) bool {
+		cd, ok := diag.(ContextualDiagnostic)
+		if ok {
+			ret = cd.AttributePath()
+		}
+		return ok
+	})
+	return ret, found
+}
+
+// Elaborate returns a copy of the receiver in which each ContextualDiagnostic
+// is replaced by the result of its Elaborate method with the given resolver.
+// Contextual diagnostics wrapped by other diagnostics, such as by
+// WithEmitter, keep their wrappers and are given the subject that their
+// Elaborate method found. Other diagnostics are unchanged.
+func (diags Diagnostics) Elaborate(resolver PathResolver) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		ret[i] = elaborate(diag, resolver)
+	}
+	return ret
+}
+
+// elaborate returns the given diagnostic elaborated as for
+// Diagnostics.Elaborate.
+func elaborate(diag Diagnostic, resolver PathResolver) Diagnostic {
+	if cd, ok := diag.(ContextualDiagnostic); ok {
+		return cd.Elaborate(resolver)
+	}
+	var cd ContextualDiagnostic
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		var ok bool
+		cd, ok = diag.(ContextualDiagnostic)
+		return ok
+	})
+	src := diag.Source()
+	if cd == nil || src.Subject != nil {
+		return diag
+	}
+	if src.Subject = cd.Elaborate(resolver).Source().Subject; src.Subject == nil {
+		return diag
+	}
+	return overrideSource{diag, src}
+}
+
+type attributeDiagnostic struct {
+	diagnosticBase
+	path    cty.Path
+	subject *SourceRange
+}
+
+var _ ContextualDiagnostic = attributeDiagnostic{}
+
+func (d attributeDiagnostic) Source() Source {
+	return Source{Subject: d.subject}
+}
+
+func (d attributeDiagnostic) AttributePath() cty.Path {
+	return d.path[:len(d.path):len(d.path)]
+}
+
+func (d attributeDiagnostic) Elaborate(resolver PathResolver) Diagnostic {
+	if d.subject != nil || resolver == nil {
+		return d
+	}
+	// The exact value may not appear in the configuration, such as when
+	// it's an element of a collection produced by an expression, in which
+	// case the nearest ancestor that does is the next best thing.
+	for n := len(d.path); n >= 0; n-- {
+		if rng, ok := resolver(d.path[:n:n]); ok {
+			d.subject = &rng
+			return d
+		}
+	}
+	return d
+}
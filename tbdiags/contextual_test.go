@@ -0,0 +1,77 @@
+package tbdiags
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestDiagnosticsElaborate(t *testing.T) {
+	listRange := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 3, Column: 3, Byte: 20},
+		End:      SourcePos{Line: 3, Column: 20, Byte: 37},
+	}
+	nameRange := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 2, Column: 3, Byte: 8},
+		End:      SourcePos{Line: 2, Column: 15, Byte: 20},
+	}
+	resolver := func(path cty.Path) (SourceRange, bool) {
+		switch FormatCtyPath(path) {
+		case ".name":
+			return nameRange, true
+		case ".ports":
+			return listRange, true
+		}
+		return SourceRange{}, false
+	}
+
+	diags := Diagnostics{
+		AttributeValue(Error, "Invalid name", "", cty.GetAttrPath("name")),
+		AttributeValue(Error, "Invalid port", "", cty.GetAttrPath("ports").IndexInt(2)),
+		AttributeValue(Warning, "Unknown setting", "", cty.GetAttrPath("other")),
+		Sourceless(Error, "Unrelated", ""),
+		WithEmitter(AttributeValue(Error, "Wrapped", "", cty.GetAttrPath("name")), Emitter{Component: "validator"}),
+	}
+	got := diags.Elaborate(resolver)
+
+	wantSubjects := []*SourceRange{&nameRange, &listRange, nil, nil, &nameRange}
+	for i, diag := range got {
+		subject := diag.Source().Subject
+		switch {
+		case wantSubjects[i] == nil && subject != nil:
+			t.Errorf("diagnostic %d has subject %v; want none", i, subject)
+		case wantSubjects[i] != nil && (subject == nil || *subject != *wantSubjects[i]):
+			t.Errorf("diagnostic %d has subject %v; want %v", i, subject, wantSubjects[i])
+		}
+		if diag.Description() != diags[i].Description() {
+			t.Errorf("diagnostic %d has description %#v; want %#v", i, diag.Description(), diags[i].Description())
+		}
+	}
+	if diags[0].Source().Subject != nil {
+		t.Errorf("Elaborate modified the receiver")
+	}
+
+	path, ok := AttributePathOf(got[1])
+	if !ok || FormatCtyPath(path) != ".ports[2]" {
+		t.Errorf("wrong path %q", FormatCtyPath(path))
+	}
+	if _, ok := EmitterOf(got[4]); !ok {
+		t.Errorf("elaborated diagnostic lost its wrapper")
+	}
+	if _, ok := AttributePathOf(got[3]); ok {
+		t.Errorf("found a path on a diagnostic without one")
+	}
+
+	// Elaborating again doesn't replace a subject that was already found.
+	again := got.Elaborate(func(cty.Path) (SourceRange, bool) {
+		return SourceRange{Filename: "other.tb"}, true
+	})
+	if again[0].Source().Subject.Filename != "main.tb" {
+		t.Errorf("subject was replaced: %v", again[0].Source().Subject)
+	}
+	if again[2].Source().Subject.Filename != "other.tb" {
+		t.Errorf("unresolved diagnostic wasn't elaborated")
+	}
+}
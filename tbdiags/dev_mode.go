@@ -0,0 +1,85 @@
+package tbdiags
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+)
+
+// devMode is non-zero when development mode is enabled by SetDevMode.
+var devMode int32
+
+// SetDevMode enables or disables development mode, in which the functions
+// that create diagnostics, such as Sourceless, SimpleWarning, Wrap and
+// Builder.Build, record the file and line of the code that called them, so
+// that a diagnostic can be traced back to the code path that produced it.
+// The location is available from ReportedAt, is rendered by a Renderer
+// with Verbose set, and is included in JSON as "reported_at".
+//
+// Capturing callers has a small cost for every diagnostic, so development
+// mode is disabled by default and is intended only for debugging and tests.
+// Building with the tbdiags_devmode build tag enables it from the start, so
+// that a debug build of a program can report where its diagnostics came
+// from without any change to its code.
+func SetDevMode(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&devMode, v)
+}
+
+// DiagnosticReportedAt is an optional interface implemented by diagnostics
+// that record the location in Go source code where they were created.
+// Diagnostics.MarshalJSON encodes the location as the "reported_at"
+// property, such as "config/load.go:42".
+type DiagnosticReportedAt interface {
+	ReportedAt() string
+}
+
+// ReportedAt returns the location in Go source code where the given
+// diagnostic was created, such as "config/load.go:42", if it
+// implements DiagnosticReportedAt, or an empty string otherwise.
+func ReportedAt(diag Diagnostic) string {
+	var ret string
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		r, ok := diag.(DiagnosticReportedAt)
+		if ok {
+			ret = r.ReportedAt()
+		}
+		return ok
+	})
+	return ret
+}
+
+// withCaller returns the given diagnostic, which must have been created by
+// the caller of withCaller's caller, annotated with that location if
+// development mode is enabled, or the diagnostic unchanged otherwise.
+func withCaller(diag Diagnostic) Diagnostic {
+	if atomic.LoadInt32(&devMode) == 0 {
+		return diag
+	}
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return diag
+	}
+	file = filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file))
+	return withReportedAt{
+		Diagnostic: diag,
+		at:         fmt.Sprintf("%s:%d", filepath.ToSlash(file), line),
+	}
+}
+
+type withReportedAt struct {
+	Diagnostic
+	at string
+}
+
+func (d withReportedAt) ReportedAt() string {
+	return d.at
+}
+
+func (d withReportedAt) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
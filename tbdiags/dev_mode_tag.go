@@ -0,0 +1,8 @@
+//go:build tbdiags_devmode
+// +build tbdiags_devmode
+
+package tbdiags
+
+func init() {
+	SetDevMode(true)
+}
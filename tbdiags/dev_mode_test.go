@@ -0,0 +1,78 @@
+package tbdiags
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDevMode(t *testing.T) {
+	// Development mode is enabled from the start when testing with the
+	// tbdiags_devmode build tag, so it's restored afterwards.
+	defer SetDevMode(atomic.LoadInt32(&devMode) != 0)
+
+	SetDevMode(false)
+	if got := ReportedAt(Sourceless(Warning, "Unexpected", "")); got != "" {
+		t.Errorf("location recorded outside of development mode: %s", got)
+	}
+
+	SetDevMode(true)
+
+	diag := Sourceless(Warning, "Unexpected", "")
+	at := ReportedAt(diag)
+	if !strings.HasPrefix(at, "tbdiags/dev_mode_test.go:") {
+		t.Fatalf("wrong location %q", at)
+	}
+	if got := ReportedAt(SimpleWarning("Unexpected")); !strings.HasPrefix(got, "tbdiags/dev_mode_test.go:") {
+		t.Errorf("wrong location %q for SimpleWarning", got)
+	}
+	if got := ReportedAt(SimpleError("Unexpected", "")); !strings.HasPrefix(got, "tbdiags/dev_mode_test.go:") {
+		t.Errorf("wrong location %q for SimpleError", got)
+	}
+	if got := ReportedAt(SimpleWarningDetail("Unexpected", "")); !strings.HasPrefix(got, "tbdiags/dev_mode_test.go:") {
+		t.Errorf("wrong location %q for SimpleWarningDetail", got)
+	}
+	if got := ReportedAt(New(Hint, "Unexpected", "")); !strings.HasPrefix(got, "tbdiags/dev_mode_test.go:") {
+		t.Errorf("wrong location %q for New", got)
+	}
+
+	got := (&Renderer{Verbose: true}).RenderString(Diagnostics{diag})
+	if want := "Warning: Unexpected (reported at " + at + ")\n\n"; got != want {
+		t.Errorf("wrong rendering\ngot:  %q\nwant: %q", got, want)
+	}
+	got = (&Renderer{}).RenderString(Diagnostics{diag})
+	if want := "Warning: Unexpected\n\n"; got != want {
+		t.Errorf("wrong non-verbose rendering\ngot:  %q\nwant: %q", got, want)
+	}
+
+	js, err := Diagnostics{diag}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"reported_at":"` + at + `"`; !strings.Contains(string(js), want) {
+		t.Errorf("JSON doesn't contain %s: %s", want, js)
+	}
+	var decoded Diagnostics
+	if err := decoded.UnmarshalJSON(js); err != nil {
+		t.Fatal(err)
+	}
+	if got := ReportedAt(decoded[0]); got != at {
+		t.Errorf("wrong location %q after decoding; want %q", got, at)
+	}
+
+	// Diagnostics created in different places are still equal.
+	other := Sourceless(Warning, "Unexpected", "")
+	if ReportedAt(other) == at || !(Diagnostics{other}).Equal(Diagnostics{diag}) {
+		t.Errorf("diagnostics created in different places are not equal")
+	}
+}
+
+var reportedAtPattern = regexp.MustCompile(`,"reported_at":"[^"]*"| \(reported at [^)]*\)`)
+
+// withoutReportedAt returns the given JSON or verbose rendering without the
+// locations recorded in development mode, so that tests can compare it
+// regardless of whether they're run with the tbdiags_devmode build tag.
+func withoutReportedAt(s string) string {
+	return reportedAtPattern.ReplaceAllString(s, "")
+}
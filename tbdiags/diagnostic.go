@@ -1,5 +1,13 @@
 package tbdiags
 
+import (
+	"fmt"
+	"strings"
+)
+
+// Diagnostic is implemented by each of the problems reported by a
+// producer. Diagnostics must not change once created, so that they can be
+// read by any number of goroutines at once.
 type Diagnostic interface {
 	Severity() Severity
 	Description() Description
@@ -13,12 +21,137 @@ type Severity rune
 const (
 	Error   Severity = 'E'
 	Warning Severity = 'W'
+
+	// Hint is for non-actionable suggestions, such as "consider using X
+	// instead", which shouldn't cause CI gating on warnings to fail.
+	Hint Severity = 'H'
+
+	// Fatal is for errors after which processing must stop immediately,
+	// unlike other errors, which allow processing to continue so that
+	// further problems can be reported together. Fatal diagnostics are
+	// errors for all other purposes, such as Diagnostics.HasErrors.
+	Fatal Severity = 'F'
 )
 
+// Level returns the position of the severity in the order from least to most
+// severe: 0 for Hint, 1 for Warning, 2 for Error and 3 for Fatal. Policy code
+// should compare severities using Level, MoreSevereThan or AtLeast rather
+// than comparing their rune values, which have no meaningful order.
+func (s Severity) Level() int {
+	switch s {
+	case Hint:
+		return 0
+	case Warning:
+		return 1
+	case Fatal:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// MoreSevereThan returns true if s is strictly more severe than other.
+func (s Severity) MoreSevereThan(other Severity) bool {
+	return s.Level() > other.Level()
+}
+
+// AtLeast returns true if s is at least as severe as other, so that, for
+// example, sev.AtLeast(Warning) is true for everything except hints.
+func (s Severity) AtLeast(other Severity) bool {
+	return s.Level() >= other.Level()
+}
+
+// Severities returns all of the severities, ordered from least to most
+// severe.
+func Severities() []Severity {
+	return []Severity{Hint, Warning, Error, Fatal}
+}
+
+// ParseSeverity returns the severity with the given name, such as "warning",
+// ignoring case, so that configuration files and command line flags can
+// refer to severities by name.
+func ParseSeverity(name string) (Severity, error) {
+	for _, sev := range Severities() {
+		if strings.EqualFold(name, sev.String()) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid severity %q: must be one of \"hint\", \"warning\", \"error\" or \"fatal\"", name)
+}
+
+// MarshalText implements encoding.TextMarshaler, returning the lowercase name
+// of the severity, such as "warning", as used in the JSON format.
+func (s Severity) MarshalText() ([]byte, error) {
+	switch s {
+	case Hint, Warning, Error, Fatal:
+		return []byte(strings.ToLower(s.String())), nil
+	default:
+		return nil, fmt.Errorf("invalid severity %s", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the names
+// accepted by ParseSeverity.
+func (s *Severity) UnmarshalText(text []byte) error {
+	sev, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sev
+	return nil
+}
+
+// isError returns true for the severities that are errors, which are Error
+// and Fatal.
+func (s Severity) isError() bool {
+	return s.Level() >= Error.Level()
+}
+
 type Description struct {
 	Address string
 	Summary string
 	Detail  string
+
+	// Code is a stable machine-readable identifier for the kind of problem,
+	// such as "TB1001", which downstream tooling can key suppressions and
+	// documentation on. It's empty for diagnostics without a code. Codes
+	// are usually registered using RegisterCode. Renderer shows the code
+	// after the severity, such as "Error [TB1001]".
+	Code string
+
+	// HelpURL is the address of documentation that explains how to resolve
+	// the problem, or empty if there is none. Renderers and error messages
+	// include it so that users can click through to it.
+	HelpURL string
+}
+
+// withHelpURL returns the given text, which describes a diagnostic in an
+// error message, followed by a reference to the diagnostic's HelpURL, if
+// it has one.
+func (d Description) withHelpURL(text string) string {
+	if d.HelpURL == "" {
+		return text
+	}
+	return text + " (see " + d.HelpURL + ")"
+}
+
+// DetailParts splits the detail into its first paragraph and the remainder,
+// so that interactive displays with limited space, such as hover tooltips,
+// can show the first part immediately and offer the rest on request.
+//
+// Paragraphs are separated by blank lines. If the detail has only one
+// paragraph then rest is empty.
+func (d Description) DetailParts() (first, rest string) {
+	detail := strings.TrimSpace(d.Detail)
+	lines := strings.Split(detail, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			first = strings.Join(lines[:i], "\n")
+			rest = strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+			return first, rest
+		}
+	}
+	return detail, ""
 }
 
 type Source struct {
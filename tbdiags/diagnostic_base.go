@@ -10,6 +10,8 @@ type diagnosticBase struct {
 	summary  string
 	detail   string
 	address  string
+	code     string
+	helpURL  string
 }
 
 func (d diagnosticBase) Severity() Severity {
@@ -21,9 +23,27 @@ func (d diagnosticBase) Description() Description {
 		Summary: d.summary,
 		Detail:  d.detail,
 		Address: d.address,
+		Code:    d.code,
+		HelpURL: d.helpURL,
 	}
 }
 
 func (d diagnosticBase) Source() Source {
 	return Source{}
 }
+
+// sourcedDiagnostic extends diagnosticBase with source location information,
+// for diagnostics that are constructed by this package from the parts of
+// other diagnostics.
+type sourcedDiagnostic struct {
+	diagnosticBase
+	subject *SourceRange
+	context *SourceRange
+}
+
+func (d sourcedDiagnostic) Source() Source {
+	return Source{
+		Subject: d.subject,
+		Context: d.context,
+	}
+}
@@ -0,0 +1,92 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestDescriptionDetailParts(t *testing.T) {
+	tests := map[string]struct {
+		Detail      string
+		First, Rest string
+	}{
+		"empty": {
+			"",
+			"", "",
+		},
+		"one paragraph": {
+			"The value is invalid.\nIt must be a number.",
+			"The value is invalid.\nIt must be a number.", "",
+		},
+		"several paragraphs": {
+			"The value is invalid.\n\nValid values are numbers.\n\nSee the docs.",
+			"The value is invalid.", "Valid values are numbers.\n\nSee the docs.",
+		},
+		"whitespace-only separator": {
+			"The value is invalid.\n  \nValid values are numbers.\n",
+			"The value is invalid.", "Valid values are numbers.",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			first, rest := Description{Detail: test.Detail}.DetailParts()
+			if first != test.First {
+				t.Errorf("wrong first part\ngot:  %q\nwant: %q", first, test.First)
+			}
+			if rest != test.Rest {
+				t.Errorf("wrong rest\ngot:  %q\nwant: %q", rest, test.Rest)
+			}
+		})
+	}
+}
+
+func TestSeverityOrder(t *testing.T) {
+	sevs := Severities()
+	for i, sev := range sevs {
+		if got := sev.Level(); got != i {
+			t.Errorf("wrong level for %s: got %d, want %d", sev, got, i)
+		}
+		for j, other := range sevs {
+			if got, want := sev.MoreSevereThan(other), i > j; got != want {
+				t.Errorf("wrong result for %s.MoreSevereThan(%s): got %t, want %t", sev, other, got, want)
+			}
+			if got, want := sev.AtLeast(other), i >= j; got != want {
+				t.Errorf("wrong result for %s.AtLeast(%s): got %t, want %t", sev, other, got, want)
+			}
+		}
+	}
+
+	diags := Diagnostics{Sourceless(Hint, "Hint", ""), Sourceless(Warning, "Warning", "")}
+	if !diags.HasSeverityAtLeast(Warning) {
+		t.Errorf("no diagnostic at least as severe as Warning")
+	}
+	if diags.HasSeverityAtLeast(Error) {
+		t.Errorf("unexpected diagnostic at least as severe as Error")
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for _, sev := range Severities() {
+		text, err := sev.MarshalText()
+		if err != nil {
+			t.Fatalf("unexpected error marshaling %s: %s", sev, err)
+		}
+		var got Severity
+		if err := got.UnmarshalText(text); err != nil {
+			t.Fatalf("unexpected error unmarshaling %q: %s", text, err)
+		}
+		if got != sev {
+			t.Errorf("wrong result for %q\ngot:  %s\nwant: %s", text, got, sev)
+		}
+	}
+
+	if got, err := ParseSeverity("WARNING"); err != nil || got != Warning {
+		t.Errorf("wrong result for \"WARNING\": %s, %v", got, err)
+	}
+	if _, err := ParseSeverity("severe"); err == nil {
+		t.Errorf("no error for invalid name")
+	}
+	if _, err := Severity('X').MarshalText(); err == nil {
+		t.Errorf("no error for invalid severity")
+	}
+}
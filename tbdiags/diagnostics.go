@@ -2,18 +2,61 @@ package tbdiags
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"sort"
 	"strings"
-
-	"github.com/hashicorp/errwrap"
-	"github.com/hashicorp/go-multierror"
 )
 
 type Diagnostics []Diagnostic
 
+// These limits control how much of a multi-item diagnostics list is included
+// in the Error() string of an error returned by Err, ErrWithWarnings or
+// NonFatalErr, so that a huge diagnostics list can't produce an enormous log
+// line. They are package-level settings because such errors are often
+// rendered far from where they were created, so applications should set them
+// once during startup.
+var (
+	// MaxErrorItems is the maximum number of diagnostics to list. Any
+	// further diagnostics are counted in a final "…and N more" line instead.
+	//
+	// Zero, the default, means that there is no limit.
+	MaxErrorItems int
+
+	// MaxErrorBytes is the maximum length of the Error() string, not counting
+	// the final "…and N more" line. The first diagnostic that would cause the
+	// string to exceed this length, and all diagnostics after it, are counted
+	// in that final line instead of being listed. The message of a single
+	// diagnostic is instead truncated at a word boundary to fit.
+	//
+	// Zero, the default, means that there is no limit.
+	MaxErrorBytes int
+)
+
+// Append returns the result of appending the given items to the receiver,
+// in the same way as the built-in append function, after converting them to
+// diagnostics. Each item may be a Diagnostic, a Diagnostics, or an error,
+// and any nil items are ignored. Append panics if given any other type,
+// unless lenient mode is enabled by SetLenientAppend.
+//
+// Errors are unwrapped using the standard library's conventions, so that
+// an error wrapping a NonFatalError or an error returned by Err produces the
+// original diagnostics, and an error wrapping several errors, such as one
+// returned by errors.Join, produces a diagnostic for each of them. Unless
+// the package is built with the tbdiags_stdliberrors build tag, errors from
+// the hashicorp/go-multierror and hashicorp/errwrap packages are also
+// unwrapped, as they were before the standard library supported wrapping.
+//
+// Each new diagnostic is passed through any normalizers registered with
+// RegisterNormalizer before it is appended.
 func (diags Diagnostics) Append(new ...interface{}) Diagnostics {
+	return diags.appendValues(new, 0)
+}
+
+// appendValues is the implementation of Append. Values of unsupported types
+// are handled by appendUnknownValue, with the given severity.
+func (diags Diagnostics) appendValues(new []interface{}, unknown Severity) Diagnostics {
 	for _, item := range new {
 		if item == nil {
 			continue
@@ -21,29 +64,49 @@ func (diags Diagnostics) Append(new ...interface{}) Diagnostics {
 
 		switch ti := item.(type) {
 		case Diagnostic:
-			diags = append(diags, ti)
+			diags = append(diags, normalize(ti))
 		case Diagnostics:
-			diags = append(diags, ti...) // flatten
+			// flatten
+			for _, diag := range ti {
+				diags = append(diags, normalize(diag))
+			}
 		case diagnosticsAsError:
 			diags = diags.Append(ti.Diagnostics) // unwrap
 		case NonFatalError:
 			diags = diags.Append(ti.Diagnostics) // unwrap
-		case *multierror.Error:
-			for _, err := range ti.Errors {
-				diags = append(diags, nativeError{err})
-			}
+		case *NonFatalError:
+			diags = diags.Append(ti.Diagnostics) // unwrap
 		case error:
+			var nfe NonFatalError
+			var dae diagnosticsAsError
+			if legacy, ok := appendLegacyError(diags, ti); ok {
+				diags = legacy
+				continue
+			}
 			switch {
-			case errwrap.ContainsType(ti, Diagnostics(nil)):
-				// If we have an errwrap wrapper with a Diagnostics hiding
-				// inside then we'll unpick it here to get access to the
-				// individual diagnostics.
-				diags = diags.Append(errwrap.GetType(ti, Diagnostics(nil)))
+			case errors.As(ti, &nfe):
+				// A NonFatalError wrapped by some other error, such as
+				// by fmt.Errorf with the %w verb, must not become an
+				// error diagnostic, or the non-fatal signal would be lost.
+				diags = diags.Append(nfe.Diagnostics)
+			case errors.As(ti, &dae):
+				// Likewise, a wrapped error returned by Err should
+				// produce its original diagnostics.
+				diags = diags.Append(dae.Diagnostics)
 			default:
-				diags = append(diags, nativeError{ti})
+				if multi, ok := ti.(interface{ Unwrap() []error }); ok {
+					// An error that wraps several others, such as one
+					// created by errors.Join, produces a diagnostic for
+					// each of them.
+					for _, err := range multi.Unwrap() {
+						diags = diags.Append(err)
+					}
+					break
+				}
+				diags = append(diags, normalize(nativeError{ti}))
 			}
 		default:
-			panic(fmt.Errorf("can't construct diagnostic(s) from %T", item))
+			diags = append(diags, normalize(appendUnknownValue(item, unknown)))
 		}
 	}
 
@@ -56,11 +119,95 @@ func (diags Diagnostics) Append(new ...interface{}) Diagnostics {
 	return diags
 }
 
+// unknownValue returns a diagnostic with the given severity describing a
+// value of a type that can't be converted to diagnostics.
+func unknownValue(item interface{}, severity Severity) Diagnostic {
+	return diagnosticBase{
+		severity: severity,
+		summary:  "Unexpected value reported as a diagnostic",
+		detail:   fmt.Sprintf("A value of type %T can't be converted to diagnostics, which is a bug in the program that reported it. The value was: %#v", item, item),
+	}
+}
+
 // HasErrors returns true if any of the diagnostics in the list have
-// a severity of Error.
+// a severity of Error or Fatal.
 func (diags Diagnostics) HasErrors() bool {
 	for _, diag := range diags {
-		if diag.Severity() == Error {
+		if diag.Severity().isError() {
+			return true
+		}
+	}
+	return false
+}
+
+// HasSeverityAtLeast returns true if any of the diagnostics in the list are at
+// least as severe as the given severity, for policies such as "fail if
+// anything is a warning or worse".
+func (diags Diagnostics) HasSeverityAtLeast(sev Severity) bool {
+	for _, diag := range diags {
+		if diag.Severity().AtLeast(sev) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterBySeverity returns the subset of the receiver that is at least as
+// severe as the given severity, preserving their order. For example,
+// FilterBySeverity(Error) returns only the errors, including fatal errors.
+func (diags Diagnostics) FilterBySeverity(min Severity) Diagnostics {
+	var ret Diagnostics
+	for _, diag := range diags {
+		if diag.Severity().AtLeast(min) {
+			ret = append(ret, diag)
+		}
+	}
+	return ret
+}
+
+// ExcludeSeverity returns the subset of the receiver whose severity is none
+// of the given severities, preserving their order.
+func (diags Diagnostics) ExcludeSeverity(severities ...Severity) Diagnostics {
+	var ret Diagnostics
+Diags:
+	for _, diag := range diags {
+		for _, sev := range severities {
+			if diag.Severity() == sev {
+				continue Diags
+			}
+		}
+		ret = append(ret, diag)
+	}
+	return ret
+}
+
+// HasWarnings returns true if any of the diagnostics in the list have
+// a severity of Warning.
+func (diags Diagnostics) HasWarnings() bool {
+	for _, diag := range diags {
+		if diag.Severity() == Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// HasHints returns true if any of the diagnostics in the list have
+// a severity of Hint.
+func (diags Diagnostics) HasHints() bool {
+	for _, diag := range diags {
+		if diag.Severity() == Hint {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFatal returns true if any of the diagnostics in the list have
+// a severity of Fatal, meaning that processing must stop immediately.
+func (diags Diagnostics) HasFatal() bool {
+	for _, diag := range diags {
+		if diag.Severity() == Fatal {
 			return true
 		}
 	}
@@ -76,6 +223,11 @@ func (diags Diagnostics) HasErrors() bool {
 // mechanism through which to report these.
 //
 //     return result, diags.Error()
+//
+// If the list includes any diagnostics with a severity of Fatal then
+// IsFatal returns true for the result, even if it's wrapped by some other
+// error, so that callers can distinguish errors that must stop processing
+// immediately from errors that can be reported at the end.
 func (diags Diagnostics) Err() error {
 	if !diags.HasErrors() {
 		return nil
@@ -83,6 +235,37 @@ func (diags Diagnostics) Err() error {
 	return diagnosticsAsError{diags}
 }
 
+// ErrWith is similar to Err except that it first passes any warnings in the
+// receiver to the given handler, and then returns an error describing only
+// the error diagnostics, or nil if there are none.
+//
+// This is for callers that need to return a native error but that also have
+// some other way to report warnings, such as logging them, so that the
+// warnings are not silently lost when there are no errors:
+//
+//	return result, diags.ErrWith(func(warnings tbdiags.Diagnostics) {
+//		log.Printf("[WARN] %s", warnings.NonFatalErr())
+//	})
+//
+// The handler is not called at all if there are no warnings. Warnings that
+// have been marked as acknowledged using Acknowledge are not passed to the
+// handler, since they have already been reported elsewhere.
+func (diags Diagnostics) ErrWith(handler func(warnings Diagnostics)) error {
+	var errs, warnings Diagnostics
+	for _, diag := range diags {
+		switch {
+		case diag.Severity().isError():
+			errs = append(errs, diag)
+		case !IsAcknowledged(diag):
+			warnings = append(warnings, diag)
+		}
+	}
+	if len(warnings) > 0 {
+		handler(warnings)
+	}
+	return errs.Err()
+}
+
 // ErrWithWarnings is similar to Err except that it will also return a non-nil
 // error if the receiver contains only warnings.
 //
@@ -90,10 +273,14 @@ func (diags Diagnostics) Err() error {
 // type NonFatalError, allowing diagnostics-aware callers to type-assert
 // and unwrap it, treating it as non-fatal.
 //
+// Warnings that have been marked as acknowledged using Acknowledge are
+// excluded from the result, since they have already been reported elsewhere.
+//
 // This should be used only in contexts where the caller is able to recognize
 // and handle NonFatalError. For normal callers that expect a lack of errors
 // to be signaled by nil, use just Diagnostics.Err.
 func (diags Diagnostics) ErrWithWarnings() error {
+	diags = diags.withoutAcknowledged()
 	if len(diags) == 0 {
 		return nil
 	}
@@ -109,10 +296,13 @@ func (diags Diagnostics) ErrWithWarnings() error {
 // This allows diagnostics to be returned over an error return channel while
 // being explicit that the diagnostics should not halt processing.
 //
+// As with ErrWithWarnings, acknowledged warnings are excluded from the result.
+//
 // This should be used only in contexts where the caller is able to recognize
 // and handle NonFatalError. For normal callers that expect a lack of errors
 // to be signaled by nil, use just Diagnostics.Err.
 func (diags Diagnostics) NonFatalErr() error {
+	diags = diags.withoutAcknowledged()
 	if len(diags) == 0 {
 		return nil
 	}
@@ -121,9 +311,13 @@ func (diags Diagnostics) NonFatalErr() error {
 
 // Sort applies an ordering to the diagnostics in the receiver in-place.
 //
-// The ordering is: warnings before errors, sourceless before sourced,
-// short source paths before long source paths, and then ordering by
-// position within each file.
+// The ordering is: hints before warnings before errors, sourceless before
+// sourced, files before other kinds of subject, short source paths before
+// long source paths, and then ordering by position within each file.
+// Ranges with PrecisionFile come before all other ranges in the same file,
+// and ranges with PrecisionLine are ordered by line only, before any other
+// ranges starting on the same line. Ranges with PrecisionNone are treated
+// as if there were no range.
 //
 // Diagnostics that do not differ by any of these sortable characteristics
 // will remain in the same relative order after this method returns.
@@ -131,6 +325,18 @@ func (diags Diagnostics) Sort() {
 	sort.Stable(sortDiagnostics(diags))
 }
 
+// SortByAddress is like Sort except that diagnostics of the same severity
+// that are either both sourceless or both in the same file are ordered by
+// their Description.Address before considering their positions, so that
+// diagnostics about the same logical object are grouped together.
+//
+// Addresses are compared using a "natural" ordering where sequences of
+// digits are compared numerically, so that "resource[2]" sorts before
+// "resource[10]".
+func (diags Diagnostics) SortByAddress() {
+	sort.Stable(sortDiagnosticsByAddress{sortDiagnostics(diags)})
+}
+
 type diagnosticsAsError struct {
 	Diagnostics
 }
@@ -144,22 +350,16 @@ func (dae diagnosticsAsError) Error() string {
 		return "no errors"
 	case len(diags) == 1:
 		desc := diags[0].Description()
-		if desc.Detail == "" {
-			return desc.Summary
+		msg := desc.Summary
+		if desc.Detail != "" {
+			msg = fmt.Sprintf("%s: %s", desc.Summary, desc.Detail)
 		}
-		return fmt.Sprintf("%s: %s", desc.Summary, desc.Detail)
-	default:
-		var ret bytes.Buffer
-		fmt.Fprintf(&ret, "%d problems:\n", len(diags))
-		for _, diag := range dae.Diagnostics {
-			desc := diag.Description()
-			if desc.Detail == "" {
-				fmt.Fprintf(&ret, "\n- %s", desc.Summary)
-			} else {
-				fmt.Fprintf(&ret, "\n- %s: %s", desc.Summary, desc.Detail)
-			}
+		if MaxErrorBytes > 0 {
+			msg = truncateAtWord(msg, MaxErrorBytes-len(desc.withHelpURL("")))
 		}
-		return ret.String()
+		return desc.withHelpURL(msg)
+	default:
+		return formatProblems(diags, NounProblem)
 	}
 }
 
@@ -168,22 +368,61 @@ func (dae diagnosticsAsError) Error() string {
 func (dae diagnosticsAsError) WrappedErrors() []error {
 	var errs []error
 	for _, diag := range dae.Diagnostics {
-		if wrapper, isErr := diag.(nativeError); isErr {
-			errs = append(errs, wrapper.err)
+		if err := CauseOf(diag); err != nil {
+			errs = append(errs, err)
 		}
 	}
 	return errs
 }
 
+// Unwrap returns the errors that the diagnostics were created from, so that
+// errors.Is and errors.As can find the causes of an error returned by Err
+// in Go 1.20 and later.
+func (dae diagnosticsAsError) Unwrap() []error {
+	return dae.WrappedErrors()
+}
+
 // NonFatalError is a special error type, returned by
 // Diagnostics.ErrWithWarnings and Diagnostics.NonFatalErr,
 // that indicates that the wrapped diagnostics should be treated as non-fatal.
-// Callers can conditionally type-assert an error to this type in order to
-// detect the non-fatal scenario and handle it in a different way.
+// Callers can use IsNonFatal, or errors.As with a target of either
+// *NonFatalError or **NonFatalError, in order to detect the non-fatal
+// scenario and handle it in a different way even if some intermediate layer
+// has wrapped the error using fmt.Errorf with the %w verb.
 type NonFatalError struct {
 	Diagnostics
 }
 
+// IsFatal returns true if the given error is, or wraps, an error returned by
+// Diagnostics.Err for diagnostics that include any with a severity of
+// Fatal.
+func IsFatal(err error) bool {
+	var dae diagnosticsAsError
+	return errors.As(err, &dae) && dae.Diagnostics.HasFatal()
+}
+
+// IsNonFatal returns true if the given error is, or wraps, a NonFatalError.
+func IsNonFatal(err error) bool {
+	var nfe NonFatalError
+	return errors.As(err, &nfe)
+}
+
+// As implements the interface used by errors.As so that a NonFatalError can
+// be extracted into either a NonFatalError or a *NonFatalError target,
+// regardless of whether the error chain contains a value or a pointer.
+func (woe NonFatalError) As(target interface{}) bool {
+	switch target := target.(type) {
+	case *NonFatalError:
+		*target = woe
+		return true
+	case **NonFatalError:
+		*target = &woe
+		return true
+	default:
+		return false
+	}
+}
+
 func (woe NonFatalError) Error() string {
 	diags := woe.Diagnostics
 	switch {
@@ -193,27 +432,91 @@ func (woe NonFatalError) Error() string {
 		return "no errors or warnings"
 	case len(diags) == 1:
 		desc := diags[0].Description()
-		if desc.Detail == "" {
-			return desc.Summary
+		msg := desc.Summary
+		if desc.Detail != "" {
+			msg = fmt.Sprintf("%s: %s", desc.Summary, desc.Detail)
+		}
+		if MaxErrorBytes > 0 {
+			msg = truncateAtWord(msg, MaxErrorBytes-len(desc.withHelpURL("")))
 		}
-		return fmt.Sprintf("%s: %s", desc.Summary, desc.Detail)
+		return desc.withHelpURL(msg)
 	default:
-		var ret bytes.Buffer
-		if diags.HasErrors() {
-			fmt.Fprintf(&ret, "%d problems:\n", len(diags))
+		switch {
+		case diags.HasErrors(), diags.HasWarnings() && diags.HasHints():
+			return formatProblems(diags, NounProblem)
+		case diags.HasHints():
+			return formatProblems(diags, NounHint)
+		default:
+			return formatProblems(diags, NounWarning)
+		}
+	}
+}
+
+// formatProblems renders a multi-item diagnostics list as a bulleted list
+// under a header counting the items using the given noun.
+//
+// If the list contains a mix of severities then the header also includes
+// a breakdown by severity and each bullet is prefixed with the severity of
+// its diagnostic, so that readers can tell which of the problems are fatal.
+func formatProblems(diags Diagnostics, noun CountNoun) string {
+	bySeverity := make(map[Severity]int)
+	for _, diag := range diags {
+		bySeverity[diag.Severity()]++
+	}
+	counts := severityCounts(bySeverity)
+	mixed := len(counts) > 1
+
+	var ret bytes.Buffer
+	if mixed {
+		fmt.Fprintf(&ret, "%s (%s):\n", FormatCount(len(diags), noun), strings.Join(counts, ", "))
+	} else {
+		fmt.Fprintf(&ret, "%s:\n", FormatCount(len(diags), noun))
+	}
+	var item bytes.Buffer
+	for i, diag := range diags {
+		if MaxErrorItems > 0 && i >= MaxErrorItems {
+			fmt.Fprintf(&ret, "\n\n…and %s", FormatCount(len(diags)-i, NounMore))
+			break
+		}
+
+		item.Reset()
+		desc := diag.Description()
+		item.WriteString("\n- ")
+		if mixed {
+			fmt.Fprintf(&item, "%s: ", diag.Severity())
+		}
+		if desc.Detail == "" {
+			item.WriteString(desc.withHelpURL(desc.Summary))
 		} else {
-			fmt.Fprintf(&ret, "%d warnings:\n", len(diags))
+			item.WriteString(desc.withHelpURL(fmt.Sprintf("%s: %s", desc.Summary, desc.Detail)))
 		}
-		for _, diag := range woe.Diagnostics {
-			desc := diag.Description()
-			if desc.Detail == "" {
-				fmt.Fprintf(&ret, "\n- %s", desc.Summary)
-			} else {
-				fmt.Fprintf(&ret, "\n- %s: %s", desc.Summary, desc.Detail)
-			}
+
+		if MaxErrorBytes > 0 && ret.Len()+item.Len() > MaxErrorBytes {
+			fmt.Fprintf(&ret, "\n\n…and %s", FormatCount(len(diags)-i, NounMore))
+			break
 		}
-		return ret.String()
+		ret.Write(item.Bytes())
 	}
+	return ret.String()
+}
+
+// severityCounts describes the nonzero counts in the given map, such as
+// "2 errors", from the most to the least severe.
+func severityCounts(counts map[Severity]int) []string {
+	var ret []string
+	if n := counts[Fatal]; n > 0 {
+		ret = append(ret, FormatCount(n, NounFatalError))
+	}
+	if n := counts[Error]; n > 0 {
+		ret = append(ret, FormatCount(n, NounError))
+	}
+	if n := counts[Warning]; n > 0 {
+		ret = append(ret, FormatCount(n, NounWarning))
+	}
+	if n := counts[Hint]; n > 0 {
+		ret = append(ret, FormatCount(n, NounHint))
+	}
+	return ret
 }
 
 // sortDiagnostics is an implementation of sort.Interface
@@ -226,31 +529,68 @@ func (sd sortDiagnostics) Len() int {
 }
 
 func (sd sortDiagnostics) Less(i, j int) bool {
-	iD, jD := sd[i], sd[j]
+	return lessDiagnostic(sd[i], sd[j], false)
+}
+
+// lessDiagnostic implements the ordering of both Sort and SortByAddress,
+// which considers the addresses of diagnostics in the same file only if
+// byAddress is set.
+func lessDiagnostic(iD, jD Diagnostic, byAddress bool) bool {
 	iSev, jSev := iD.Severity(), jD.Severity()
-	iSrc, jSrc := iD.Source(), jD.Source()
+	iSubj, jSubj := subjectOf(iD), subjectOf(jD)
 
 	switch {
 
 	case iSev != jSev:
-		return iSev == Warning
+		return iSev.Level() < jSev.Level()
+
+	case (iSubj == nil) != (jSubj == nil):
+		return iSubj == nil
 
-	case (iSrc.Subject == nil) != (jSrc.Subject == nil):
-		return iSrc.Subject == nil
+	case iSubj != nil && iSubj.Kind != jSubj.Kind:
+		// Files go first, followed by other kinds of subject
+		return iSubj.Kind < jSubj.Kind
 
-	case iSrc.Subject != nil && *iSrc.Subject != *jSrc.Subject:
-		iSubj := iSrc.Subject
-		jSubj := jSrc.Subject
+	case iSubj != nil && iSubj.Filename != jSubj.Filename:
+		// Path with fewer segments goes first if they are different lengths
+		sep := string(filepath.Separator)
+		iCount := strings.Count(iSubj.Filename, sep)
+		jCount := strings.Count(jSubj.Filename, sep)
+		if iCount != jCount {
+			return iCount < jCount
+		}
+		return iSubj.Filename < jSubj.Filename
+
+	case byAddress && iD.Description().Address != jD.Description().Address:
+		// Within a file, or among sourceless diagnostics, addresses are
+		// considered before positions.
+		return naturalLess(iD.Description().Address, jD.Description().Address)
+
+	case iSubj != nil && *iSubj != *jSubj:
 		switch {
-		case iSubj.Filename != jSubj.Filename:
-			// Path with fewer segments goes first if they are different lengths
-			sep := string(filepath.Separator)
-			iCount := strings.Count(iSubj.Filename, sep)
-			jCount := strings.Count(jSubj.Filename, sep)
-			if iCount != jCount {
-				return iCount < jCount
+		case iSubj.Precision != PrecisionExact || jSubj.Precision != PrecisionExact:
+			// Byte offsets are not meaningful for less precise ranges,
+			// so we compare by line and then put the less precise ranges
+			// first. Whole-file ranges go before all others.
+			iLine, jLine := iSubj.Start.Line, jSubj.Start.Line
+			if iSubj.Precision == PrecisionFile {
+				iLine = 0
+			}
+			if jSubj.Precision == PrecisionFile {
+				jLine = 0
 			}
-			return iSubj.Filename < jSubj.Filename
+			if iLine != jLine {
+				return iLine < jLine
+			}
+			return iSubj.Precision > jSubj.Precision
+		case iSubj.Start.Byte == 0 && jSubj.Start.Byte == 0 && iSubj.Start != jSubj.Start:
+			// Producers that know only lines and columns, such as
+			// LineParser, leave the byte offsets zero, so we compare by
+			// line and column instead.
+			if iSubj.Start.Line != jSubj.Start.Line {
+				return iSubj.Start.Line < jSubj.Start.Line
+			}
+			return iSubj.Start.Column < jSubj.Start.Column
 		case iSubj.Start.Byte != jSubj.Start.Byte:
 			return iSubj.Start.Byte < jSubj.Start.Byte
 		case iSubj.End.Byte != jSubj.End.Byte:
@@ -270,3 +610,62 @@ func (sd sortDiagnostics) Less(i, j int) bool {
 func (sd sortDiagnostics) Swap(i, j int) {
 	sd[i], sd[j] = sd[j], sd[i]
 }
+
+// sortDiagnosticsByAddress is an implementation of sort.Interface that
+// extends sortDiagnostics to also consider diagnostic addresses.
+type sortDiagnosticsByAddress struct {
+	sortDiagnostics
+}
+
+func (sd sortDiagnosticsByAddress) Less(i, j int) bool {
+	return lessDiagnostic(sd.sortDiagnostics[i], sd.sortDiagnostics[j], true)
+}
+
+// naturalLess compares two strings in the same way as the < operator except
+// that runs of decimal digits are compared by their numeric value. Strings
+// that are equal by that comparison, such as "a[02]" and "a[2]", are
+// ordered shortest first and then by the < operator, so that the result is
+// a strict ordering.
+func naturalLess(a, b string) bool {
+	origA, origB := a, b
+	for a != "" && b != "" {
+		aDigits, bDigits := isDigit(a[0]), isDigit(b[0])
+		switch {
+		case aDigits && bDigits:
+			var aNum, bNum string
+			aNum, a = splitDigits(a)
+			bNum, b = splitDigits(b)
+			aNum = strings.TrimLeft(aNum, "0")
+			bNum = strings.TrimLeft(bNum, "0")
+			if len(aNum) != len(bNum) {
+				return len(aNum) < len(bNum)
+			}
+			if aNum != bNum {
+				return aNum < bNum
+			}
+		case a[0] != b[0]:
+			return a[0] < b[0]
+		default:
+			a, b = a[1:], b[1:]
+		}
+	}
+	if a != "" || b != "" {
+		return len(a) < len(b)
+	}
+	if len(origA) != len(origB) {
+		return len(origA) < len(origB)
+	}
+	return origA < origB
+}
+
+func splitDigits(s string) (digits, rest string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
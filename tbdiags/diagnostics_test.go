@@ -0,0 +1,505 @@
+package tbdiags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsErr(t *testing.T) {
+	tests := map[string]struct {
+		Diags Diagnostics
+		Want  string
+	}{
+		"single error": {
+			Diagnostics{
+				Sourceless(Error, "Bad thing", "It went wrong."),
+			},
+			"Bad thing: It went wrong.",
+		},
+		"only errors": {
+			Diagnostics{
+				Sourceless(Error, "Bad thing", "It went wrong."),
+				Sourceless(Error, "Worse thing", ""),
+			},
+			`2 problems:
+
+- Bad thing: It went wrong.
+- Worse thing`,
+		},
+		"errors and warnings": {
+			Diagnostics{
+				Sourceless(Warning, "Dubious thing", ""),
+				Sourceless(Error, "Bad thing", "It went wrong."),
+				Sourceless(Error, "Worse thing", ""),
+			},
+			`3 problems (2 errors, 1 warning):
+
+- Warning: Dubious thing
+- Error: Bad thing: It went wrong.
+- Error: Worse thing`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := test.Diags.Err().Error()
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:\n%s\n\nwant:\n%s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsNonFatalErr(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Warning, "Dubious thing", ""),
+		Sourceless(Warning, "Suspicious thing", "Look closer."),
+	}
+	got := diags.NonFatalErr().Error()
+	want := `2 warnings:
+
+- Dubious thing
+- Suspicious thing: Look closer.`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiagnosticsErrLimits(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Error, "First", ""),
+		Sourceless(Error, "Second", ""),
+		Sourceless(Error, "Third", ""),
+		Sourceless(Error, "Fourth", ""),
+	}
+
+	t.Run("items", func(t *testing.T) {
+		defer func(old int) { MaxErrorItems = old }(MaxErrorItems)
+		MaxErrorItems = 2
+
+		got := diags.Err().Error()
+		want := `4 problems:
+
+- First
+- Second
+
+…and 2 more`
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\n\nwant:\n%s", got, want)
+		}
+	})
+	t.Run("bytes", func(t *testing.T) {
+		defer func(old int) { MaxErrorBytes = old }(MaxErrorBytes)
+		MaxErrorBytes = len("4 problems:\n\n- First\n- Second")
+
+		got := diags.Err().Error()
+		want := `4 problems:
+
+- First
+- Second
+
+…and 2 more`
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\n\nwant:\n%s", got, want)
+		}
+	})
+	t.Run("bytes for a single diagnostic", func(t *testing.T) {
+		defer func(old int) { MaxErrorBytes = old }(MaxErrorBytes)
+		MaxErrorBytes = 40
+
+		diag := Sourceless(Error, "Bad config", strings.Repeat("This part of the detail is long. ", 100))
+		got := Diagnostics{diag}.Err().Error()
+		if want := "Bad config: This part of the detail…"; got != want {
+			t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+		}
+
+		got = Diagnostics{WithHelpURL(diag, "https://example.com/x")}.Err().Error()
+		if want := "Bad… (see https://example.com/x)"; got != want {
+			t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+		}
+	})
+}
+
+func TestDiagnosticsSortByAddress(t *testing.T) {
+	rng := func(filename string, start int) *SourceRange {
+		return &SourceRange{
+			Filename: filename,
+			Start:    SourcePos{Byte: start},
+			End:      SourcePos{Byte: start + 1},
+		}
+	}
+	diags := Diagnostics{
+		addressedDiag{"resource[10]", rng("main.tb", 0)},
+		addressedDiag{"resource[2]", rng("main.tb", 20)},
+		addressedDiag{"other", rng("a/other.tb", 0)},
+		addressedDiag{"resource[2]", rng("main.tb", 10)},
+		addressedDiag{"b", nil},
+		addressedDiag{"a", nil},
+	}
+	diags.SortByAddress()
+
+	var got []string
+	for _, diag := range diags {
+		desc := diag.Description()
+		got = append(got, desc.Address)
+		if subj := diag.Source().Subject; subj != nil {
+			got[len(got)-1] += fmt.Sprintf("@%d", subj.Start.Byte)
+		}
+	}
+	want := []string{
+		"a",
+		"b",
+		"resource[2]@10",
+		"resource[2]@20",
+		"resource[10]@0",
+		"other@0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDiagnosticsSortByAddressConsistent(t *testing.T) {
+	rng := func(filename string, line int) *SourceRange {
+		return &SourceRange{
+			Filename:  filename,
+			Start:     SourcePos{Line: line},
+			End:       SourcePos{Line: line},
+			Precision: PrecisionLine,
+		}
+	}
+	a := addressedDiag{"a", rng("x.tb", 10)}
+	b := addressedDiag{"b", rng("x.tb", 1)}
+	c := addressedDiag{"c", rng("y.tb", 5)}
+	d := addressedDiag{"a[2]", nil}
+	e := addressedDiag{"a[02]", nil}
+
+	// Every input order must produce the same result, which can only
+	// happen if the ordering is consistent between all pairs.
+	for _, diags := range []Diagnostics{
+		{a, b, c, d, e},
+		{c, b, a, e, d},
+		{b, c, e, a, d},
+		{e, a, d, c, b},
+	} {
+		diags.SortByAddress()
+		var got []string
+		for _, diag := range diags {
+			got = append(got, diag.Description().Address)
+		}
+		if want := []string{"a[2]", "a[02]", "a", "b", "c"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+		}
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		A, B string
+		Want bool
+	}{
+		{"a", "b", true},
+		{"b", "a", false},
+		{"a", "a", false},
+		{"a", "ab", true},
+		{"a[2]", "a[10]", true},
+		{"a[10]", "a[2]", false},
+		{"a[02]", "a[2]", false},
+		{"a[2]", "a[02]", true},
+		{"a[02]", "a[01]", false},
+		{"a[2].b", "a[2].c", true},
+		{"a2", "ab", true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s<%s", test.A, test.B), func(t *testing.T) {
+			if got := naturalLess(test.A, test.B); got != test.Want {
+				t.Errorf("wrong result %t; want %t", got, test.Want)
+			}
+		})
+	}
+}
+
+type addressedDiag struct {
+	address string
+	subject *SourceRange
+}
+
+func (d addressedDiag) Severity() Severity {
+	return Error
+}
+
+func (d addressedDiag) Description() Description {
+	return Description{
+		Address: d.address,
+		Summary: "Problem with " + d.address,
+	}
+}
+
+func (d addressedDiag) Source() Source {
+	return Source{Subject: d.subject}
+}
+
+func TestDiagnosticsErrWith(t *testing.T) {
+	t.Run("errors and warnings", func(t *testing.T) {
+		diags := Diagnostics{
+			Sourceless(Warning, "Dubious thing", ""),
+			Sourceless(Error, "Bad thing", ""),
+		}
+		var warnings Diagnostics
+		err := diags.ErrWith(func(ws Diagnostics) {
+			warnings = ws
+		})
+		if got, want := err.Error(), "Bad thing"; got != want {
+			t.Errorf("wrong error %q; want %q", got, want)
+		}
+		if len(warnings) != 1 || warnings[0].Description().Summary != "Dubious thing" {
+			t.Errorf("wrong warnings %#v", warnings)
+		}
+	})
+	t.Run("only warnings", func(t *testing.T) {
+		diags := Diagnostics{
+			Sourceless(Warning, "Dubious thing", ""),
+		}
+		called := false
+		err := diags.ErrWith(func(ws Diagnostics) {
+			called = true
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+		if !called {
+			t.Errorf("handler was not called")
+		}
+	})
+	t.Run("only errors", func(t *testing.T) {
+		diags := Diagnostics{
+			Sourceless(Error, "Bad thing", ""),
+		}
+		err := diags.ErrWith(func(ws Diagnostics) {
+			t.Errorf("handler was called")
+		})
+		if err == nil {
+			t.Errorf("no error")
+		}
+	})
+}
+
+func TestIsNonFatal(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Warning, "Dubious thing", ""),
+	}
+	nfe := diags.NonFatalErr()
+	wrapped := fmt.Errorf("loading configuration: %w", nfe)
+
+	if !IsNonFatal(nfe) {
+		t.Errorf("NonFatalError is not non-fatal")
+	}
+	if !IsNonFatal(wrapped) {
+		t.Errorf("wrapped NonFatalError is not non-fatal")
+	}
+	if !IsNonFatal(fmt.Errorf("wrapped pointer: %w", &NonFatalError{diags})) {
+		t.Errorf("wrapped *NonFatalError is not non-fatal")
+	}
+	if IsNonFatal(fmt.Errorf("plain")) {
+		t.Errorf("plain error is non-fatal")
+	}
+
+	var target *NonFatalError
+	if !errors.As(wrapped, &target) {
+		t.Fatalf("errors.As failed to find *NonFatalError")
+	}
+	if len(target.Diagnostics) != 1 {
+		t.Errorf("wrong diagnostics %#v", target.Diagnostics)
+	}
+
+	var appended Diagnostics
+	appended = appended.Append(wrapped)
+	if appended.HasErrors() {
+		t.Errorf("appending a wrapped NonFatalError produced errors")
+	}
+}
+
+func TestDiagnosticsSortLineOnly(t *testing.T) {
+	exact := func(line, start int) *SourceRange {
+		return &SourceRange{
+			Filename: "main.tb",
+			Start:    SourcePos{Line: line, Column: 1, Byte: start},
+			End:      SourcePos{Line: line, Column: 2, Byte: start + 1},
+		}
+	}
+	lineOnly := func(line int) *SourceRange {
+		rng := LineRange("main.tb", line)
+		return &rng
+	}
+	diags := Diagnostics{
+		addressedDiag{"exact 2", exact(2, 10)},
+		addressedDiag{"line 2", lineOnly(2)},
+		addressedDiag{"line 1", lineOnly(1)},
+		addressedDiag{"exact 1", exact(1, 0)},
+	}
+	diags.Sort()
+
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Description().Address)
+	}
+	want := []string{"line 1", "exact 1", "line 2", "exact 2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDiagnosticsAppendWrappedErrors(t *testing.T) {
+	var inner Diagnostics
+	inner = inner.Append(Sourceless(Error, "Bad thing", ""))
+	wrapped := fmt.Errorf("loading: %w", inner.Err())
+	multi := multiError{errors.New("first"), errors.New("second")}
+
+	var diags Diagnostics
+	diags = diags.Append(wrapped, multi)
+
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Description().Summary)
+	}
+	want := []string{"Bad thing", "first", "second"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong summaries\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+// multiError is an error that wraps several others in the same way as
+// errors.Join, which is not available in all supported Go versions.
+type multiError []error
+
+func (errs multiError) Error() string {
+	return fmt.Sprintf("%d errors", len(errs))
+}
+
+func (errs multiError) Unwrap() []error {
+	return errs
+}
+
+func TestDiagnosticsSortPrecision(t *testing.T) {
+	rng := func(r SourceRange) *SourceRange {
+		return &r
+	}
+	none := FileRange("main.tb")
+	none.Precision = PrecisionNone
+	diags := Diagnostics{
+		addressedDiag{"line 2", rng(LineRange("main.tb", 2))},
+		addressedDiag{"file", rng(FileRange("main.tb"))},
+		addressedDiag{"line 1", rng(LineRange("main.tb", 1))},
+		addressedDiag{"none", &none},
+	}
+	diags.Sort()
+
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Description().Address)
+	}
+	want := []string{"none", "file", "line 1", "line 2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestDiagnosticsHints(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Error, "Bad thing", ""),
+		Sourceless(Warning, "Dubious thing", ""),
+		Sourceless(Hint, "Consider another thing", ""),
+	}
+	if !diags.HasHints() || !diags.HasWarnings() {
+		t.Errorf("HasHints or HasWarnings returned false")
+	}
+	if (Diagnostics{diags[0]}).HasHints() {
+		t.Errorf("HasHints returned true without hints")
+	}
+
+	sorted := append(Diagnostics(nil), diags...)
+	sorted.Sort()
+	var got []Severity
+	for _, diag := range sorted {
+		got = append(got, diag.Severity())
+	}
+	if want := []Severity{Hint, Warning, Error}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong order\ngot:  %#v\nwant: %#v", got, want)
+	}
+
+	if got, want := diags.Err().Error(), `3 problems (1 error, 1 warning, 1 hint):
+
+- Error: Bad thing
+- Warning: Dubious thing
+- Hint: Consider another thing`; got != want {
+		t.Errorf("wrong error\ngot:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiagnosticsFatal(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Error, "Bad thing", ""),
+	}
+	if diags.HasFatal() {
+		t.Errorf("HasFatal returned true without fatal diagnostics")
+	}
+	if IsFatal(diags.Err()) {
+		t.Errorf("IsFatal returned true without fatal diagnostics")
+	}
+
+	diags = diags.Append(Sourceless(Fatal, "Disk full", ""))
+	if !diags.HasFatal() || !diags.HasErrors() {
+		t.Errorf("HasFatal or HasErrors returned false")
+	}
+	err := fmt.Errorf("validating: %w", diags.Err())
+	if !IsFatal(err) {
+		t.Errorf("IsFatal returned false for wrapped fatal error")
+	}
+	if IsFatal(fmt.Errorf("plain")) {
+		t.Errorf("IsFatal returned true for plain error")
+	}
+	if got, want := diags.Err().Error(), `2 problems (1 fatal error, 1 error):
+
+- Error: Bad thing
+- Fatal: Disk full`; got != want {
+		t.Errorf("wrong error\ngot:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiagnosticsFilterBySeverity(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Hint, "A", ""),
+		Sourceless(Error, "B", ""),
+		Sourceless(Warning, "C", ""),
+		Sourceless(Fatal, "D", ""),
+	}
+	summaries := func(diags Diagnostics) string {
+		var ret []string
+		for _, diag := range diags {
+			ret = append(ret, diag.Description().Summary)
+		}
+		return strings.Join(ret, ",")
+	}
+
+	tests := map[string]struct {
+		Got  Diagnostics
+		Want string
+	}{
+		"at least hint":    {diags.FilterBySeverity(Hint), "A,B,C,D"},
+		"at least warning": {diags.FilterBySeverity(Warning), "B,C,D"},
+		"at least error":   {diags.FilterBySeverity(Error), "B,D"},
+		"exclude errors":   {diags.ExcludeSeverity(Error, Fatal), "A,C"},
+		"exclude nothing":  {diags.ExcludeSeverity(), "A,B,C,D"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := summaries(test.Got); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
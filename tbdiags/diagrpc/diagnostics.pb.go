@@ -0,0 +1,191 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v3.21.12
+// source: diagnostics.proto
+
+package diagrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Batch struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      uint64                 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Diagnostics   []byte                 `protobuf:"bytes,2,opt,name=diagnostics,proto3" json:"diagnostics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Batch) Reset() {
+	*x = Batch{}
+	mi := &file_diagnostics_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Batch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Batch) ProtoMessage() {}
+
+func (x *Batch) ProtoReflect() protoreflect.Message {
+	mi := &file_diagnostics_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Batch.ProtoReflect.Descriptor instead.
+func (*Batch) Descriptor() ([]byte, []int) {
+	return file_diagnostics_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Batch) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Batch) GetDiagnostics() []byte {
+	if x != nil {
+		return x.Diagnostics
+	}
+	return nil
+}
+
+type Ack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sequence      uint64                 `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ack) Reset() {
+	*x = Ack{}
+	mi := &file_diagnostics_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ack) ProtoMessage() {}
+
+func (x *Ack) ProtoReflect() protoreflect.Message {
+	mi := &file_diagnostics_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ack.ProtoReflect.Descriptor instead.
+func (*Ack) Descriptor() ([]byte, []int) {
+	return file_diagnostics_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Ack) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *Ack) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_diagnostics_proto protoreflect.FileDescriptor
+
+const file_diagnostics_proto_rawDesc = "" +
+	"\n" +
+	"\x11diagnostics.proto\x12\x12tbdiags.diagrpc.v1\"E\n" +
+	"\x05Batch\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12 \n" +
+	"\vdiagnostics\x18\x02 \x01(\fR\vdiagnostics\"7\n" +
+	"\x03Ack\x12\x1a\n" +
+	"\bsequence\x18\x01 \x01(\x04R\bsequence\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error2O\n" +
+	"\vDiagnostics\x12@\n" +
+	"\x06Report\x12\x19.tbdiags.diagrpc.v1.Batch\x1a\x17.tbdiags.diagrpc.v1.Ack(\x010\x01B.Z,github.com/jimmyflamingo/pkg/tbdiags/diagrpcb\x06proto3"
+
+var (
+	file_diagnostics_proto_rawDescOnce sync.Once
+	file_diagnostics_proto_rawDescData []byte
+)
+
+func file_diagnostics_proto_rawDescGZIP() []byte {
+	file_diagnostics_proto_rawDescOnce.Do(func() {
+		file_diagnostics_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_diagnostics_proto_rawDesc), len(file_diagnostics_proto_rawDesc)))
+	})
+	return file_diagnostics_proto_rawDescData
+}
+
+var file_diagnostics_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_diagnostics_proto_goTypes = []any{
+	(*Batch)(nil), // 0: tbdiags.diagrpc.v1.Batch
+	(*Ack)(nil),   // 1: tbdiags.diagrpc.v1.Ack
+}
+var file_diagnostics_proto_depIdxs = []int32{
+	0, // 0: tbdiags.diagrpc.v1.Diagnostics.Report:input_type -> tbdiags.diagrpc.v1.Batch
+	1, // 1: tbdiags.diagrpc.v1.Diagnostics.Report:output_type -> tbdiags.diagrpc.v1.Ack
+	1, // [1:2] is the sub-list for method output_type
+	0, // [0:1] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_diagnostics_proto_init() }
+func file_diagnostics_proto_init() {
+	if File_diagnostics_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_diagnostics_proto_rawDesc), len(file_diagnostics_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_diagnostics_proto_goTypes,
+		DependencyIndexes: file_diagnostics_proto_depIdxs,
+		MessageInfos:      file_diagnostics_proto_msgTypes,
+	}.Build()
+	File_diagnostics_proto = out.File
+	file_diagnostics_proto_goTypes = nil
+	file_diagnostics_proto_depIdxs = nil
+}
@@ -0,0 +1,191 @@
+package diagrpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// serve starts a server that reports to the given sink, returning a
+// connection to it.
+func serve(t *testing.T, sink tbdiags.Sink) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	RegisterDiagnosticsServer(srv, NewServer(sink))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestTransport(t *testing.T) {
+	collector := &tbdiags.Collector{}
+	transport := NewTransport(serve(t, collector))
+	defer transport.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	first := tbdiags.Diagnostics{
+		tbdiags.Sourceless(tbdiags.Error, "Build failed", "The compiler crashed."),
+		tbdiags.WithCode(tbdiags.SimpleWarning("Slow build"), "TB2001"),
+	}
+	if err := transport.Send(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	second := tbdiags.Diagnostics{tbdiags.Sourceless(tbdiags.Hint, "Consider caching", "")}
+	if err := transport.Send(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	got := (&tbdiags.Renderer{}).RenderString(collector.Diagnostics())
+	want := (&tbdiags.Renderer{}).RenderString(append(first, second...))
+	if got != want {
+		t.Errorf("wrong diagnostics\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTransportConcurrent(t *testing.T) {
+	collector := &tbdiags.Collector{}
+	transport := NewTransport(serve(t, collector))
+	defer transport.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	errs := make(chan error)
+	for i := 0; i < 10; i++ {
+		go func() {
+			errs <- transport.Send(ctx, tbdiags.Diagnostics{tbdiags.SimpleWarning("Slow build")})
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if got := len(collector.Diagnostics()); got != 10 {
+		t.Errorf("coordinator received %d diagnostics; want 10", got)
+	}
+}
+
+// blockingSink is a sink that doesn't return from Report until unblocked.
+type blockingSink struct {
+	tbdiags.Collector
+	unblock chan struct{}
+}
+
+func (s *blockingSink) Report(diags tbdiags.Diagnostics) {
+	<-s.unblock
+	s.Collector.Report(diags)
+}
+
+func TestTransportBackpressure(t *testing.T) {
+	sink := &blockingSink{unblock: make(chan struct{})}
+	transport := NewTransport(serve(t, sink))
+	defer transport.Close()
+
+	// The batch isn't acknowledged while the sink is blocked.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	diags := tbdiags.Diagnostics{tbdiags.SimpleWarning("Slow build")}
+	if err := transport.Send(ctx, diags); err != context.DeadlineExceeded {
+		t.Fatalf("wrong error %v; want %v", err, context.DeadlineExceeded)
+	}
+
+	// Once the sink catches up, the stream continues.
+	close(sink.unblock)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := transport.Send(ctx, diags); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(sink.Diagnostics()); got != 2 {
+		t.Errorf("coordinator received %d diagnostics; want 2", got)
+	}
+}
+
+func TestServerRejects(t *testing.T) {
+	collector := &tbdiags.Collector{}
+	client := NewDiagnosticsClient(serve(t, collector))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Report(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&Batch{Sequence: 7, Diagnostics: []byte("not JSON")}); err != nil {
+		t.Fatal(err)
+	}
+	ack, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ack.Sequence != 7 || ack.Error == "" {
+		t.Errorf("wrong acknowledgment %v; want a rejection of batch 7", ack)
+	}
+
+	// The stream continues after a rejected batch.
+	if err := stream.Send(&Batch{Sequence: 8, Diagnostics: []byte(`[{"severity":"warning","summary":"Slow build"}]`)}); err != nil {
+		t.Fatal(err)
+	}
+	if ack, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if ack.Sequence != 8 || ack.Error != "" {
+		t.Errorf("wrong acknowledgment %v; want acceptance of batch 8", ack)
+	}
+	if got := len(collector.Diagnostics()); got != 1 {
+		t.Errorf("coordinator received %d diagnostics; want 1", got)
+	}
+}
+
+func TestTransportClose(t *testing.T) {
+	transport := NewTransport(serve(t, &tbdiags.Collector{}))
+	transport.Close()
+
+	err := transport.Send(context.Background(), tbdiags.Diagnostics{tbdiags.SimpleWarning("Slow build")})
+	if err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Errorf("wrong error %v after Close", err)
+	}
+}
+
+func TestRemoteSink(t *testing.T) {
+	collector := &tbdiags.Collector{}
+	transport := NewTransport(serve(t, collector))
+	defer transport.Close()
+
+	sink := tbdiags.NewRemoteSink(transport, tbdiags.RemoteSinkOptions{Component: "worker"})
+	sink.Report(tbdiags.Diagnostics{tbdiags.SimpleWarning("Slow build")})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := sink.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got := collector.Diagnostics()
+	if len(got) != 1 {
+		t.Fatalf("coordinator received %d diagnostics; want 1", len(got))
+	}
+	if hops := tbdiags.ProvenanceOf(got[0]); len(hops) != 1 || hops[0].Component != "worker" {
+		t.Errorf("wrong provenance %v", hops)
+	}
+}
@@ -0,0 +1,28 @@
+// Package diagrpc is a gRPC service for streaming diagnostics from worker
+// processes to a coordinator, with acknowledgments and backpressure.
+//
+// Workers send diagnostics with a Transport, usually on behalf of a
+// tbdiags.RemoteSink, which batches and retries them. The coordinator
+// registers a Server, which decodes each batch and reports it to a
+// tbdiags.Sink such as a tbdiags.Collector:
+//
+//	// Coordinator
+//	srv := grpc.NewServer()
+//	diagrpc.RegisterDiagnosticsServer(srv, diagrpc.NewServer(collector))
+//
+//	// Worker
+//	transport := diagrpc.NewTransport(conn)
+//	sink := tbdiags.NewRemoteSink(transport, tbdiags.RemoteSinkOptions{})
+//
+// Batches are encoded in the JSON format of tbdiags.Diagnostics.MarshalJSON,
+// so the coordinator receives the same diagnostics that the worker reported,
+// although without their causes.
+//
+// This package is a separate module from tbdiags so that programs which
+// don't use it don't depend on gRPC, and so that tbdiags itself continues to
+// support older versions of Go than gRPC does. The bindings in the .pb.go
+// files are generated from diagnostics.proto by go generate, which requires
+// protoc, protoc-gen-go and protoc-gen-go-grpc.
+package diagrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative diagnostics.proto
@@ -0,0 +1,53 @@
+package diagrpc
+
+import (
+	"io"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+)
+
+// Server implements the Diagnostics service by reporting the diagnostics it
+// receives to a sink.
+//
+// Each batch is acknowledged only after the sink's Report method returns,
+// and a stream's batches are handled one at a time, so a slow sink slows
+// down the workers that send to it rather than causing diagnostics to be
+// buffered without limit. A batch that can't be decoded is rejected, with
+// an Ack describing why, and the stream continues.
+type Server struct {
+	UnimplementedDiagnosticsServer
+
+	sink tbdiags.Sink
+}
+
+var _ DiagnosticsServer = (*Server)(nil)
+
+// NewServer returns a Server that reports diagnostics to the given sink,
+// which must be safe to call concurrently when there are multiple workers.
+func NewServer(sink tbdiags.Sink) *Server {
+	return &Server{sink: sink}
+}
+
+// Report implements DiagnosticsServer.
+func (s *Server) Report(stream Diagnostics_ReportServer) error {
+	for {
+		batch, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		ack := &Ack{Sequence: batch.Sequence}
+		var diags tbdiags.Diagnostics
+		if err := diags.UnmarshalJSON(batch.Diagnostics); err != nil {
+			ack.Error = err.Error()
+		} else if len(diags) > 0 {
+			s.sink.Report(diags)
+		}
+		if err := stream.Send(ack); err != nil {
+			return err
+		}
+	}
+}
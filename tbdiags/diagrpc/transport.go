@@ -0,0 +1,219 @@
+package diagrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/jimmyflamingo/pkg/tbdiags"
+	"google.golang.org/grpc"
+)
+
+// errClosed is returned by Transport.Send after Transport.Close.
+var errClosed = errors.New("diagrpc: transport is closed")
+
+// Transport is a tbdiags.Transport that sends each batch of diagnostics on a
+// long-lived stream of the Diagnostics service, and waits for the
+// coordinator to acknowledge it.
+//
+// The stream is opened by the first call to Send and is reused by later
+// calls, which may be concurrent. If the stream fails, any batches awaiting
+// acknowledgment fail with it, and the next call to Send opens a new stream.
+// A batch for which Send returns an error may still have been reported by
+// the coordinator, so a tbdiags.RemoteSink retrying it can cause the
+// coordinator to receive the same diagnostics more than once.
+type Transport struct {
+	client DiagnosticsClient
+	opts   []grpc.CallOption
+
+	mu      sync.Mutex
+	current *stream
+	seq     uint64
+	closed  bool
+}
+
+var _ tbdiags.Transport = (*Transport)(nil)
+
+// NewTransport returns a Transport that sends diagnostics over the given
+// connection, using the given options for each stream it opens.
+func NewTransport(conn grpc.ClientConnInterface, opts ...grpc.CallOption) *Transport {
+	return &Transport{
+		client: NewDiagnosticsClient(conn),
+		opts:   opts,
+	}
+}
+
+// Send implements tbdiags.Transport. It returns when the coordinator has
+// acknowledged the batch, or when the given context is cancelled.
+//
+// Sending blocks while the coordinator is behind, due to the flow control
+// of the stream, so the given context is also what bounds how long a worker
+// will wait for a slow coordinator.
+func (t *Transport) Send(ctx context.Context, diags tbdiags.Diagnostics) error {
+	data, err := diags.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	s, seq, err := t.next()
+	if err != nil {
+		return err
+	}
+	done := s.await(seq)
+	if err := s.send(&Batch{Sequence: seq, Diagnostics: data}); err != nil {
+		t.fail(s, err)
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		s.forget(seq)
+		return ctx.Err()
+	}
+}
+
+// Close closes the current stream, if any, causing any calls to Send that
+// are awaiting acknowledgment to fail, and any later calls to Send to fail
+// immediately. It doesn't close the underlying connection.
+func (t *Transport) Close() error {
+	t.mu.Lock()
+	s := t.current
+	t.current = nil
+	t.closed = true
+	t.mu.Unlock()
+
+	if s != nil {
+		s.fail(errClosed)
+	}
+	return nil
+}
+
+// next returns the current stream, opening a new one if necessary, along
+// with the sequence for the next batch to be sent.
+func (t *Transport) next() (*stream, uint64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, 0, errClosed
+	}
+	if t.current == nil {
+		// The stream outlives any one call to Send, so it isn't bound to
+		// the context of the call that opens it.
+		ctx, cancel := context.WithCancel(context.Background())
+		client, err := t.client.Report(ctx, t.opts...)
+		if err != nil {
+			cancel()
+			return nil, 0, err
+		}
+		t.current = &stream{
+			client:  client,
+			cancel:  cancel,
+			pending: make(map[uint64]chan error),
+		}
+		go t.receive(t.current)
+	}
+	t.seq++
+	return t.current, t.seq, nil
+}
+
+// receive delivers the acknowledgments received on the given stream until
+// it fails.
+func (t *Transport) receive(s *stream) {
+	for {
+		ack, err := s.client.Recv()
+		if err != nil {
+			t.fail(s, err)
+			return
+		}
+		if ack.Error != "" {
+			s.resolve(ack.Sequence, fmt.Errorf("coordinator rejected diagnostics: %s", ack.Error))
+		} else {
+			s.resolve(ack.Sequence, nil)
+		}
+	}
+}
+
+// fail fails the given stream with the given error, so that the next call
+// to Send opens a new one.
+func (t *Transport) fail(s *stream, err error) {
+	t.mu.Lock()
+	if t.current == s {
+		t.current = nil
+	}
+	t.mu.Unlock()
+	s.fail(err)
+}
+
+// stream is a stream opened by a Transport, along with the batches sent on
+// it that are awaiting acknowledgment.
+type stream struct {
+	client Diagnostics_ReportClient
+	cancel context.CancelFunc
+
+	// sendMu serializes calls to client.Send, which isn't safe to call
+	// concurrently. It's separate from mu so that acknowledgments can be
+	// delivered while a call to Send is blocked by flow control.
+	sendMu sync.Mutex
+
+	mu      sync.Mutex
+	pending map[uint64]chan error
+	err     error
+}
+
+// await registers a batch with the given sequence as awaiting
+// acknowledgment, returning a channel that receives the outcome.
+func (s *stream) await(seq uint64) <-chan error {
+	done := make(chan error, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		done <- s.err
+		return done
+	}
+	s.pending[seq] = done
+	return done
+}
+
+func (s *stream) send(batch *Batch) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.client.Send(batch)
+}
+
+// resolve delivers the outcome of the batch with the given sequence, if
+// it's still awaiting acknowledgment.
+func (s *stream) resolve(seq uint64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if done, ok := s.pending[seq]; ok {
+		delete(s.pending, seq)
+		done <- err
+	}
+}
+
+// forget stops waiting for acknowledgment of the batch with the given
+// sequence.
+func (s *stream) forget(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, seq)
+}
+
+// fail cancels the stream, and fails all of the batches awaiting
+// acknowledgment with the given error. Only the first call has any effect.
+func (s *stream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	s.err = err
+	s.cancel()
+	for seq, done := range s.pending {
+		delete(s.pending, seq)
+		done <- err
+	}
+}
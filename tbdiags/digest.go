@@ -0,0 +1,222 @@
+package tbdiags
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"sort"
+)
+
+// DigestOptions configures NewDigest.
+type DigestOptions struct {
+	// Title describes the period or run that the digest covers, such as
+	// "Nightly build, 1 March".
+	Title string
+
+	// Owner returns the team or person responsible for the given
+	// diagnostic, such as by matching its subject's filename against a
+	// CODEOWNERS file. Diagnostics for which it returns an empty string,
+	// or all diagnostics if it's nil, are listed as unowned.
+	Owner func(Diagnostic) string
+
+	// Previous is the diagnostics of the previous period, such as those
+	// loaded from a store of earlier runs. Diagnostics whose Fingerprint
+	// doesn't match any of them are new.
+	Previous Diagnostics
+
+	// TopNew is the maximum number of new problems to list for each owner.
+	// Defaults to five.
+	TopNew int
+
+	// Permalinks, if set, is used to link each of the new problems to its
+	// source lines on the code host.
+	Permalinks *Permalinker
+}
+
+// Digest is a summary of a set of diagnostics, grouped by owner, for teams
+// that triage diagnostics asynchronously rather than reading every build
+// log. Use WriteText or WriteHTML to produce a report, such as for the body
+// of an email.
+type Digest struct {
+	Title string
+
+	// Owners are the sections of the digest, sorted by owner with any
+	// unowned diagnostics last.
+	Owners []DigestOwner
+
+	// Permalinks, if set, is used to link each of the new problems to its
+	// source lines on the code host.
+	Permalinks *Permalinker
+}
+
+// DigestOwner is the section of a Digest for a single owner.
+type DigestOwner struct {
+	// Owner is the owner's name, or empty for unowned diagnostics.
+	Owner string
+
+	// Counts and NewCounts are the number of diagnostics of each
+	// severity, and of those the number that are new.
+	Counts, NewCounts map[Severity]int
+
+	// TopNew is the most severe of the new problems, each listed once
+	// regardless of how many times it was reported, in order of severity
+	// and then of first report.
+	TopNew Diagnostics
+}
+
+// NewDigest summarizes the given diagnostics as configured by the given
+// options.
+func NewDigest(diags Diagnostics, opts DigestOptions) Digest {
+	if opts.TopNew <= 0 {
+		opts.TopNew = 5
+	}
+	previous := make(map[string]bool, len(opts.Previous))
+	for _, diag := range opts.Previous {
+		previous[Fingerprint(diag)] = true
+	}
+
+	sections := make(map[string]*DigestOwner)
+	seen := make(map[string]bool)
+	for _, diag := range diags {
+		var owner string
+		if opts.Owner != nil {
+			owner = opts.Owner(diag)
+		}
+		section, ok := sections[owner]
+		if !ok {
+			section = &DigestOwner{
+				Owner:     owner,
+				Counts:    make(map[Severity]int),
+				NewCounts: make(map[Severity]int),
+			}
+			sections[owner] = section
+		}
+		section.Counts[diag.Severity()]++
+
+		fingerprint := Fingerprint(diag)
+		if previous[fingerprint] {
+			continue
+		}
+		section.NewCounts[diag.Severity()]++
+		if !seen[fingerprint] {
+			seen[fingerprint] = true
+			section.TopNew = append(section.TopNew, diag)
+		}
+	}
+
+	ret := Digest{Title: opts.Title, Permalinks: opts.Permalinks}
+	for _, section := range sections {
+		sort.SliceStable(section.TopNew, func(i, j int) bool {
+			return section.TopNew[i].Severity().MoreSevereThan(section.TopNew[j].Severity())
+		})
+		if len(section.TopNew) > opts.TopNew {
+			section.TopNew = section.TopNew[:opts.TopNew]
+		}
+		ret.Owners = append(ret.Owners, *section)
+	}
+	sort.Slice(ret.Owners, func(i, j int) bool {
+		a, b := ret.Owners[i].Owner, ret.Owners[j].Owner
+		if (a == "") != (b == "") {
+			return b == ""
+		}
+		return a < b
+	})
+	return ret
+}
+
+// name returns the heading for the section.
+func (o DigestOwner) name() string {
+	if o.Owner == "" {
+		return "Unowned"
+	}
+	return o.Owner
+}
+
+// summary describes the counts of the section, such as "1 error and 2
+// warnings, 1 new".
+func (o DigestOwner) summary() string {
+	ret := joinCounts(o.Counts)
+	var newTotal int
+	for _, n := range o.NewCounts {
+		newTotal += n
+	}
+	if newTotal > 0 {
+		ret += ", " + FormatCount(newTotal, NounNew)
+	}
+	return ret
+}
+
+// WriteText writes the digest as plain text.
+func (d Digest) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if d.Title != "" {
+		fmt.Fprintf(bw, "%s\n\n", d.Title)
+	}
+	if len(d.Owners) == 0 {
+		bw.WriteString("No problems were reported.\n")
+	}
+	for i, section := range d.Owners {
+		if i > 0 {
+			bw.WriteByte('\n')
+		}
+		fmt.Fprintf(bw, "== %s ==\n%s\n", section.name(), section.summary())
+		if len(section.TopNew) > 0 {
+			bw.WriteString("\nNew problems:\n")
+		}
+		for _, diag := range section.TopNew {
+			fmt.Fprintf(bw, "  - %s\n", digestItem(diag))
+			if link, ok := d.link(diag); ok {
+				fmt.Fprintf(bw, "    %s\n", link)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteHTML writes the digest as a fragment of HTML, suitable for the body
+// of an email.
+func (d Digest) WriteHTML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if d.Title != "" {
+		fmt.Fprintf(bw, "<h1>%s</h1>\n", html.EscapeString(d.Title))
+	}
+	if len(d.Owners) == 0 {
+		bw.WriteString("<p>No problems were reported.</p>\n")
+	}
+	for _, section := range d.Owners {
+		fmt.Fprintf(bw, "<h2>%s</h2>\n<p>%s</p>\n", html.EscapeString(section.name()), html.EscapeString(section.summary()))
+		if len(section.TopNew) == 0 {
+			continue
+		}
+		bw.WriteString("<p>New problems:</p>\n<ul>\n")
+		for _, diag := range section.TopNew {
+			item := html.EscapeString(digestItem(diag))
+			if link, ok := d.link(diag); ok {
+				item = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(link), item)
+			}
+			fmt.Fprintf(bw, "<li>%s</li>\n", item)
+		}
+		bw.WriteString("</ul>\n")
+	}
+	return bw.Flush()
+}
+
+// link returns the permalink for the given diagnostic, if the digest has
+// a Permalinker and the diagnostic can be linked to.
+func (d Digest) link(diag Diagnostic) (string, bool) {
+	if d.Permalinks == nil {
+		return "", false
+	}
+	return d.Permalinks.Link(diag)
+}
+
+// digestItem describes a single diagnostic in a digest, such as "Error:
+// Missing name (main.tb:3,1)".
+func digestItem(diag Diagnostic) string {
+	ret := diag.Severity().String() + ": " + diag.Description().Summary
+	if subject := subjectOf(diag); subject != nil {
+		ret += " (" + subject.StartString() + ")"
+	}
+	return ret
+}
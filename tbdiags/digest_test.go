@@ -0,0 +1,126 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigest(t *testing.T) {
+	inFile := func(severity Severity, filename, summary string) Diagnostic {
+		rng := LineRange(filename, 1)
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: severity, summary: summary},
+			subject:        &rng,
+		}
+	}
+	previous := Diagnostics{
+		inFile(Warning, "api/server.tb", "Deprecated argument"),
+	}
+	diags := Diagnostics{
+		inFile(Warning, "api/server.tb", "Deprecated argument"),
+		inFile(Warning, "api/client.tb", "Unused variable"),
+		inFile(Error, "api/client.tb", "Missing name"),
+		inFile(Error, "api/client.tb", "Missing name"),
+		inFile(Error, "web/app.tb", "Bad <tag>"),
+		Sourceless(Hint, "Consider upgrading", ""),
+	}
+	digest := NewDigest(diags, DigestOptions{
+		Title: "Nightly build",
+		Owner: func(diag Diagnostic) string {
+			if subject := subjectOf(diag); subject != nil {
+				return "team-" + strings.SplitN(subject.Filename, "/", 2)[0]
+			}
+			return ""
+		},
+		Previous: previous,
+	})
+
+	var text strings.Builder
+	if err := digest.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	want := `Nightly build
+
+== team-api ==
+2 errors and 2 warnings, 3 new
+
+New problems:
+  - Error: Missing name (api/client.tb:1)
+  - Warning: Unused variable (api/client.tb:1)
+
+== team-web ==
+1 error, 1 new
+
+New problems:
+  - Error: Bad <tag> (web/app.tb:1)
+
+== Unowned ==
+1 hint, 1 new
+
+New problems:
+  - Hint: Consider upgrading
+`
+	if got := text.String(); got != want {
+		t.Errorf("wrong text\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	var html strings.Builder
+	if err := digest.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if want := "<li>Error: Bad &lt;tag&gt; (web/app.tb:1)</li>"; !strings.Contains(html.String(), want) {
+		t.Errorf("HTML doesn't contain %q:\n%s", want, html.String())
+	}
+
+	text.Reset()
+	if err := NewDigest(nil, DigestOptions{}).WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := text.String(), "No problems were reported.\n"; got != want {
+		t.Errorf("wrong text for no diagnostics\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestDigestPermalinks(t *testing.T) {
+	rng := LineRange("web/app.tb", 4)
+	diags := Diagnostics{
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Error, summary: "Bad <tag>"},
+			subject:        &rng,
+		},
+		Sourceless(Hint, "Consider upgrading", ""),
+	}
+	links, err := NewPermalinker(PermalinkOptions{
+		Template: GitHubPermalink,
+		Repo:     "https://example.com/org/repo",
+		Commit:   "abc123",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := NewDigest(diags, DigestOptions{Permalinks: links})
+
+	var text strings.Builder
+	if err := digest.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	want := `== Unowned ==
+1 error and 1 hint, 2 new
+
+New problems:
+  - Error: Bad <tag> (web/app.tb:4)
+    https://example.com/org/repo/blob/abc123/web/app.tb#L4
+  - Hint: Consider upgrading
+`
+	if got := text.String(); got != want {
+		t.Errorf("wrong text\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	var html strings.Builder
+	if err := digest.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	if want := `<li><a href="https://example.com/org/repo/blob/abc123/web/app.tb#L4">Error: Bad &lt;tag&gt; (web/app.tb:4)</a></li>`; !strings.Contains(html.String(), want) {
+		t.Errorf("HTML doesn't contain %q:\n%s", want, html.String())
+	}
+}
@@ -0,0 +1,49 @@
+package tbdiags
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Duplicates returns an error diagnostic for each name in the given map
+// that has more than one defining range, for validators that require the
+// names of some kind of object, such as "variable", to be unique.
+//
+// The subject of each diagnostic is the first of the name's ranges, and the
+// others are its related information, in order, so that every validator
+// reports duplicates in the same way. The diagnostics are in order of name,
+// so that the result doesn't depend on the order of the map.
+func Duplicates(kind string, definitions map[string][]SourceRange) Diagnostics {
+	names := make([]string, 0, len(definitions))
+	for name, ranges := range definitions {
+		if len(ranges) > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diags Diagnostics
+	for _, name := range names {
+		ranges := definitions[name]
+		subject := ranges[0]
+		related := make([]RelatedInfo, len(ranges)-1)
+		for i, rng := range ranges[1:] {
+			related[i] = RelatedInfo{
+				Message: "also defined here",
+				Range:   rng,
+			}
+		}
+		diags = diags.Append(WithRelated(sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  fmt.Sprintf("Duplicate %s %q", kind, name),
+				detail: fmt.Sprintf(
+					"The %s %q is defined %d times, first at %s. Each %s must have a unique name.",
+					kind, name, len(ranges), subject.StartString(), kind,
+				),
+			},
+			subject: &subject,
+		}, related...))
+	}
+	return diags
+}
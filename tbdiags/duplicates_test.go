@@ -0,0 +1,40 @@
+package tbdiags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuplicates(t *testing.T) {
+	a := LineRange("a.tb", 1)
+	b := LineRange("b.tb", 4)
+	c := LineRange("c.tb", 9)
+	diags := Duplicates("variable", map[string][]SourceRange{
+		"unique": {a},
+		"region": {b, a, c},
+		"name":   {c, b},
+	})
+
+	var summaries []string
+	for _, diag := range diags {
+		summaries = append(summaries, diag.Description().Summary)
+	}
+	if want := []string{`Duplicate variable "name"`, `Duplicate variable "region"`}; !reflect.DeepEqual(summaries, want) {
+		t.Fatalf("wrong summaries\ngot:  %#v\nwant: %#v", summaries, want)
+	}
+
+	region := diags[1]
+	if got := subjectOf(region).StartString(); got != "b.tb:4" {
+		t.Errorf("wrong subject %s", got)
+	}
+	wantRelated := []RelatedInfo{
+		{Message: "also defined here", Range: a},
+		{Message: "also defined here", Range: c},
+	}
+	if got := RelatedOf(region); !reflect.DeepEqual(got, wantRelated) {
+		t.Errorf("wrong related information\ngot:  %#v\nwant: %#v", got, wantRelated)
+	}
+	if got, want := region.Description().Detail, `The variable "region" is defined 3 times, first at b.tb:4. Each variable must have a unique name.`; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package tbdiags
+
+// Emitter identifies the component of a program that produced a diagnostic,
+// so that diagnostics aggregated from several subsystems can be traced back
+// to the one responsible.
+type Emitter struct {
+	// Component is the name of the component, such as "validator".
+	Component string
+
+	// Version is the version of the component, if known.
+	Version string
+}
+
+// String returns the component name followed by the version, if any, such
+// as "validator 1.2.0".
+func (e Emitter) String() string {
+	if e.Version == "" {
+		return e.Component
+	}
+	return e.Component + " " + e.Version
+}
+
+// DiagnosticEmitter is an optional interface implemented by diagnostics that
+// record which component of a program produced them. Diagnostics.MarshalJSON
+// encodes the emitter as the "emitter" property, an object with "component"
+// and optional "version" properties.
+type DiagnosticEmitter interface {
+	Emitter() Emitter
+}
+
+// WithEmitter returns a diagnostic that is the same as the given diagnostic
+// except that it also implements DiagnosticEmitter, returning the given
+// emitter.
+func WithEmitter(diag Diagnostic, emitter Emitter) Diagnostic {
+	return withEmitter{diag, emitter}
+}
+
+// EmitterOf returns the emitter of the given diagnostic. The result is false
+// if the diagnostic doesn't implement DiagnosticEmitter.
+func EmitterOf(diag Diagnostic) (Emitter, bool) {
+	var ret Emitter
+	found := findDiagnostic(diag, func(diag Diagnostic) bool {
+		e, ok := diag.(DiagnosticEmitter)
+		if ok {
+			ret = e.Emitter()
+		}
+		return ok
+	})
+	return ret, found
+}
+
+// WithEmitter returns a copy of the receiver in which each diagnostic that
+// does not already have an emitter is attributed to the named component.
+// As with WithOrigin, diagnostics that already have one keep it, so that
+// diagnostics passed up through several components are attributed to the
+// one that produced them.
+func (diags Diagnostics) WithEmitter(component string) Diagnostics {
+	return diags.WithEmitterVersion(component, "")
+}
+
+// WithEmitterVersion is like WithEmitter except that it also records the
+// version of the component.
+func (diags Diagnostics) WithEmitterVersion(component, version string) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	emitter := Emitter{Component: component, Version: version}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		if _, ok := EmitterOf(diag); !ok {
+			diag = WithEmitter(diag, emitter)
+		}
+		ret[i] = diag
+	}
+	return ret
+}
+
+type withEmitter struct {
+	Diagnostic
+	emitter Emitter
+}
+
+func (d withEmitter) Emitter() Emitter {
+	return d.emitter
+}
+
+func (d withEmitter) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
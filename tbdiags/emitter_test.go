@@ -0,0 +1,40 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsEmitter(t *testing.T) {
+	var diags Diagnostics
+	diags = diags.Append(Diagnostics{
+		Sourceless(Error, "Missing name", ""),
+	}.WithEmitterVersion("validator", "1.2.0"))
+	diags = diags.Append(Diagnostics{
+		Sourceless(Warning, "Slow query", ""),
+		WithEmitter(Sourceless(Hint, "Index unused", ""), Emitter{Component: "planner"}),
+	}.WithEmitter("storage"))
+
+	want := []string{"validator 1.2.0", "storage", "planner"}
+	for i, diag := range diags {
+		got, ok := EmitterOf(diag)
+		if !ok || got.String() != want[i] {
+			t.Errorf("wrong emitter %q for diagnostic %d; want %q", got, i, want[i])
+		}
+	}
+	if _, ok := EmitterOf(Sourceless(Error, "Other", "")); ok {
+		t.Errorf("found an emitter on a diagnostic without one")
+	}
+
+	rendered := (&Renderer{}).RenderString(diags[:1])
+	if want := "Error: Missing name\n  (emitted by validator 1.2.0)\n\n"; rendered != want {
+		t.Errorf("wrong rendering\ngot:  %q\nwant: %q", rendered, want)
+	}
+	js, err := diags[1:2].MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(js), `"emitter":{"component":"storage"}`) {
+		t.Errorf("JSON has no emitter: %s", js)
+	}
+}
@@ -0,0 +1,105 @@
+package tbdiags
+
+import (
+	"reflect"
+)
+
+// EqualOption relaxes the comparison made by Diagnostics.Equal.
+type EqualOption int
+
+const (
+	// IgnoreRanges causes the subject and context ranges of diagnostics,
+	// and the ranges of their related information, to be ignored.
+	IgnoreRanges EqualOption = iota + 1
+
+	// IgnoreDetail causes the details of diagnostics to be ignored.
+	IgnoreDetail
+
+	// IgnoreOrder causes the diagnostics to be compared as unordered
+	// collections, in which each diagnostic must appear the same number of
+	// times in both.
+	IgnoreOrder
+)
+
+// Equal returns true if the receiver and the other diagnostics are the
+// same, as modified by the given options.
+//
+// Two diagnostics are the same if they would have the same JSON
+// representation, as produced by MarshalJSON, so that tests can compare
+// diagnostics directly rather than by encoding them. Without IgnoreOrder,
+// the diagnostics must also be in the same order.
+func (diags Diagnostics) Equal(other Diagnostics, opts ...EqualOption) bool {
+	if len(diags) != len(other) {
+		return false
+	}
+	var ignoreOrder bool
+	for _, opt := range opts {
+		if opt == IgnoreOrder {
+			ignoreOrder = true
+		}
+	}
+
+	if !ignoreOrder {
+		for i := range diags {
+			if !reflect.DeepEqual(comparableDiagnostic(diags[i], opts), comparableDiagnostic(other[i], opts)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Each diagnostic in the receiver is matched with the first unmatched
+	// equal diagnostic in other.
+	candidates := make([]*jsonDiagnostic, len(other))
+	for i, diag := range other {
+		c := comparableDiagnostic(diag, opts)
+		candidates[i] = &c
+	}
+	for _, diag := range diags {
+		want := comparableDiagnostic(diag, opts)
+		found := false
+		for i, c := range candidates {
+			if c != nil && reflect.DeepEqual(want, *c) {
+				candidates[i] = nil
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// comparableDiagnostic returns the representation of the given diagnostic
+// used by Diagnostics.Equal, without the parts ignored by the given
+// options.
+func comparableDiagnostic(diag Diagnostic, opts []EqualOption) jsonDiagnostic {
+	ret := newJSONDiagnostic(diag, PositionOptions{})
+	relaxJSONDiagnostic(&ret, opts)
+	return ret
+}
+
+// relaxJSONDiagnostic removes the parts ignored by the given options from
+// the given diagnostic and its children.
+func relaxJSONDiagnostic(diag *jsonDiagnostic, opts []EqualOption) {
+	// Where a diagnostic was created is only recorded in development mode,
+	// and is never part of what the diagnostic means.
+	diag.ReportedAt = ""
+	for _, opt := range opts {
+		switch opt {
+		case IgnoreRanges:
+			diag.Subject = nil
+			diag.Context = nil
+			for i := range diag.Related {
+				diag.Related[i].Range = nil
+			}
+		case IgnoreDetail:
+			diag.Detail = ""
+		}
+	}
+	for i := range diag.Children {
+		relaxJSONDiagnostic(&diag.Children[i], opts)
+	}
+}
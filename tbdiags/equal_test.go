@@ -0,0 +1,113 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestDiagnosticsEqual(t *testing.T) {
+	rng := func(line int) *SourceRange {
+		return &SourceRange{
+			Filename: "main.tb",
+			Start:    SourcePos{Line: line, Column: 1, Byte: 0},
+			End:      SourcePos{Line: line, Column: 5, Byte: 4},
+		}
+	}
+	bad := func(line int, detail string) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Error, summary: "Bad thing", detail: detail},
+			subject:        rng(line),
+		}
+	}
+	base := Diagnostics{
+		WithAttributes(bad(1, "Details."), map[string]interface{}{"n": 1}),
+		Sourceless(Warning, "Odd thing", ""),
+	}
+
+	tests := map[string]struct {
+		other Diagnostics
+		opts  []EqualOption
+		want  bool
+	}{
+		"identical": {
+			Diagnostics{
+				WithAttributes(bad(1, "Details."), map[string]interface{}{"n": 1}),
+				Sourceless(Warning, "Odd thing", ""),
+			},
+			nil,
+			true,
+		},
+		"different attributes": {
+			Diagnostics{
+				WithAttributes(bad(1, "Details."), map[string]interface{}{"n": 2}),
+				Sourceless(Warning, "Odd thing", ""),
+			},
+			nil,
+			false,
+		},
+		"different range": {
+			Diagnostics{
+				WithAttributes(bad(2, "Details."), map[string]interface{}{"n": 1}),
+				Sourceless(Warning, "Odd thing", ""),
+			},
+			nil,
+			false,
+		},
+		"different range ignored": {
+			Diagnostics{
+				WithAttributes(bad(2, "Details."), map[string]interface{}{"n": 1}),
+				Sourceless(Warning, "Odd thing", ""),
+			},
+			[]EqualOption{IgnoreRanges},
+			true,
+		},
+		"different detail ignored": {
+			Diagnostics{
+				WithAttributes(bad(1, "Other details."), map[string]interface{}{"n": 1}),
+				Sourceless(Warning, "Odd thing", "More."),
+			},
+			[]EqualOption{IgnoreDetail},
+			true,
+		},
+		"different order": {
+			Diagnostics{
+				Sourceless(Warning, "Odd thing", ""),
+				WithAttributes(bad(1, "Details."), map[string]interface{}{"n": 1}),
+			},
+			nil,
+			false,
+		},
+		"different order ignored": {
+			Diagnostics{
+				Sourceless(Warning, "Odd thing", ""),
+				WithAttributes(bad(1, "Details."), map[string]interface{}{"n": 1}),
+			},
+			[]EqualOption{IgnoreOrder},
+			true,
+		},
+		"different multiplicity ignoring order": {
+			Diagnostics{
+				Sourceless(Warning, "Odd thing", ""),
+				Sourceless(Warning, "Odd thing", ""),
+			},
+			[]EqualOption{IgnoreOrder, IgnoreRanges, IgnoreDetail},
+			false,
+		},
+		"fewer": {
+			base[:1],
+			nil,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := base.Equal(test.other, test.opts...); got != test.want {
+				t.Errorf("wrong result %t; want %t", got, test.want)
+			}
+		})
+	}
+
+	if !Diagnostics(nil).Equal(Diagnostics{}) {
+		t.Errorf("nil diagnostics are not equal to empty diagnostics")
+	}
+}
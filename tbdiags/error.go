@@ -12,8 +12,16 @@ func (e nativeError) Severity() Severity {
 }
 
 func (e nativeError) Description() Description {
+	summary, detail := SplitMessage(FormatError(e.err), MaxErrorSummaryLen)
+	if extra, ok := errorDetail(e.err); ok {
+		if detail != "" {
+			detail += "\n\n"
+		}
+		detail += extra
+	}
 	return Description{
-		Summary: FormatError(e.err),
+		Summary: summary,
+		Detail:  detail,
 	}
 }
 
@@ -21,3 +29,67 @@ func (e nativeError) Source() Source {
 	// No source information available for a native error
 	return Source{}
 }
+
+// Unwrap returns the error that the diagnostic was created from.
+func (e nativeError) Unwrap() error {
+	return e.err
+}
+
+// CauseOf returns the error that the given diagnostic was created from, such
+// as by Diagnostics.Append, or nil if it wasn't created from an error. The
+// result can be inspected using errors.Is and errors.As.
+func CauseOf(diag Diagnostic) error {
+	var ret error
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		u, ok := diag.(interface{ Unwrap() error })
+		if ok {
+			ret = u.Unwrap()
+		}
+		return ok
+	})
+	return ret
+}
+
+// Wrap returns an error diagnostic created from the given error, as for
+// Diagnostics.Append, except that it has the given summary and subject,
+// and the message of the error is its detail. This lets a producer present
+// an error from a lower layer, such as a failure to read a file, in terms
+// of what it was trying to do and where. The subject may be nil.
+//
+// As with other diagnostics created from errors, CauseOf returns the given
+// error. If the error is nil then Wrap returns nil, which Diagnostics.Append
+// ignores, so the result of a call that may fail can be wrapped without
+// checking it first.
+func Wrap(err error, summary string, subject *SourceRange) Diagnostic {
+	if err == nil {
+		return nil
+	}
+	return withCaller(wrappedError{
+		nativeError: nativeError{err},
+		summary:     summary,
+		subject:     copyRange(subject),
+	})
+}
+
+// wrappedError is a nativeError that has its own summary and subject, as
+// returned by Wrap.
+type wrappedError struct {
+	nativeError
+	summary string
+	subject *SourceRange
+}
+
+func (e wrappedError) Description() Description {
+	detail := FormatError(e.err)
+	if extra, ok := errorDetail(e.err); ok {
+		detail += "\n\n" + extra
+	}
+	return Description{
+		Summary: e.summary,
+		Detail:  detail,
+	}
+}
+
+func (e wrappedError) Source() Source {
+	return Source{Subject: e.subject}
+}
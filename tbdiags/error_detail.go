@@ -0,0 +1,69 @@
+package tbdiags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+var (
+	errorDetails   = make(map[reflect.Type]*template.Template)
+	errorDetailsMu sync.RWMutex
+)
+
+// RegisterErrorDetail registers a text/template that produces the detail of
+// diagnostics created from errors of the same type as the given example,
+// such as (*os.LinkError)(nil), so that the fields of common error types can
+// be presented readably without converting the errors at every call site of
+// Diagnostics.Append.
+//
+// The template is executed with the error as its data, so that, for
+// example, "Old path: {{.Old}}" refers to a field of *os.LinkError. The
+// error matches if it has the registered type or wraps an error that does,
+// as for errors.As. The result follows any detail that the diagnostic would
+// otherwise have, separated by a blank line. If the template fails, the
+// diagnostic has its usual detail.
+//
+// RegisterErrorDetail is intended to be called from the init functions of
+// main packages. It panics if the example is nil, if the template can't be
+// parsed, or if a template is already registered for the type.
+func RegisterErrorDetail(example error, text string) {
+	if example == nil {
+		panic("tbdiags: RegisterErrorDetail requires an example error")
+	}
+	ty := reflect.TypeOf(example)
+	tmpl := template.Must(template.New(ty.String()).Option("missingkey=error").Parse(text))
+
+	errorDetailsMu.Lock()
+	defer errorDetailsMu.Unlock()
+	if _, exists := errorDetails[ty]; exists {
+		panic(fmt.Sprintf("tbdiags: error detail for %s is already registered", ty))
+	}
+	errorDetails[ty] = tmpl
+}
+
+// errorDetail returns the result of the detail template registered for the
+// given error or the first error it wraps that has one, or false if there
+// is no such template or it fails.
+func errorDetail(err error) (string, bool) {
+	errorDetailsMu.RLock()
+	defer errorDetailsMu.RUnlock()
+	if len(errorDetails) == 0 {
+		return "", false
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		tmpl, ok := errorDetails[reflect.TypeOf(err)]
+		if !ok {
+			continue
+		}
+		var buf strings.Builder
+		if tmpl.Execute(&buf, err) != nil {
+			return "", false
+		}
+		return strings.TrimSpace(buf.String()), true
+	}
+	return "", false
+}
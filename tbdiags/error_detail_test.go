@@ -0,0 +1,40 @@
+package tbdiags
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestRegisterErrorDetail(t *testing.T) {
+	defer func() {
+		errorDetailsMu.Lock()
+		delete(errorDetails, reflect.TypeOf((*os.LinkError)(nil)))
+		errorDetailsMu.Unlock()
+	}()
+	RegisterErrorDetail((*os.LinkError)(nil), "Operation: {{.Op}}\nOld path: {{.Old}}\nNew path: {{.New}}")
+
+	err := fmt.Errorf("install failed: %w", &os.LinkError{Op: "symlink", Old: "a.tb", New: "b.tb", Err: errors.New("file exists")})
+	diags := Diagnostics(nil).Append(err)
+	desc := diags[0].Description()
+	if got, want := desc.Summary, "install failed: symlink a.tb b.tb: file exists"; got != want {
+		t.Errorf("wrong summary\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := desc.Detail, "Operation: symlink\nOld path: a.tb\nNew path: b.tb"; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+
+	other := Diagnostics(nil).Append(errors.New("unrelated"))
+	if got := other[0].Description().Detail; got != "" {
+		t.Errorf("unexpected detail %q for an unregistered error type", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("registering a second template for the same type didn't panic")
+		}
+	}()
+	RegisterErrorDetail(&os.LinkError{}, "{{.Op}}")
+}
@@ -0,0 +1,54 @@
+package tbdiags
+
+import (
+	"strings"
+)
+
+// ErrorSummary returns a short description of the errors in the receiver,
+// suitable for space-constrained user interfaces such as tooltips and
+// status bars, or an empty string if there are no errors.
+//
+// The result is the summaries of up to maxItems errors, separated by
+// semicolons and followed by a count of any that were omitted, such as
+// "Bad thing; Worse thing (and 3 more)". If the result would be longer than
+// maxLen bytes, fewer summaries are included, and if even one summary is too
+// long, it's shortened at a word boundary. Zero for either limit means that
+// the result is not limited in that way.
+func (diags Diagnostics) ErrorSummary(maxItems, maxLen int) string {
+	var summaries []string
+	for _, diag := range diags {
+		if diag.Severity().isError() {
+			summaries = append(summaries, diag.Description().Summary)
+		}
+	}
+	if len(summaries) == 0 {
+		return ""
+	}
+
+	shown := len(summaries)
+	if maxItems > 0 && shown > maxItems {
+		shown = maxItems
+	}
+	for ; shown > 0; shown-- {
+		ret := strings.Join(summaries[:shown], "; ") + moreSuffix(len(summaries)-shown)
+		if maxLen <= 0 || len(ret) <= maxLen {
+			return ret
+		}
+	}
+
+	// Even the first summary alone is too long, so we'll shorten it while
+	// keeping the count of the others. If there isn't room for even that,
+	// the count is all that's left.
+	suffix := moreSuffix(len(summaries) - 1)
+	if maxLen <= len(suffix) {
+		return strings.TrimSpace(suffix)
+	}
+	return truncateAtWord(summaries[0], maxLen-len(suffix)) + suffix
+}
+
+func moreSuffix(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return " (and " + FormatCount(n, NounMore) + ")"
+}
@@ -0,0 +1,73 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestDiagnosticsErrorSummary(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Error, "Bad thing", ""),
+		Sourceless(Warning, "Dubious thing", ""),
+		Sourceless(Error, "Worse thing", ""),
+		Sourceless(Error, "Terrible thing", ""),
+	}
+
+	tests := map[string]struct {
+		Diags    Diagnostics
+		MaxItems int
+		MaxLen   int
+		Want     string
+	}{
+		"no errors": {
+			Diagnostics{Sourceless(Warning, "Dubious thing", "")},
+			0, 0,
+			"",
+		},
+		"unlimited": {
+			diags,
+			0, 0,
+			"Bad thing; Worse thing; Terrible thing",
+		},
+		"max items": {
+			diags,
+			2, 0,
+			"Bad thing; Worse thing (and 1 more)",
+		},
+		"max length": {
+			diags,
+			0, 30,
+			"Bad thing (and 2 more)",
+		},
+		"truncated summary": {
+			Diagnostics{
+				Sourceless(Error, "The configuration could not be loaded", ""),
+				Sourceless(Error, "Bad thing", ""),
+			},
+			0, 35,
+			"The configuration… (and 1 more)",
+		},
+		"no room for any summary": {
+			Diagnostics{
+				Sourceless(Error, "X", ""),
+				Sourceless(Error, "Yyyyyyyyyyyyy", ""),
+				Sourceless(Error, "Zzzzzzzzzzzzz", ""),
+			},
+			0, 5,
+			"(and 2 more)",
+		},
+		"tiny length": {
+			Diagnostics{Sourceless(Error, "Bad thing", "")},
+			0, 2,
+			"B…",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := test.Diags.ErrorSummary(test.MaxItems, test.MaxLen)
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package tbdiags
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestNativeErrorCause(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "main.tb", Err: fs.ErrNotExist}
+	err := fmt.Errorf("loading configuration: %w", pathErr)
+	diags := Diagnostics(nil).Append(err)
+
+	if got := CauseOf(diags[0]); got != err {
+		t.Errorf("wrong cause %#v; want %#v", got, err)
+	}
+	escalated := Escalate(diags[0], Fatal, "strict mode")
+	if got := CauseOf(escalated); got != err {
+		t.Errorf("wrong cause %#v of wrapped diagnostic; want %#v", got, err)
+	}
+	if got := CauseOf(Sourceless(Error, "Bad thing", "")); got != nil {
+		t.Errorf("unexpected cause %#v", got)
+	}
+
+	combined := Diagnostics{escalated, Sourceless(Warning, "Odd thing", "")}.Err()
+	if !errors.Is(combined, fs.ErrNotExist) {
+		t.Errorf("errors.Is can't find the cause in %q", combined)
+	}
+	var target *fs.PathError
+	if !errors.As(combined, &target) || target.Path != "main.tb" {
+		t.Errorf("errors.As can't find the cause in %q", combined)
+	}
+
+	got := (&Renderer{Verbose: true}).RenderString(diags)
+	want := strings.Join([]string{
+		"Error: loading configuration: open main.tb: file does not exist",
+		"  (caused by *fs.PathError: open main.tb: file does not exist)",
+		"  (caused by *errors.errorString: file does not exist)",
+		"", "",
+	}, "\n")
+	if got != want {
+		t.Errorf("wrong rendering\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "vars.tb", Err: fs.ErrNotExist}
+	rng := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 4, Column: 3, Byte: 40},
+		End:      SourcePos{Line: 4, Column: 20, Byte: 57},
+	}
+	diag := Wrap(pathErr, "Failed to load variables file", &rng)
+	rng.Filename = "changed.tb"
+
+	if got := diag.Severity(); got != Error {
+		t.Errorf("wrong severity %s", got)
+	}
+	desc := diag.Description()
+	if got, want := desc.Summary, "Failed to load variables file"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := desc.Detail, "open vars.tb: file does not exist"; got != want {
+		t.Errorf("wrong detail %q; want %q", got, want)
+	}
+	if subject := diag.Source().Subject; subject == nil || subject.Filename != "main.tb" {
+		t.Errorf("wrong subject %v", subject)
+	}
+	if got := CauseOf(diag); got != pathErr {
+		t.Errorf("wrong cause %#v; want %#v", got, pathErr)
+	}
+	if !errors.Is(Diagnostics{diag}.Err(), fs.ErrNotExist) {
+		t.Errorf("errors.Is can't find the cause")
+	}
+	if Wrap(pathErr, "Failed", nil).Source().Subject != nil {
+		t.Errorf("subject for a nil range")
+	}
+	if got := Wrap(nil, "Failed", &rng); got != nil {
+		t.Errorf("wrong result %#v for a nil error", got)
+	}
+	if got := (Diagnostics{}).Append(Wrap(nil, "Failed", nil)); len(got) != 0 {
+		t.Errorf("appended %d diagnostics for a nil error", len(got))
+	}
+}
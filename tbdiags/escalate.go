@@ -0,0 +1,203 @@
+package tbdiags
+
+import (
+	"path/filepath"
+)
+
+// Escalation describes how the severity of a diagnostic was changed by a
+// policy, such as a strict mode that treats warnings as errors, so that
+// users can understand why something that used to pass now fails.
+type Escalation struct {
+	// From is the severity the diagnostic had before any escalation.
+	From Severity
+
+	// Policy is a user-facing description of the policy that escalated the
+	// diagnostic, such as "strict mode".
+	Policy string
+}
+
+// DiagnosticEscalated is an optional interface implemented by diagnostics
+// whose severity was changed by a policy.
+type DiagnosticEscalated interface {
+	Escalation() Escalation
+}
+
+// Escalate returns a diagnostic that is the same as the given diagnostic
+// except that it has the given severity, which is usually higher, and
+// records that it was changed by the given policy. It's the same as
+// ChangeSeverity, which is clearer for policies that lower severities.
+func Escalate(diag Diagnostic, severity Severity, policy string) Diagnostic {
+	return ChangeSeverity(diag, severity, policy)
+}
+
+// ChangeSeverity returns a diagnostic that is the same as the given
+// diagnostic except that it has the given severity, which may be higher or
+// lower, and records that it was changed by the given policy. The change is
+// reported by EscalationOf, and by the Renderer as an escalation or a
+// demotion depending on its direction.
+//
+// If the given diagnostic's severity was itself changed then the result
+// records its original severity, so that the provenance refers to what the
+// producer of the diagnostic originally reported.
+func ChangeSeverity(diag Diagnostic, severity Severity, policy string) Diagnostic {
+	from := diag.Severity()
+	if prev, ok := EscalationOf(diag); ok {
+		from = prev.From
+	}
+	return escalatedDiagnostic{
+		Diagnostic: diag,
+		severity:   severity,
+		escalation: Escalation{
+			From:   from,
+			Policy: policy,
+		},
+	}
+}
+
+// PromoteWarnings returns a copy of the receiver in which each warning is
+// escalated to an error by the "warnings as errors" policy, for the -werror
+// modes of command line tools. The source and description of each promoted
+// warning are unchanged.
+//
+// If any glob patterns are given, in the syntax of filepath.Match, then only
+// warnings whose subject's filename matches one of them are promoted. Use
+// PromoteWarningsWith to select warnings by their codes instead.
+func (diags Diagnostics) PromoteWarnings(globs ...string) Diagnostics {
+	return diags.PromoteWarningsWith(PromoteOptions{Globs: globs})
+}
+
+// PromoteOptions selects the warnings that PromoteWarningsWith promotes. A
+// warning must match both of the fields that are set, so the zero value
+// selects every warning.
+type PromoteOptions struct {
+	// Codes, if not empty, limits promotion to warnings that have one of
+	// the given codes, such as for a -Werror=TB1001 flag. A code qualified
+	// by an origin, such as "linter/E102", matches only the warnings from
+	// that origin. See QualifiedCode.
+	Codes []string
+
+	// Globs, if not empty, limits promotion to warnings whose subject's
+	// filename matches one of the given glob patterns, in the syntax of
+	// filepath.Match.
+	Globs []string
+}
+
+// PromoteWarningsWith is like PromoteWarnings except that the warnings to
+// promote are selected by the given options.
+func (diags Diagnostics) PromoteWarningsWith(opts PromoteOptions) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		if diag.Severity() == Warning && matchesAnyCode(diag, opts.Codes) && matchesAnyGlob(diag, opts.Globs) {
+			diag = Escalate(diag, Error, "warnings as errors")
+		}
+		ret[i] = diag
+	}
+	return ret
+}
+
+// Demote returns a copy of the receiver in which each diagnostic that the
+// given function matches is changed to a lower severity by the "noise
+// reduction" policy, so that embedding applications can downgrade
+// diagnostics they consider noisy. Errors, including fatal errors, become
+// warnings, and warnings become hints. Hints are unchanged.
+func (diags Diagnostics) Demote(match func(Diagnostic) bool) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		if match(diag) {
+			switch sev := diag.Severity(); {
+			case sev.isError():
+				diag = ChangeSeverity(diag, Warning, "noise reduction")
+			case sev == Warning:
+				diag = ChangeSeverity(diag, Hint, "noise reduction")
+			}
+		}
+		ret[i] = diag
+	}
+	return ret
+}
+
+// Suppress splits the receiver into the diagnostics that the given function
+// doesn't match, which are kept, and those that it matches, which are
+// suppressed, preserving their order. The suppressed diagnostics are
+// returned rather than discarded so that they remain available for
+// auditing, such as with AuditLog.RecordSuppressions.
+func (diags Diagnostics) Suppress(match func(Diagnostic) bool) (kept, suppressed Diagnostics) {
+	for _, diag := range diags {
+		if match(diag) {
+			suppressed = append(suppressed, diag)
+		} else {
+			kept = append(kept, diag)
+		}
+	}
+	return kept, suppressed
+}
+
+// matchesAnyCode returns true if there are no codes or if the diagnostic
+// has one of them.
+func matchesAnyCode(diag Diagnostic, codes []string) bool {
+	if len(codes) == 0 {
+		return true
+	}
+	for _, code := range codes {
+		if matchesCode(diag, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesAnyGlob returns true if there are no glob patterns or if the
+// filename of the diagnostic's subject matches at least one of them.
+func matchesAnyGlob(diag Diagnostic, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	subject := subjectOf(diag)
+	if subject == nil {
+		return false
+	}
+	for _, glob := range globs {
+		if ok, _ := filepath.Match(glob, subject.Filename); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// EscalationOf returns the escalation recorded for the given diagnostic, if
+// it implements DiagnosticEscalated.
+func EscalationOf(diag Diagnostic) (Escalation, bool) {
+	var ret Escalation
+	found := findDiagnostic(diag, func(diag Diagnostic) bool {
+		esc, ok := diag.(DiagnosticEscalated)
+		if ok {
+			ret = esc.Escalation()
+		}
+		return ok
+	})
+	return ret, found
+}
+
+type escalatedDiagnostic struct {
+	Diagnostic
+	severity   Severity
+	escalation Escalation
+}
+
+func (d escalatedDiagnostic) Severity() Severity {
+	return d.severity
+}
+
+func (d escalatedDiagnostic) Escalation() Escalation {
+	return d.escalation
+}
+
+func (d escalatedDiagnostic) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,184 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEscalate(t *testing.T) {
+	orig := Sourceless(Warning, "Deprecated setting", "")
+	escalated := Escalate(orig, Error, "strict mode")
+
+	if got, want := escalated.Severity(), Error; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	esc, ok := EscalationOf(escalated)
+	if !ok {
+		t.Fatalf("no escalation recorded")
+	}
+	if want := (Escalation{From: Warning, Policy: "strict mode"}); esc != want {
+		t.Errorf("wrong escalation %#v; want %#v", esc, want)
+	}
+
+	again := Escalate(escalated, Error, "release policy")
+	if esc, _ := EscalationOf(again); esc.From != Warning {
+		t.Errorf("wrong original severity %s; want %s", esc.From, Warning)
+	}
+
+	r := &Renderer{Verbose: true}
+	got := withoutReportedAt(r.RenderString(Diagnostics{escalated}))
+	want := `Error: Deprecated setting
+  (escalated from Warning by strict mode)
+
+`
+	if got != want {
+		t.Errorf("wrong rendering\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestDiagnosticsPromoteWarnings(t *testing.T) {
+	inFile := func(filename, summary string) Diagnostic {
+		rng := LineRange(filename, 1)
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Warning, summary: summary},
+			subject:        &rng,
+		}
+	}
+	diags := Diagnostics{
+		inFile("main.tb", "A"),
+		inFile("vendor/lib.tb", "B"),
+		Sourceless(Warning, "C", ""),
+		Sourceless(Hint, "D", ""),
+		Sourceless(Error, "E", ""),
+	}
+
+	tests := map[string]struct {
+		Globs []string
+		Want  string
+	}{
+		"all warnings": {
+			nil,
+			"Error A, Error B, Error C, Hint D, Error E",
+		},
+		"matching glob": {
+			[]string{"*.tb"},
+			"Error A, Warning B, Warning C, Hint D, Error E",
+		},
+		"no match": {
+			[]string{"*.txt"},
+			"Warning A, Warning B, Warning C, Hint D, Error E",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, diag := range diags.PromoteWarnings(test.Globs...) {
+				got = append(got, diag.Severity().String()+" "+diag.Description().Summary)
+			}
+			if got := strings.Join(got, ", "); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+
+	promoted := diags.PromoteWarnings()[0]
+	if esc, ok := EscalationOf(promoted); !ok || esc.From != Warning {
+		t.Errorf("promotion not recorded as an escalation")
+	}
+	if subjectOf(promoted) == nil {
+		t.Errorf("promotion lost the source")
+	}
+	if diags[0].Severity() != Warning {
+		t.Errorf("receiver was modified")
+	}
+
+	coded := Diagnostics{
+		WithCode(inFile("main.tb", "F"), "TB1001"),
+		WithCode(inFile("vendor/lib.tb", "G"), "TB1001"),
+		WithCode(inFile("main.tb", "H"), "TB1002"),
+		inFile("main.tb", "I"),
+		WithOrigin(WithCode(inFile("main.tb", "J"), "TB1001"), "linter"),
+	}
+	for name, test := range map[string]struct {
+		Opts PromoteOptions
+		Want string
+	}{
+		"matching code": {
+			PromoteOptions{Codes: []string{"TB1001"}},
+			"Error F, Error G, Warning H, Warning I, Error J",
+		},
+		"matching code and glob": {
+			PromoteOptions{Codes: []string{"TB1001", "TB1002"}, Globs: []string{"*.tb"}},
+			"Error F, Warning G, Error H, Warning I, Error J",
+		},
+		"matching code from origin": {
+			PromoteOptions{Codes: []string{"linter/TB1001"}},
+			"Warning F, Warning G, Warning H, Warning I, Error J",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var got []string
+			for _, diag := range coded.PromoteWarningsWith(test.Opts) {
+				got = append(got, diag.Severity().String()+" "+diag.Description().Summary)
+			}
+			if got := strings.Join(got, ", "); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsDemoteAndSuppress(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Fatal, "Noisy A", ""),
+		Sourceless(Error, "B", ""),
+		Sourceless(Warning, "Noisy C", ""),
+		Sourceless(Hint, "Noisy D", ""),
+	}
+	noisy := func(diag Diagnostic) bool {
+		return strings.HasPrefix(diag.Description().Summary, "Noisy")
+	}
+	describe := func(diags Diagnostics) string {
+		var ret []string
+		for _, diag := range diags {
+			ret = append(ret, diag.Severity().String()+" "+diag.Description().Summary)
+		}
+		return strings.Join(ret, ", ")
+	}
+
+	demoted := diags.Demote(noisy)
+	if got, want := describe(demoted), "Warning Noisy A, Error B, Hint Noisy C, Hint Noisy D"; got != want {
+		t.Errorf("wrong demotion result\ngot:  %s\nwant: %s", got, want)
+	}
+	if esc, ok := EscalationOf(demoted[0]); !ok || esc.From != Fatal {
+		t.Errorf("demotion not recorded")
+	}
+	r := &Renderer{Verbose: true}
+	got := withoutReportedAt(r.RenderString(demoted[:1]))
+	want := `Warning: Noisy A
+  (demoted from Fatal by noise reduction)
+
+`
+	if got != want {
+		t.Errorf("wrong rendering\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	kept, suppressed := diags.Suppress(noisy)
+	if got, want := describe(kept), "Error B"; got != want {
+		t.Errorf("wrong kept diagnostics\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := describe(suppressed), "Fatal Noisy A, Warning Noisy C, Hint Noisy D"; got != want {
+		t.Errorf("wrong suppressed diagnostics\ngot:  %s\nwant: %s", got, want)
+	}
+
+	var log AuditLog
+	log.RecordEscalations(demoted)
+	log.RecordSuppressions(suppressed, "noise filter")
+	var actions []string
+	for _, entry := range log.Entries() {
+		actions = append(actions, string(entry.Action)+" "+entry.Rule)
+	}
+	if got, want := strings.Join(actions, ", "), "demoted noise reduction, demoted noise reduction, suppressed noise filter, suppressed noise filter, suppressed noise filter"; got != want {
+		t.Errorf("wrong audit log\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,77 @@
+package tbdiags
+
+// DiagnosticExtraInfo is an optional interface implemented by diagnostics
+// that carry an arbitrary payload for callers that know how to interpret
+// it, such as structured machine-readable data or a hint about how to
+// retry an operation.
+//
+// Callers should not type-assert a diagnostic to this interface directly,
+// because the payload might belong to a diagnostic wrapped by another.
+// Instead, use the generic ExtraInfo function, which finds a payload of a
+// particular type, or ExtraInfos, which returns all of them.
+type DiagnosticExtraInfo interface {
+	ExtraInfo() interface{}
+}
+
+// WithExtraInfo returns a diagnostic that is the same as the given
+// diagnostic except that it also implements DiagnosticExtraInfo, returning
+// the given payload. Payloads attached to the given diagnostic remain
+// available from ExtraInfo and ExtraInfos.
+func WithExtraInfo(diag Diagnostic, info interface{}) Diagnostic {
+	return withExtraInfo{diag, info}
+}
+
+// ExtraInfos returns the payloads of the given diagnostic and of any
+// diagnostics it wraps, outermost first, for callers that can't use the
+// generic ExtraInfo function.
+func ExtraInfos(diag Diagnostic) []interface{} {
+	var ret []interface{}
+	findExtraInfo(diag, func(info interface{}) bool {
+		ret = append(ret, info)
+		return false
+	})
+	return ret
+}
+
+// findExtraInfo calls fn with each payload of the given diagnostic and of
+// the diagnostics it wraps, outermost first, stopping and returning true at
+// the first for which fn returns true. Payloads hidden by withoutExtraInfo
+// are skipped.
+func findExtraInfo(diag Diagnostic, fn func(info interface{}) bool) bool {
+	var found bool
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		switch diag := diag.(type) {
+		case withoutExtraInfo:
+			return true
+		case DiagnosticExtraInfo:
+			found = fn(diag.ExtraInfo())
+			return found
+		default:
+			return false
+		}
+	})
+	return found
+}
+
+type withExtraInfo struct {
+	Diagnostic
+	info interface{}
+}
+
+func (d withExtraInfo) ExtraInfo() interface{} {
+	return d.info
+}
+
+func (d withExtraInfo) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
+
+// withoutExtraInfo hides the payloads of the diagnostic it wraps, as for
+// Diagnostics.ForCapabilities.
+type withoutExtraInfo struct {
+	Diagnostic
+}
+
+func (d withoutExtraInfo) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
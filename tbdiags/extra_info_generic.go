@@ -0,0 +1,26 @@
+//go:build go1.21
+// +build go1.21
+
+package tbdiags
+
+// ExtraInfo returns the outermost payload of type T attached to the given
+// diagnostic, or to any diagnostic it wraps, using DiagnosticExtraInfo. The
+// second return value is false if there is no such payload.
+//
+// T may be an interface type, in which case the result is the outermost
+// payload that implements it.
+//
+// ExtraInfo requires Go 1.21 or later, because earlier versions don't allow
+// generics in a module that, like this one, declares an older Go version.
+// Callers using older versions can use ExtraInfos instead.
+func ExtraInfo[T any](diag Diagnostic) (T, bool) {
+	var ret T
+	found := findExtraInfo(diag, func(info interface{}) bool {
+		v, ok := info.(T)
+		if ok {
+			ret = v
+		}
+		return ok
+	})
+	return ret, found
+}
@@ -0,0 +1,39 @@
+//go:build go1.21
+// +build go1.21
+
+package tbdiags
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtraInfo(t *testing.T) {
+	type retryHint struct {
+		After time.Duration
+	}
+
+	diag := WithExtraInfo(Sourceless(Error, "Rate limited", ""), retryHint{After: time.Minute})
+	diag = WithExtraInfo(diag, "unrelated")
+
+	hint, ok := ExtraInfo[retryHint](diag)
+	if !ok {
+		t.Fatalf("payload not found")
+	}
+	if hint.After != time.Minute {
+		t.Errorf("wrong payload %#v", hint)
+	}
+	if got, ok := ExtraInfo[string](diag); !ok || got != "unrelated" {
+		t.Errorf("wrong string payload %q", got)
+	}
+	if _, ok := ExtraInfo[int](diag); ok {
+		t.Errorf("found a payload of a type that isn't attached")
+	}
+	if _, ok := ExtraInfo[retryHint](Sourceless(Error, "Other", "")); ok {
+		t.Errorf("found a payload on a diagnostic without one")
+	}
+	stripped := Diagnostics{diag}.ForCapabilities(Capabilities{})[0]
+	if _, ok := ExtraInfo[retryHint](stripped); ok {
+		t.Errorf("found a payload removed by ForCapabilities")
+	}
+}
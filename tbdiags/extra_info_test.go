@@ -0,0 +1,24 @@
+package tbdiags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtraInfos(t *testing.T) {
+	diag := Sourceless(Error, "Rate limited", "")
+	if got := ExtraInfos(diag); got != nil {
+		t.Errorf("unexpected payloads %#v", got)
+	}
+
+	diag = WithExtraInfo(diag, 30)
+	diag = WithOrigin(diag, "api")
+	diag = WithExtraInfo(diag, "retry later")
+	want := []interface{}{"retry later", 30}
+	if got := ExtraInfos(diag); !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong payloads\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := OriginOf(diag); got != "api" {
+		t.Errorf("payload hid the origin; got %q", got)
+	}
+}
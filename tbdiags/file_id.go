@@ -0,0 +1,125 @@
+package tbdiags
+
+import (
+	"path/filepath"
+)
+
+// FileID identifies a file in a way that doesn't depend on the path that was
+// used to reach it, so that diagnostics about the same file can be recognized
+// as such even if they were produced using different relative paths or via
+// symbolic links.
+//
+// A FileID is the cleaned absolute path of the file with any symbolic links
+// resolved. Files that don't exist, or that can't be inspected, are
+// identified only by their cleaned absolute path.
+type FileID string
+
+// FileIDOf returns the FileID for the file with the given name, which is
+// interpreted relative to the current working directory if it isn't
+// absolute.
+func FileIDOf(filename string) FileID {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		path = filepath.Clean(filename)
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return FileID(path)
+}
+
+// fileIDCache memoizes calls to FileIDOf, which must consult the filesystem,
+// for the duration of a single operation over a diagnostics list.
+type fileIDCache map[string]FileID
+
+// SubjectID returns the FileID for the subject of the given range. Subjects
+// that are not files get an identifier that can't be mistaken for a file,
+// such as "env:TB_TOKEN".
+func (c fileIDCache) SubjectID(rng *SourceRange) FileID {
+	switch rng.Kind {
+	case SubjectEnvVar:
+		return FileID("env:" + rng.Filename)
+	case SubjectFlag:
+		return FileID("flag:" + rng.Filename)
+	case SubjectObjectKey:
+		return FileID("object:" + rng.Filename)
+	case SubjectArchiveEntry:
+		if id, ok := c[rng.Filename]; ok {
+			return id
+		}
+		archive, entry := splitArchiveEntry(rng.Filename)
+		id := FileID("archive:" + string(FileIDOf(archive)) + "!" + entry)
+		c[rng.Filename] = id
+		return id
+	}
+
+	if id, ok := c[rng.Filename]; ok {
+		return id
+	}
+	id := FileIDOf(rng.Filename)
+	c[rng.Filename] = id
+	return id
+}
+
+// FileDiagnostics is a group of diagnostics whose subjects are all in the
+// same file, as returned by Diagnostics.ByFile.
+type FileDiagnostics struct {
+	// ID is the identity of the file that the diagnostics belong to, or
+	// the empty string for the group of sourceless diagnostics.
+	ID FileID
+
+	// Filename is the filename as given in the subject of the first of the
+	// diagnostics in the group, or the empty string for the group of
+	// sourceless diagnostics.
+	Filename string
+
+	Diagnostics Diagnostics
+}
+
+// IsGeneral returns true if the receiver is the group of diagnostics that
+// have no subject.
+func (g FileDiagnostics) IsGeneral() bool {
+	return g.ID == ""
+}
+
+// ByFile groups the diagnostics in the receiver by the file their subjects
+// belong to, using FileID to recognize the same file reached via different
+// paths.
+//
+// If there are any diagnostics without a subject then they are collected
+// into a "general" group that comes first, matching the ordering used by
+// Sort, and for which IsGeneral returns true. The other groups follow in
+// the order of the first diagnostic in each group. The diagnostics within
+// each group retain their relative order.
+//
+// Subjects that are not files, such as environment variables, are grouped
+// by their kind and name.
+func (diags Diagnostics) ByFile() []FileDiagnostics {
+	var general Diagnostics
+	var ret []FileDiagnostics
+	ids := make(fileIDCache)
+	index := make(map[FileID]int)
+	for _, diag := range diags {
+		subject := subjectOf(diag)
+		if subject == nil {
+			general = append(general, diag)
+			continue
+		}
+		id := ids.SubjectID(subject)
+		i, exists := index[id]
+		if !exists {
+			i = len(ret)
+			index[id] = i
+			ret = append(ret, FileDiagnostics{
+				ID:       id,
+				Filename: subject.Filename,
+			})
+		}
+		ret[i].Diagnostics = append(ret[i].Diagnostics, diag)
+	}
+
+	if len(general) > 0 {
+		ret = append([]FileDiagnostics{{Diagnostics: general}}, ret...)
+	}
+	return ret
+}
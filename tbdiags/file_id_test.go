@@ -0,0 +1,93 @@
+package tbdiags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileIDOf(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(dir, "main.tb")
+	if err := os.WriteFile(filename, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "link.tb")
+	if err := os.Symlink(filename, link); err != nil {
+		t.Skipf("can't create symlink: %s", err)
+	}
+
+	want := FileID(filename)
+	for _, name := range []string{
+		filename,
+		link,
+		filepath.Join(dir, "sub", "..", "main.tb"),
+	} {
+		if got := FileIDOf(name); got != want {
+			t.Errorf("wrong FileID for %s\ngot:  %s\nwant: %s", name, got, want)
+		}
+	}
+}
+
+func TestDiagnosticsByFile(t *testing.T) {
+	diag := func(filename, summary string) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  summary,
+			},
+			subject: &SourceRange{Filename: filename},
+		}
+	}
+	diags := Diagnostics{
+		diag("a.tb", "first"),
+		Sourceless(Error, "sourceless", ""),
+		diag("b.tb", "second"),
+		diag("./a.tb", "third"),
+	}
+
+	got := diags.ByFile()
+	if len(got) != 3 {
+		t.Fatalf("wrong number of groups %d; want 3", len(got))
+	}
+	if !got[0].IsGeneral() || len(got[0].Diagnostics) != 1 {
+		t.Errorf("wrong general group %#v", got[0])
+	}
+	if got[1].Filename != "a.tb" || len(got[1].Diagnostics) != 2 {
+		t.Errorf("wrong first file group %#v", got[1])
+	}
+	if got[2].Filename != "b.tb" || len(got[2].Diagnostics) != 1 {
+		t.Errorf("wrong second file group %#v", got[2])
+	}
+}
+
+func TestDiagnosticsByFileSubjectKinds(t *testing.T) {
+	diag := func(kind SubjectKind, name string) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  "Invalid value",
+			},
+			subject: &SourceRange{Filename: name, Kind: kind},
+		}
+	}
+	diags := Diagnostics{
+		diag(SubjectFile, "TB_TOKEN"),
+		diag(SubjectEnvVar, "TB_TOKEN"),
+		diag(SubjectEnvVar, "TB_TOKEN"),
+	}
+
+	got := diags.ByFile()
+	if len(got) != 2 {
+		t.Fatalf("wrong number of groups %d; want 2", len(got))
+	}
+	if got, want := got[1].ID, FileID("env:TB_TOKEN"); got != want {
+		t.Errorf("wrong ID %q; want %q", got, want)
+	}
+	if got, want := got[1].Diagnostics[0].Source().Subject.StartString(), "environment variable TB_TOKEN"; got != want {
+		t.Errorf("wrong StartString %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package tbdiags
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// FingerprintOptions chooses which parts of a diagnostic are included in
+// the result of FingerprintWith.
+type FingerprintOptions struct {
+	// Paths decides how the filenames of subjects are normalized before
+	// being included. Fingerprints are only stable across machines if the
+	// filenames are, so most callers will want to set Paths.Root to the
+	// root of their workspace.
+	Paths PathPolicy
+
+	// IgnoreSeverity, IgnoreCode and IgnoreLocation exclude the severity,
+	// code and subject, respectively, which are otherwise included.
+	IgnoreSeverity bool
+	IgnoreCode     bool
+	IgnoreLocation bool
+
+	// IncludeColumn includes the column of the start of the subject, in
+	// addition to its filename and line.
+	IncludeColumn bool
+
+	// IncludeDetail includes the detail, which is excluded by default
+	// because it's more likely than the summary to change between
+	// versions of a producer without the problem itself changing.
+	IncludeDetail bool
+}
+
+// Fingerprint returns a stable hash of the severity, code, summary and
+// normalized subject location of the given diagnostic, using the default
+// FingerprintOptions, as a hexadecimal string. Diagnostics for the same
+// problem have the same fingerprint in different runs of a program, so
+// fingerprints are suitable for baselining, deduplication and tracking
+// problems over time.
+func Fingerprint(diag Diagnostic) string {
+	return FingerprintWith(diag, FingerprintOptions{})
+}
+
+// FingerprintWith is like Fingerprint except that it's customized by the
+// given options.
+//
+// As with MatchKey, the messages of diagnostics created from native errors
+// are normalized using StableMessage before being included.
+func FingerprintWith(diag Diagnostic, opts FingerprintOptions) string {
+	desc := diag.Description()
+	summary, detail := desc.Summary, desc.Detail
+	if CauseOf(diag) != nil {
+		summary = StableMessage(summary)
+		detail = StableMessage(detail)
+	}
+
+	// Each part is labelled and on its own line, so that different
+	// combinations of options can't produce the same input to the hash.
+	var b strings.Builder
+	if !opts.IgnoreSeverity {
+		fmt.Fprintf(&b, "severity %s\n", strings.ToLower(diag.Severity().String()))
+	}
+	if !opts.IgnoreCode && desc.Code != "" {
+		fmt.Fprintf(&b, "code %q\n", desc.Code)
+	}
+	fmt.Fprintf(&b, "summary %q\n", summary)
+	if opts.IncludeDetail {
+		fmt.Fprintf(&b, "detail %q\n", detail)
+	}
+	if subject := subjectOf(diag); subject != nil && !opts.IgnoreLocation {
+		filename := subject.Filename
+		if subject.Kind == SubjectFile {
+			filename = filepath.ToSlash(opts.Paths.DisplayPath(filename))
+		}
+		fmt.Fprintf(&b, "subject %d %q", subject.Kind, filename)
+		if subject.Precision == PrecisionExact || subject.Precision == PrecisionLine {
+			fmt.Fprintf(&b, " %d", subject.Start.Line)
+			if opts.IncludeColumn && subject.Precision == PrecisionExact {
+				fmt.Fprintf(&b, ":%d", subject.Start.Column)
+			}
+		}
+		b.WriteByte('\n')
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,108 @@
+package tbdiags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	opts := FingerprintOptions{Paths: PathPolicy{Root: "/work"}}
+	at := func(filename string, line, column int, severity Severity, detail string) Diagnostic {
+		return WithCode(sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: severity, summary: "Unused variable", detail: detail},
+			subject: &SourceRange{
+				Filename: filename,
+				Start:    SourcePos{Line: line, Column: column},
+				End:      SourcePos{Line: line, Column: column + 1},
+			},
+		}, "TB3001")
+	}
+	orig := at("/work/main.tb", 3, 5, Warning, "It's never used.")
+	fingerprint := FingerprintWith(orig, opts)
+
+	tests := map[string]struct {
+		diag Diagnostic
+		opts FingerprintOptions
+		same bool
+	}{
+		"identical": {
+			at("/work/main.tb", 3, 5, Warning, "It's never used."),
+			opts,
+			true,
+		},
+		"different column": {
+			at("/work/main.tb", 3, 9, Warning, "It's never used."),
+			opts,
+			true,
+		},
+		"different column included": {
+			at("/work/main.tb", 3, 9, Warning, "It's never used."),
+			FingerprintOptions{Paths: opts.Paths, IncludeColumn: true},
+			false,
+		},
+		"different detail": {
+			at("/work/main.tb", 3, 5, Warning, "Remove it."),
+			opts,
+			true,
+		},
+		"different detail included": {
+			at("/work/main.tb", 3, 5, Warning, "Remove it."),
+			FingerprintOptions{Paths: opts.Paths, IncludeDetail: true},
+			false,
+		},
+		"different line": {
+			at("/work/main.tb", 4, 5, Warning, "It's never used."),
+			opts,
+			false,
+		},
+		"different file": {
+			at("/work/other.tb", 3, 5, Warning, "It's never used."),
+			opts,
+			false,
+		},
+		"different severity": {
+			at("/work/main.tb", 3, 5, Error, "It's never used."),
+			opts,
+			false,
+		},
+		"different root": {
+			at("/home/ci/main.tb", 3, 5, Warning, "It's never used."),
+			FingerprintOptions{Paths: PathPolicy{Root: "/home/ci"}},
+			true,
+		},
+		"different code": {
+			WithCode(orig, "TB3002"),
+			opts,
+			false,
+		},
+		"no code": {
+			sourcedDiagnostic{
+				diagnosticBase: diagnosticBase{severity: Warning, summary: "Unused variable"},
+				subject:        orig.Source().Subject,
+			},
+			opts,
+			false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := FingerprintWith(test.diag, test.opts)
+			if same := got == fingerprint; same != test.same {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s (same: %t)", got, fingerprint, test.same)
+			}
+		})
+	}
+
+	if got := Fingerprint(orig); len(got) != 32 {
+		t.Errorf("wrong fingerprint length %d for %q", len(got), got)
+	}
+	a := Diagnostics(nil).Append(errors.New("open /tmp/123456/state: no such file"))
+	b := Diagnostics(nil).Append(errors.New("open /tmp/654321/state: no such file"))
+	if Fingerprint(a[0]) != Fingerprint(b[0]) {
+		t.Errorf("native errors that differ only in variable details have different fingerprints")
+	}
+	if Fingerprint(WithOrigin(a[0], "cache")) != Fingerprint(WithOrigin(b[0], "cache")) {
+		t.Errorf("wrapped native errors that differ only in variable details have different fingerprints")
+	}
+}
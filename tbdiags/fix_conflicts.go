@@ -0,0 +1,43 @@
+package tbdiags
+
+import (
+	"fmt"
+)
+
+// FixConflicts returns a warning for each pair of the given diagnostics
+// whose preferred suggested fixes have overlapping edits, so that tools can
+// report the conflicts before calling ApplyFixes, which can apply only one
+// fix of each pair.
+//
+// The subject of each warning is the conflicting edit of the later
+// diagnostic, and its related information points at the conflicting edit
+// of the earlier one.
+func (diags Diagnostics) FixConflicts() Diagnostics {
+	fixable := diags.Fixable()
+	var ret Diagnostics
+	for i, later := range fixable {
+		laterFix := FixesOf(later)[0]
+		for _, earlier := range fixable[:i] {
+			earlierEdit, laterEdit, ok := overlappingEdits(FixesOf(earlier)[0], laterFix)
+			if !ok {
+				continue
+			}
+			subject := laterEdit.Range
+			ret = append(ret, WithRelated(sourcedDiagnostic{
+				diagnosticBase: diagnosticBase{
+					severity: Warning,
+					summary:  "Conflicting suggested fixes",
+					detail: fmt.Sprintf(
+						"The suggested fixes for %q and %q change overlapping text, so only one of them can be applied automatically.",
+						earlier.Description().Summary, later.Description().Summary,
+					),
+				},
+				subject: &subject,
+			}, RelatedInfo{
+				Message: "conflicting edit here",
+				Range:   earlierEdit.Range,
+			}))
+		}
+	}
+	return ret
+}
@@ -0,0 +1,74 @@
+package tbdiags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticsFixConflicts(t *testing.T) {
+	fixable := func(severity Severity, summary string, start, end int) Diagnostic {
+		return WithFixes(Sourceless(severity, summary, ""), SuggestedFix{
+			Message: "Fix it",
+			Edits:   []TextEdit{{Range: editRange("main.tb", start, end), NewText: "x"}},
+		})
+	}
+	diags := Diagnostics{
+		fixable(Warning, "Rename", 0, 3),
+		fixable(Error, "Replace", 2, 5),
+		fixable(Warning, "Elsewhere", 8, 9),
+	}
+
+	conflicts := diags.FixConflicts()
+	if len(conflicts) != 1 {
+		t.Fatalf("wrong number of conflicts %d; want 1", len(conflicts))
+	}
+	conflict := conflicts[0]
+	if got, want := conflict.Description().Detail, `The suggested fixes for "Rename" and "Replace" change overlapping text, so only one of them can be applied automatically.`; got != want {
+		t.Errorf("wrong detail\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := *subjectOf(conflict), editRange("main.tb", 2, 5); got != want {
+		t.Errorf("wrong subject %#v; want %#v", got, want)
+	}
+	if got, want := RelatedOf(conflict), []RelatedInfo{{Message: "conflicting edit here", Range: editRange("main.tb", 0, 3)}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong related information %#v; want %#v", got, want)
+	}
+
+	tests := map[string]struct {
+		resolve FixResolver
+		applied []string
+	}{
+		"default": {
+			nil,
+			[]string{"Rename", "Elsewhere"},
+		},
+		"prefer more severe": {
+			PreferMoreSevere,
+			[]string{"Replace", "Elsewhere"},
+		},
+		"interactive": {
+			func(accepted, candidate Diagnostic) bool {
+				return candidate.Description().Summary == "Replace"
+			},
+			[]string{"Replace", "Elsewhere"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fsys := memFS{"main.tb": "abcdefghij"}
+			applied, skipped, err := ApplyFixes(fsys, diags, ApplyFixesOptions{DryRun: true, Resolve: test.resolve})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, diag := range applied {
+				got = append(got, diag.Description().Summary)
+			}
+			if !reflect.DeepEqual(got, test.applied) {
+				t.Errorf("wrong applied fixes\ngot:  %q\nwant: %q", got, test.applied)
+			}
+			if len(skipped) != 1 {
+				t.Errorf("wrong number of skipped fixes %d; want 1", len(skipped))
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package tbdiags
+
+// TextEdit is a change to a source file that replaces the text in Range,
+// which must be a PrecisionExact range in a file, with NewText. An empty
+// range inserts NewText at its start, and an empty NewText deletes the
+// range.
+type TextEdit struct {
+	Range   SourceRange
+	NewText string
+}
+
+// SuggestedFix is a change that would resolve a diagnostic, such as
+// removing an unused import, which tools can offer to apply.
+type SuggestedFix struct {
+	// Message describes the fix, such as "Remove the unused import".
+	Message string
+
+	// Edits are the changes that make up the fix, which must all be
+	// applied together. They must not overlap.
+	Edits []TextEdit
+}
+
+// DiagnosticFixes is an optional interface implemented by diagnostics that
+// can be resolved automatically, which makes them machine-fixable.
+//
+// Renderer describes the preferred fix after the detail of these
+// diagnostics. Diagnostics.MarshalJSON encodes the fixes as the "fixes"
+// property, an array of objects with "message" and "edits" properties, where
+// each edit is an object with "range" and "new_text" properties.
+type DiagnosticFixes interface {
+	// Fixes returns the suggested fixes, most preferred first.
+	Fixes() []SuggestedFix
+}
+
+// WithFixes returns a diagnostic that is the same as the given diagnostic
+// except that it also implements DiagnosticFixes, returning the given
+// fixes after any that the given diagnostic already has.
+func WithFixes(diag Diagnostic, fixes ...SuggestedFix) Diagnostic {
+	all := make([]SuggestedFix, 0, len(fixes))
+	all = append(all, FixesOf(diag)...)
+	all = append(all, fixes...)
+	return withFixes{diag, all}
+}
+
+// FixesOf returns the suggested fixes for the given diagnostic, most
+// preferred first, if it implements DiagnosticFixes, or nil otherwise.
+func FixesOf(diag Diagnostic) []SuggestedFix {
+	var ret []SuggestedFix
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		f, ok := diag.(DiagnosticFixes)
+		if ok {
+			ret = f.Fixes()
+		}
+		return ok
+	})
+	return ret
+}
+
+// Fixable returns the subset of the receiver that has at least one
+// suggested fix.
+func (diags Diagnostics) Fixable() Diagnostics {
+	var ret Diagnostics
+	for _, diag := range diags {
+		if len(FixesOf(diag)) > 0 {
+			ret = append(ret, diag)
+		}
+	}
+	return ret
+}
+
+type withFixes struct {
+	Diagnostic
+	fixes []SuggestedFix
+}
+
+func (d withFixes) Fixes() []SuggestedFix {
+	return d.fixes[:len(d.fixes):len(d.fixes)]
+}
+
+func (d withFixes) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
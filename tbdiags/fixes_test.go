@@ -0,0 +1,36 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFixes(t *testing.T) {
+	fix := SuggestedFix{
+		Message: "Remove the unused import",
+		Edits:   []TextEdit{{Range: editRange("main.tb", 0, 11), NewText: ""}},
+	}
+	diag := WithFixes(Sourceless(Warning, "Unused import", ""), fix)
+	diags := Diagnostics{diag, Sourceless(Warning, "Other", "")}
+
+	if got := FixesOf(diag); len(got) != 1 || got[0].Message != fix.Message {
+		t.Errorf("wrong fixes %#v", got)
+	}
+	if got := diags.Fixable(); len(got) != 1 {
+		t.Errorf("wrong number of fixable diagnostics %d; want 1", len(got))
+	}
+
+	got := (&Renderer{}).RenderString(diags[:1])
+	if want := "Warning: Unused import\n\nSuggested fix: Remove the unused import\n\n"; got != want {
+		t.Errorf("wrong rendering\ngot:  %q\nwant: %q", got, want)
+	}
+
+	js, err := diags[:1].MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"fixes":[{"message":"Remove the unused import","edits":[{"range":{"filename":"main.tb","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":12,"byte":11}},"new_text":""}]}]`
+	if !strings.Contains(string(js), want) {
+		t.Errorf("JSON has wrong fixes\ngot:  %s\nwant: %s", js, want)
+	}
+}
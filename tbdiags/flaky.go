@@ -0,0 +1,118 @@
+package tbdiags
+
+import (
+	"sort"
+)
+
+// RunRecord is what FindFlaky needs to know about one run of a tool: the
+// diagnostics it reported and a digest of the content of each file it read,
+// keyed by filename. The digests can be of any form, as long as a given
+// file content always has the same digest.
+type RunRecord struct {
+	Files       map[string]string
+	Diagnostics Diagnostics
+}
+
+// FlakyDiagnostic describes a diagnostic that FindFlaky found to appear and
+// disappear between runs without a corresponding change to its files.
+type FlakyDiagnostic struct {
+	// Diagnostic is the most recent occurrence of the diagnostic.
+	Diagnostic Diagnostic
+
+	// Flips is the number of times the diagnostic appeared or disappeared
+	// between consecutive runs without a file change to explain it.
+	Flips int
+}
+
+// FindFlaky compares the given runs, which must be in chronological order,
+// and returns the diagnostics that appeared or disappeared between
+// consecutive runs even though the files they relate to did not change.
+// Such "flaky" diagnostics usually indicate a nondeterministic check, so
+// the report helps rule authors find and fix them.
+//
+// Diagnostics are matched across runs using MatchKey. A diagnostic whose
+// subject is a file is considered explained by a change to that file; any
+// other diagnostic is considered explained by a change to any file.
+//
+// The result is sorted with the flakiest diagnostics first.
+func FindFlaky(runs []RunRecord) []FlakyDiagnostic {
+	flips := make(map[string]int)
+
+	for i := 1; i < len(runs); i++ {
+		prev, cur := runs[i-1], runs[i]
+		prevKeys := diagnosticsByKey(prev.Diagnostics)
+		curKeys := diagnosticsByKey(cur.Diagnostics)
+
+		check := func(key string, diag Diagnostic, present bool) {
+			if _, ok := curKeys[key]; ok == present {
+				return
+			}
+			if !filesChangedFor(diag, prev.Files, cur.Files) {
+				flips[key]++
+			}
+		}
+		for key, diag := range prevKeys {
+			check(key, diag, true)
+		}
+		for key, diag := range curKeys {
+			if _, ok := prevKeys[key]; !ok {
+				check(key, diag, false)
+			}
+		}
+	}
+
+	latest := make(map[string]Diagnostic)
+	for _, run := range runs {
+		for key, diag := range diagnosticsByKey(run.Diagnostics) {
+			latest[key] = diag
+		}
+	}
+
+	keys := make([]string, 0, len(flips))
+	for key := range flips {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if flips[keys[i]] != flips[keys[j]] {
+			return flips[keys[i]] > flips[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	ret := make([]FlakyDiagnostic, len(keys))
+	for i, key := range keys {
+		ret[i] = FlakyDiagnostic{
+			Diagnostic: latest[key],
+			Flips:      flips[key],
+		}
+	}
+	return ret
+}
+
+func diagnosticsByKey(diags Diagnostics) map[string]Diagnostic {
+	ret := make(map[string]Diagnostic, len(diags))
+	for _, diag := range diags {
+		ret[MatchKey(diag)] = diag
+	}
+	return ret
+}
+
+// filesChangedFor returns true if the files that the given diagnostic
+// relates to differ between the two given sets of file digests.
+func filesChangedFor(diag Diagnostic, prev, cur map[string]string) bool {
+	if subject := subjectOf(diag); subject != nil && subject.Kind == SubjectFile {
+		prevDigest, prevOK := prev[subject.Filename]
+		curDigest, curOK := cur[subject.Filename]
+		return prevOK != curOK || prevDigest != curDigest
+	}
+
+	if len(prev) != len(cur) {
+		return true
+	}
+	for name, digest := range prev {
+		if curDigest, ok := cur[name]; !ok || curDigest != digest {
+			return true
+		}
+	}
+	return false
+}
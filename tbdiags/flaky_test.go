@@ -0,0 +1,45 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestFindFlaky(t *testing.T) {
+	inFile := func(summary, filename string) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Warning, summary: summary},
+			subject:        &SourceRange{Filename: filename},
+		}
+	}
+	flaky := inFile("Flaky", "a.tb")
+	changed := inFile("Changed", "b.tb")
+	general := Sourceless(Warning, "General", "")
+
+	runs := []RunRecord{
+		{
+			Files:       map[string]string{"a.tb": "1", "b.tb": "1"},
+			Diagnostics: Diagnostics{flaky, changed, general},
+		},
+		{
+			// b.tb changed, which explains the disappearance of "Changed"
+			// but not of "Flaky". "General" could be explained by any file.
+			Files:       map[string]string{"a.tb": "1", "b.tb": "2"},
+			Diagnostics: Diagnostics{},
+		},
+		{
+			Files:       map[string]string{"a.tb": "1", "b.tb": "2"},
+			Diagnostics: Diagnostics{flaky, general},
+		},
+	}
+
+	got := FindFlaky(runs)
+	if len(got) != 2 {
+		t.Fatalf("wrong number of results %d; want 2\n%#v", len(got), got)
+	}
+	if summary := got[0].Diagnostic.Description().Summary; summary != "Flaky" || got[0].Flips != 2 {
+		t.Errorf("wrong first result %q with %d flips; want \"Flaky\" with 2", summary, got[0].Flips)
+	}
+	if summary := got[1].Diagnostic.Description().Summary; summary != "General" || got[1].Flips != 1 {
+		t.Errorf("wrong second result %q with %d flips; want \"General\" with 1", summary, got[1].Flips)
+	}
+}
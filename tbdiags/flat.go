@@ -0,0 +1,80 @@
+package tbdiags
+
+// FlatDiagnostic is a lossy representation of a diagnostic for use with
+// older versions of the plugin protocol, which can only transport a severity,
+// a summary, a detail, a filename and a line number for each diagnostic.
+//
+// Conversion to FlatDiagnostic discards the address, the context range, the
+// columns and byte offsets of the subject range, and any subject that is not
+// a file, and hints become warnings. Conversion back produces a diagnostic
+// whose subject, if any, is a line-only range as returned by LineRange.
+type FlatDiagnostic struct {
+	Severity int
+	Summary  string
+	Detail   string
+	File     string
+	Line     int
+}
+
+// These are the severity values used by FlatDiagnostic, which match the
+// values used by the plugin protocol.
+const (
+	FlatSeverityInvalid = 0
+	FlatSeverityError   = 1
+	FlatSeverityWarning = 2
+)
+
+// ToFlat converts the receiver to a list of FlatDiagnostic, discarding the
+// information that FlatDiagnostic cannot represent.
+func (diags Diagnostics) ToFlat() []FlatDiagnostic {
+	if len(diags) == 0 {
+		return nil
+	}
+
+	ret := make([]FlatDiagnostic, len(diags))
+	for i, diag := range diags {
+		desc := diag.Description()
+		flat := FlatDiagnostic{
+			Severity: FlatSeverityError,
+			Summary:  desc.Summary,
+			Detail:   desc.Detail,
+		}
+		if !diag.Severity().isError() {
+			flat.Severity = FlatSeverityWarning
+		}
+		if subject := subjectOf(diag); subject != nil && subject.Kind == SubjectFile {
+			flat.File = subject.Filename
+			flat.Line = subject.Start.Line
+		}
+		ret[i] = flat
+	}
+	return ret
+}
+
+// FromFlat converts a list of FlatDiagnostic back into diagnostics.
+//
+// Any severity other than FlatSeverityWarning is treated as an error, so
+// that a problem reported by a plugin is never silently downgraded.
+func FromFlat(flat []FlatDiagnostic) Diagnostics {
+	var diags Diagnostics
+	for _, f := range flat {
+		base := diagnosticBase{
+			severity: Error,
+			summary:  f.Summary,
+			detail:   f.Detail,
+		}
+		if f.Severity == FlatSeverityWarning {
+			base.severity = Warning
+		}
+		if f.File == "" {
+			diags = diags.Append(base)
+			continue
+		}
+		subject := LineRange(f.File, f.Line)
+		diags = diags.Append(sourcedDiagnostic{
+			diagnosticBase: base,
+			subject:        &subject,
+		})
+	}
+	return diags
+}
@@ -0,0 +1,49 @@
+package tbdiags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlatRoundTrip(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Warning, "Dubious thing", "Look closer."),
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  "Bad thing",
+				address:  "thing.bad",
+			},
+			subject: &SourceRange{
+				Filename: "main.tb",
+				Start:    SourcePos{Line: 3, Column: 5, Byte: 20},
+				End:      SourcePos{Line: 3, Column: 9, Byte: 24},
+			},
+		},
+	}
+
+	flat := diags.ToFlat()
+	wantFlat := []FlatDiagnostic{
+		{Severity: FlatSeverityWarning, Summary: "Dubious thing", Detail: "Look closer."},
+		{Severity: FlatSeverityError, Summary: "Bad thing", File: "main.tb", Line: 3},
+	}
+	if !reflect.DeepEqual(flat, wantFlat) {
+		t.Fatalf("wrong flat result\ngot:  %#v\nwant: %#v", flat, wantFlat)
+	}
+
+	got := FromFlat(flat)
+	if len(got) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2", len(got))
+	}
+	if got, want := got[0].Severity(), Warning; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	subject := got[1].Source().Subject
+	if subject == nil || subject.Filename != "main.tb" || subject.Start.Line != 3 {
+		t.Errorf("wrong subject %#v", subject)
+	}
+
+	if got := FromFlat([]FlatDiagnostic{{Severity: FlatSeverityInvalid}}); got[0].Severity() != Error {
+		t.Errorf("invalid severity was not treated as an error")
+	}
+}
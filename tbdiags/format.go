@@ -0,0 +1,99 @@
+package tbdiags
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Format is a named output format for diagnostics, such as a renderer or an
+// exporter, that can be registered with RegisterFormat so that host
+// programs can offer formats contributed by other packages without knowing
+// about them at compile time.
+type Format struct {
+	// Name is the unique name of the format, such as "json", which host
+	// programs typically accept as the value of a command line flag.
+	Name string
+
+	// Description is a short user-facing description of the format.
+	Description string
+
+	// ContentType is the media type of the format's output, such as
+	// "application/json".
+	ContentType string
+
+	// Write writes the given diagnostics to the given writer in the format.
+	Write func(w io.Writer, diags Diagnostics) error
+}
+
+var (
+	formats   = make(map[string]Format)
+	formatsMu sync.RWMutex
+)
+
+func init() {
+	RegisterFormat(Format{
+		Name:        "text",
+		Description: "Human-readable text",
+		ContentType: "text/plain; charset=utf-8",
+		Write: func(w io.Writer, diags Diagnostics) error {
+			return (&Renderer{}).Render(w, diags)
+		},
+	})
+	RegisterFormat(Format{
+		Name:        "json",
+		Description: "JSON array of diagnostic objects",
+		ContentType: "application/json",
+		Write: func(w io.Writer, diags Diagnostics) error {
+			src, err := diags.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(src)
+			return err
+		},
+	})
+}
+
+// RegisterFormat makes the given format available from LookupFormat and
+// AvailableFormats. This package registers the "text" and "json" formats.
+//
+// RegisterFormat is intended to be called from the init functions of
+// packages that provide formats. It panics if the format has no name or
+// Write function, or if a format with the same name is already registered.
+func RegisterFormat(f Format) {
+	if f.Name == "" || f.Write == nil {
+		panic("tbdiags: RegisterFormat requires a name and a Write function")
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	if _, exists := formats[f.Name]; exists {
+		panic(fmt.Sprintf("tbdiags: format %q is already registered", f.Name))
+	}
+	formats[f.Name] = f
+}
+
+// LookupFormat returns the registered format with the given name, or false
+// if there is none.
+func LookupFormat(name string) (Format, bool) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	f, ok := formats[name]
+	return f, ok
+}
+
+// AvailableFormats returns all of the registered formats, sorted by name.
+func AvailableFormats() []Format {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	ret := make([]Format, 0, len(formats))
+	for _, f := range formats {
+		ret = append(ret, f)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
@@ -0,0 +1,55 @@
+package tbdiags
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestRegisterFormat(t *testing.T) {
+	RegisterFormat(Format{
+		Name:        "test-count",
+		Description: "Count of diagnostics",
+		ContentType: "text/plain",
+		Write: func(w io.Writer, diags Diagnostics) error {
+			_, err := fmt.Fprintf(w, "%d", len(diags))
+			return err
+		},
+	})
+	defer func() {
+		formatsMu.Lock()
+		delete(formats, "test-count")
+		formatsMu.Unlock()
+	}()
+
+	var names []string
+	for _, f := range AvailableFormats() {
+		names = append(names, f.Name)
+	}
+	if want := []string{"json", "test-count", "text"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("wrong formats\ngot:  %#v\nwant: %#v", names, want)
+	}
+
+	f, ok := LookupFormat("test-count")
+	if !ok {
+		t.Fatalf("format not found")
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf, Diagnostics{Sourceless(Error, "Bad thing", "")}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "1"; got != want {
+		t.Errorf("wrong output %q; want %q", got, want)
+	}
+
+	t.Run("duplicate", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("registering a duplicate format did not panic")
+			}
+		}()
+		RegisterFormat(Format{Name: "json", Write: f.Write})
+	})
+}
@@ -0,0 +1,71 @@
+package tbdiags
+
+import (
+	"strings"
+)
+
+// GroupingSink is implemented by sinks that support named groups, such as
+// the phases of an operation, which allow renderers to show a header for
+// each group and machine-readable formats to nest diagnostics by group
+// without the producer needing to sort them.
+type GroupingSink interface {
+	Sink
+
+	// BeginGroup opens a group with the given name, nested inside any
+	// groups that are already open. All diagnostics reported until the
+	// matching call to EndGroup belong to the group.
+	BeginGroup(name string)
+
+	// EndGroup closes the most recently opened group that is still open.
+	EndGroup()
+}
+
+// DiagnosticGroup is an optional interface implemented by diagnostics that
+// were reported inside one or more groups of a GroupingSink.
+//
+// Renderer precedes these diagnostics with a heading whenever the group
+// changes, such as "=== phase: plan ===", and Diagnostics.MarshalJSON
+// encodes the names of the groups, outermost first, as the "group" property.
+type DiagnosticGroup interface {
+	// Group returns the names of the groups the diagnostic belongs to,
+	// outermost first.
+	Group() []string
+}
+
+// GroupOf returns the names of the groups the given diagnostic belongs to,
+// outermost first, or nil if it doesn't implement DiagnosticGroup.
+func GroupOf(diag Diagnostic) []string {
+	var ret []string
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		g, ok := diag.(DiagnosticGroup)
+		if ok {
+			ret = g.Group()
+		}
+		return ok
+	})
+	return ret
+}
+
+// groupHeading returns the heading that Renderer shows before the
+// diagnostics of the given group.
+func groupHeading(group []string) string {
+	if len(group) == 0 {
+		return "(no group)"
+	}
+	return strings.Join(group, " > ")
+}
+
+type withGroup struct {
+	Diagnostic
+	group []string
+}
+
+func (d withGroup) Group() []string {
+	// The collector's group stacks can share a backing array, so the
+	// capacity is limited to stop callers appending into it.
+	return d.group[:len(d.group):len(d.group)]
+}
+
+func (d withGroup) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,136 @@
+package tbdiags
+
+import (
+	"sync"
+	"time"
+)
+
+// Histogram is a Sink that counts the diagnostics it receives in fixed-width
+// time buckets, retaining only a limited number of the most recent buckets,
+// so that long-running programs can show trends in diagnostics on a
+// dashboard without exporting them to an external time series database.
+type Histogram struct {
+	width time.Duration
+	keep  int
+	key   func(Diagnostic) string
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets []HistogramBucket
+}
+
+var _ Sink = (*Histogram)(nil)
+
+// HistogramBucket is the diagnostic counts for a single time bucket of a
+// Histogram.
+type HistogramBucket struct {
+	// Start is the start time of the bucket, which is a multiple of the
+	// histogram's bucket width.
+	Start time.Time
+
+	Counts map[HistogramKey]int
+}
+
+// HistogramKey is the key under which a Histogram counts diagnostics.
+type HistogramKey struct {
+	Severity Severity
+	Key      string
+}
+
+// NewHistogram returns a Histogram with buckets of the given width, such
+// as time.Minute, which retains the given number of most recent buckets.
+//
+// Diagnostics are counted by severity and by the string returned by the
+// given key function. If the key function is nil, diagnostics are counted
+// by their code, or by their summary if they have no code.
+//
+// NewHistogram panics if keep is less than one, since a histogram must
+// retain at least the current bucket.
+func NewHistogram(width time.Duration, keep int, key func(Diagnostic) string) *Histogram {
+	if keep < 1 {
+		panic("tbdiags: NewHistogram requires at least one bucket")
+	}
+	if key == nil {
+		key = func(diag Diagnostic) string {
+			desc := diag.Description()
+			if desc.Code != "" {
+				return desc.Code
+			}
+			return desc.Summary
+		}
+	}
+	return &Histogram{
+		width: width,
+		keep:  keep,
+		key:   key,
+		now:   time.Now,
+	}
+}
+
+// Report implements Sink.
+func (h *Histogram) Report(diags Diagnostics) {
+	if len(diags) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start := h.now().Truncate(h.width)
+	h.prune(start)
+	if len(h.buckets) == 0 || !h.buckets[len(h.buckets)-1].Start.Equal(start) {
+		h.buckets = append(h.buckets, HistogramBucket{
+			Start:  start,
+			Counts: make(map[HistogramKey]int),
+		})
+	}
+	counts := h.buckets[len(h.buckets)-1].Counts
+	for _, diag := range diags {
+		counts[HistogramKey{diag.Severity(), h.key(diag)}]++
+	}
+}
+
+// Buckets returns a copy of the buckets that are currently within the
+// histogram's window, oldest first. Buckets in which no diagnostics were
+// reported are omitted.
+func (h *Histogram) Buckets() []HistogramBucket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.prune(h.now().Truncate(h.width))
+	ret := make([]HistogramBucket, len(h.buckets))
+	for i, bucket := range h.buckets {
+		counts := make(map[HistogramKey]int, len(bucket.Counts))
+		for k, v := range bucket.Counts {
+			counts[k] = v
+		}
+		ret[i] = HistogramBucket{
+			Start:  bucket.Start,
+			Counts: counts,
+		}
+	}
+	return ret
+}
+
+// Totals returns the total counts across all of the buckets that are
+// currently within the histogram's window.
+func (h *Histogram) Totals() map[HistogramKey]int {
+	ret := make(map[HistogramKey]int)
+	for _, bucket := range h.Buckets() {
+		for k, v := range bucket.Counts {
+			ret[k] += v
+		}
+	}
+	return ret
+}
+
+// prune discards any buckets that are outside of the window ending with
+// the bucket starting at the given time. The caller must hold h.mu.
+func (h *Histogram) prune(current time.Time) {
+	oldest := current.Add(-h.width * time.Duration(h.keep-1))
+	i := 0
+	for i < len(h.buckets) && h.buckets[i].Start.Before(oldest) {
+		i++
+	}
+	h.buckets = h.buckets[i:]
+}
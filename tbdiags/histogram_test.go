@@ -0,0 +1,67 @@
+package tbdiags
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 30, 0, time.UTC)
+	h := NewHistogram(time.Minute, 2, nil)
+	h.now = func() time.Time { return now }
+
+	warn := Sourceless(Warning, "Dubious thing", "")
+	fail := Sourceless(Error, "Bad thing", "")
+
+	h.Report(Diagnostics{warn, fail})
+	now = now.Add(time.Minute)
+	h.Report(Diagnostics{warn})
+
+	buckets := h.Buckets()
+	if len(buckets) != 2 {
+		t.Fatalf("wrong number of buckets %d; want 2", len(buckets))
+	}
+	if got, want := buckets[0].Start, time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("wrong first bucket start %s; want %s", got, want)
+	}
+	totals := h.Totals()
+	if got, want := totals[HistogramKey{Warning, "Dubious thing"}], 2; got != want {
+		t.Errorf("wrong warning total %d; want %d", got, want)
+	}
+	if got, want := totals[HistogramKey{Error, "Bad thing"}], 1; got != want {
+		t.Errorf("wrong error total %d; want %d", got, want)
+	}
+
+	// Moving on another minute pushes the first bucket out of the window.
+	now = now.Add(time.Minute)
+	totals = h.Totals()
+	if got, want := totals[HistogramKey{Warning, "Dubious thing"}], 1; got != want {
+		t.Errorf("wrong warning total after expiry %d; want %d", got, want)
+	}
+	if got, want := totals[HistogramKey{Error, "Bad thing"}], 0; got != want {
+		t.Errorf("wrong error total after expiry %d; want %d", got, want)
+	}
+}
+
+func TestHistogramCodes(t *testing.T) {
+	h := NewHistogram(time.Minute, 1, nil)
+	h.Report(Diagnostics{
+		WithCode(Sourceless(Warning, "Unused variable \"a\"", ""), "TB2001"),
+		WithCode(Sourceless(Warning, "Unused variable \"b\"", ""), "TB2001"),
+		Sourceless(Warning, "Dubious thing", ""),
+	})
+	totals := h.Totals()
+	if got, want := totals[HistogramKey{Warning, "TB2001"}], 2; got != want {
+		t.Errorf("wrong total for the code %d; want %d", got, want)
+	}
+	if got, want := totals[HistogramKey{Warning, "Dubious thing"}], 1; got != want {
+		t.Errorf("wrong total for the summary %d; want %d", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic for a histogram without buckets")
+		}
+	}()
+	NewHistogram(time.Minute, 0, nil)
+}
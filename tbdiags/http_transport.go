@@ -0,0 +1,71 @@
+//go:build !tinygo && !tbdiags_minimal
+// +build !tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPTransport is a Transport that sends each batch of diagnostics as the
+// JSON body of a POST request, in the format produced by
+// Diagnostics.MarshalJSON. Any response status other than 2xx is an error.
+//
+// HTTPTransport is not available when building with TinyGo or with the
+// tbdiags_minimal build tag, which exclude the parts of this package that
+// depend on the net/http package.
+type HTTPTransport struct {
+	URL string
+
+	// Header is additional headers to send with each request, such as for
+	// authentication.
+	Header http.Header
+
+	// Client is the client to send requests with. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+}
+
+var _ Transport = (*HTTPTransport)(nil)
+
+// maxDiscardedResponse is the maximum number of bytes of a response body that
+// Send reads before closing it. Larger bodies are left unread, at the cost of
+// the connection not being reused.
+const maxDiscardedResponse = 64 << 10
+
+// Send implements Transport.
+func (t *HTTPTransport) Send(ctx context.Context, diags Diagnostics) error {
+	body, err := diags.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", t.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, vs := range t.Header {
+		req.Header[k] = vs
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := t.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Reading the rest of the body, up to a limit, allows the client to
+	// reuse the connection for the next batch.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxDiscardedResponse))
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response from %s: %s", t.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+//go:build !tinygo && !tbdiags_minimal
+// +build !tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	var got []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	transport := &HTTPTransport{
+		URL:    server.URL,
+		Header: http.Header{"Authorization": {"Bearer abc"}},
+	}
+	err := transport.Send(context.Background(), Diagnostics{Sourceless(Error, "Bad thing", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0]["summary"] != "Bad thing" {
+		t.Errorf("wrong request body %#v", got)
+	}
+
+	transport.Header = nil
+	if err := transport.Send(context.Background(), nil); err == nil {
+		t.Errorf("no error for unauthorized request")
+	}
+}
+
+func TestHTTPTransportReusesConnection(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The body is larger than the client buffers, so at least with
+		// older versions of Go the connection can only be reused if Send
+		// reads it.
+		w.Write([]byte(strings.Repeat(" ", 32<<10) + `{"accepted":true}`))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	transport := &HTTPTransport{URL: server.URL, Client: server.Client()}
+	for i := 0; i < 3; i++ {
+		if err := transport.Send(context.Background(), Diagnostics{Sourceless(Error, "Bad thing", "")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 1 {
+		t.Errorf("made %d connections; want 1", conns)
+	}
+}
@@ -0,0 +1,64 @@
+//go:build go1.23
+// +build go1.23
+
+package tbdiags
+
+import (
+	"iter"
+)
+
+// All returns an iterator over the diagnostics in the receiver, in order.
+func (diags Diagnostics) All() iter.Seq[Diagnostic] {
+	return func(yield func(Diagnostic) bool) {
+		for _, diag := range diags {
+			if !yield(diag) {
+				return
+			}
+		}
+	}
+}
+
+// Where returns an iterator over the diagnostics in the receiver for which
+// the given function returns true, in order.
+//
+// Unlike building a filtered Diagnostics, iterating over the result doesn't
+// allocate, which matters when working with very large diagnostics lists.
+func (diags Diagnostics) Where(match func(Diagnostic) bool) iter.Seq[Diagnostic] {
+	return func(yield func(Diagnostic) bool) {
+		for _, diag := range diags {
+			if match(diag) && !yield(diag) {
+				return
+			}
+		}
+	}
+}
+
+// OfSeverity returns an iterator over the diagnostics in the receiver that
+// have the given severity, in order.
+func (diags Diagnostics) OfSeverity(severity Severity) iter.Seq[Diagnostic] {
+	return diags.Where(func(diag Diagnostic) bool {
+		return diag.Severity() == severity
+	})
+}
+
+// Errors returns an iterator over the diagnostics in the receiver that are
+// errors, including fatal errors, as for HasErrors.
+func (diags Diagnostics) Errors() iter.Seq[Diagnostic] {
+	return diags.Where(func(diag Diagnostic) bool {
+		return diag.Severity().isError()
+	})
+}
+
+// Warnings returns an iterator over the warning diagnostics in the receiver.
+func (diags Diagnostics) Warnings() iter.Seq[Diagnostic] {
+	return diags.OfSeverity(Warning)
+}
+
+// InFile returns an iterator over the diagnostics in the receiver whose
+// subject is in the file with the given name, in order.
+func (diags Diagnostics) InFile(filename string) iter.Seq[Diagnostic] {
+	return diags.Where(func(diag Diagnostic) bool {
+		subject := subjectOf(diag)
+		return subject != nil && subject.Kind == SubjectFile && subject.Filename == filename
+	})
+}
@@ -0,0 +1,61 @@
+//go:build go1.23
+// +build go1.23
+
+package tbdiags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticsIterators(t *testing.T) {
+	inFile := func(severity Severity, summary, filename string) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: severity, summary: summary},
+			subject:        &SourceRange{Filename: filename},
+		}
+	}
+	diags := Diagnostics{
+		inFile(Error, "A", "a.tb"),
+		inFile(Warning, "B", "b.tb"),
+		Sourceless(Warning, "C", ""),
+		inFile(Error, "D", "b.tb"),
+		Sourceless(Fatal, "E", ""),
+	}
+
+	summaries := func(seq func(func(Diagnostic) bool)) []string {
+		var ret []string
+		for diag := range seq {
+			ret = append(ret, diag.Description().Summary)
+		}
+		return ret
+	}
+
+	tests := map[string]struct {
+		Got  []string
+		Want []string
+	}{
+		"All":      {summaries(diags.All()), []string{"A", "B", "C", "D", "E"}},
+		"Errors":   {summaries(diags.Errors()), []string{"A", "D", "E"}},
+		"Warnings": {summaries(diags.Warnings()), []string{"B", "C"}},
+		"InFile":   {summaries(diags.InFile("b.tb")), []string{"B", "D"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if !reflect.DeepEqual(test.Got, test.Want) {
+				t.Errorf("wrong result\ngot:  %#v\nwant: %#v", test.Got, test.Want)
+			}
+		})
+	}
+
+	t.Run("early exit", func(t *testing.T) {
+		count := 0
+		for range diags.All() {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("iterated %d times after break; want 1", count)
+		}
+	})
+}
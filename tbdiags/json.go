@@ -0,0 +1,260 @@
+package tbdiags
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MarshalJSON returns a JSON representation of the diagnostics, as an array
+// of objects with the following properties:
+//
+//   - "severity": "fatal", "error", "warning" or "hint".
+//   - "summary": the summary, which is always present.
+//   - "detail", "address", "code", "help_url": the corresponding
+//     Description fields, if set.
+//   - "subject", "context": the corresponding Source ranges, if set, as
+//     objects with "filename", "start" and "end" properties and an optional
+//     "kind" for subjects that are not files ("env", "flag", "object" or
+//     "archive"). "start" and "end" are objects with "line", "column" and
+//     "byte" properties. Ranges that are not PrecisionExact have a
+//     "precision" property of "line", "file" or "none", and their positions
+//     have only a "line" property for "line" and are omitted otherwise.
+//
+// Diagnostics that implement the optional interfaces of this package have
+// additional properties, as described by each interface, such as "origin"
+// for DiagnosticOrigin.
+//
+// Apart from "attributes", whose properties are encoded in order of name,
+// the output contains no objects with variable sets of keys, so the same
+// diagnostics in the same order always produce byte-identical output.
+// Lines and columns are numbered from one. Use MarshalJSONWith to choose
+// other conventions or to sort the diagnostics.
+func (diags Diagnostics) MarshalJSON() ([]byte, error) {
+	return diags.MarshalJSONWith(JSONOptions{})
+}
+
+// JSONOptions customizes the output of Diagnostics.MarshalJSONWith and the
+// input of Diagnostics.UnmarshalJSONWith.
+type JSONOptions struct {
+	// Positions specifies the conventions for line and column numbers.
+	Positions PositionOptions
+
+	// Sort causes the diagnostics to be encoded in a deterministic order,
+	// without modifying the receiver, so that the output doesn't depend on
+	// the order in which concurrent producers reported them. The order is
+	// that of Diagnostics.Sort, with any ties broken using MatchKey and
+	// then the related information. It has no effect on decoding.
+	Sort bool
+}
+
+// MarshalJSONWith is like MarshalJSON except that it's customized by the
+// given options.
+func (diags Diagnostics) MarshalJSONWith(opts JSONOptions) ([]byte, error) {
+	if opts.Sort {
+		diags = diags.sortedDeterministically(opts.Positions)
+	}
+	ret := make([]jsonDiagnostic, len(diags))
+	for i, diag := range diags {
+		ret[i] = newJSONDiagnostic(diag, opts.Positions)
+	}
+	return json.Marshal(ret)
+}
+
+// sortedDeterministically returns a sorted copy of the receiver, in an order
+// that depends only on the diagnostics and not on their original order.
+//
+// Diagnostics with the same MatchKey can still differ, such as in the
+// numbers that StableMessage removes from the messages of errors, so ties
+// are broken using their JSON, as encoded with the given options.
+func (diags Diagnostics) sortedDeterministically(positions PositionOptions) Diagnostics {
+	sorted := keyedDiagnostics{
+		diags: make(sortDiagnostics, len(diags)),
+		keys:  make([]string, len(diags)),
+	}
+	for i, diag := range diags {
+		sorted.diags[i] = diag
+		encoded, _ := json.Marshal(newJSONDiagnostic(diag, positions))
+		sorted.keys[i] = MatchKey(diag) + relatedKey(diag) + "\x00" + string(encoded)
+	}
+	sort.Stable(sorted)
+	return Diagnostics(sorted.diags)
+}
+
+// keyedDiagnostics is an implementation of sort.Interface that orders
+// diagnostics as sortDiagnostics does, breaking ties using the given keys.
+type keyedDiagnostics struct {
+	diags sortDiagnostics
+	keys  []string
+}
+
+func (kd keyedDiagnostics) Len() int {
+	return len(kd.diags)
+}
+
+func (kd keyedDiagnostics) Less(i, j int) bool {
+	switch {
+	case kd.diags.Less(i, j):
+		return true
+	case kd.diags.Less(j, i):
+		return false
+	default:
+		return kd.keys[i] < kd.keys[j]
+	}
+}
+
+func (kd keyedDiagnostics) Swap(i, j int) {
+	kd.diags.Swap(i, j)
+	kd.keys[i], kd.keys[j] = kd.keys[j], kd.keys[i]
+}
+
+type jsonDiagnostic struct {
+	Severity    string                 `json:"severity"`
+	Summary     string                 `json:"summary"`
+	Detail      string                 `json:"detail,omitempty"`
+	Address     string                 `json:"address,omitempty"`
+	Code        string                 `json:"code,omitempty"`
+	HelpURL     string                 `json:"help_url,omitempty"`
+	Subject     *jsonRange             `json:"subject,omitempty"`
+	Context     *jsonRange             `json:"context,omitempty"`
+	ValidValues []string               `json:"valid_values,omitempty"`
+	Origin      string                 `json:"origin,omitempty"`
+	Related     []jsonRelated          `json:"related,omitempty"`
+	Fixes       []jsonFix              `json:"fixes,omitempty"`
+	Emitter     *jsonEmitter           `json:"emitter,omitempty"`
+	Category    string                 `json:"category,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Group       []string               `json:"group,omitempty"`
+	Timestamp   string                 `json:"timestamp,omitempty"`
+	ReportedAt  string                 `json:"reported_at,omitempty"`
+	Provenance  []jsonHop              `json:"provenance,omitempty"`
+	Children    []jsonDiagnostic       `json:"children,omitempty"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+}
+
+type jsonRelated struct {
+	Message string     `json:"message"`
+	Range   *jsonRange `json:"range,omitempty"`
+}
+
+type jsonFix struct {
+	Message string     `json:"message"`
+	Edits   []jsonEdit `json:"edits"`
+}
+
+type jsonEdit struct {
+	Range   *jsonRange `json:"range"`
+	NewText string     `json:"new_text"`
+}
+
+type jsonEmitter struct {
+	Component string `json:"component"`
+	Version   string `json:"version,omitempty"`
+}
+
+type jsonHop struct {
+	Host      string `json:"host,omitempty"`
+	Process   string `json:"process,omitempty"`
+	Component string `json:"component,omitempty"`
+	Time      string `json:"time,omitempty"`
+}
+
+type jsonRange struct {
+	Filename  string   `json:"filename"`
+	Kind      string   `json:"kind,omitempty"`
+	Precision string   `json:"precision,omitempty"`
+	Start     *jsonPos `json:"start,omitempty"`
+	End       *jsonPos `json:"end,omitempty"`
+}
+
+type jsonPos struct {
+	Line   int  `json:"line"`
+	Column *int `json:"column,omitempty"`
+	Byte   *int `json:"byte,omitempty"`
+}
+
+func newJSONPos(pos SourcePos, precision Precision, opts PositionOptions) *jsonPos {
+	if precision != PrecisionExact && precision != PrecisionLine {
+		return nil
+	}
+	pos = opts.encode(pos)
+	ret := &jsonPos{Line: pos.Line}
+	if precision == PrecisionExact {
+		ret.Column = &pos.Column
+		ret.Byte = &pos.Byte
+	}
+	return ret
+}
+
+func newJSONDiagnostic(diag Diagnostic, opts PositionOptions) jsonDiagnostic {
+	desc := diag.Description()
+	src := diag.Source()
+	ret := jsonDiagnostic{
+		Severity:    strings.ToLower(diag.Severity().String()),
+		Summary:     desc.Summary,
+		Detail:      desc.Detail,
+		Address:     desc.Address,
+		Code:        desc.Code,
+		HelpURL:     desc.HelpURL,
+		Subject:     newJSONRange(src.Subject, opts),
+		Context:     newJSONRange(src.Context, opts),
+		ValidValues: ValidValues(diag),
+		Origin:      OriginOf(diag),
+		Category:    CategoryOf(diag).String(),
+		Tags:        tagNames(TagsOf(diag)),
+		Group:       GroupOf(diag),
+		ReportedAt:  ReportedAt(diag),
+		Attributes:  AttributesOf(diag),
+	}
+	for _, info := range RelatedOf(diag) {
+		rng := info.Range
+		ret.Related = append(ret.Related, jsonRelated{
+			Message: info.Message,
+			Range:   newJSONRange(&rng, opts),
+		})
+	}
+	for _, fix := range FixesOf(diag) {
+		jf := jsonFix{Message: fix.Message, Edits: make([]jsonEdit, len(fix.Edits))}
+		for i, edit := range fix.Edits {
+			rng := edit.Range
+			jf.Edits[i] = jsonEdit{Range: newJSONRange(&rng, opts), NewText: edit.NewText}
+		}
+		ret.Fixes = append(ret.Fixes, jf)
+	}
+	if emitter, ok := EmitterOf(diag); ok {
+		ret.Emitter = &jsonEmitter{Component: emitter.Component, Version: emitter.Version}
+	}
+	if ts, ok := TimestampOf(diag); ok {
+		ret.Timestamp = ts.UTC().Format(time.RFC3339Nano)
+	}
+	for _, child := range ChildrenOf(diag) {
+		ret.Children = append(ret.Children, newJSONDiagnostic(child, opts))
+	}
+	for _, hop := range ProvenanceOf(diag) {
+		jh := jsonHop{Host: hop.Host, Process: hop.Process, Component: hop.Component}
+		if !hop.Time.IsZero() {
+			jh.Time = hop.Time.UTC().Format(time.RFC3339Nano)
+		}
+		ret.Provenance = append(ret.Provenance, jh)
+	}
+	return ret
+}
+
+func newJSONRange(rng *SourceRange, opts PositionOptions) *jsonRange {
+	if rng == nil {
+		return nil
+	}
+	ret := &jsonRange{
+		Filename: rng.Filename,
+		Start:    newJSONPos(rng.Start, rng.Precision, opts),
+		End:      newJSONPos(rng.End, rng.Precision, opts),
+	}
+	if rng.Precision != PrecisionExact {
+		ret.Precision = rng.Precision.String()
+	}
+	if rng.Kind != SubjectFile {
+		ret.Kind = rng.Kind.String()
+	}
+	return ret
+}
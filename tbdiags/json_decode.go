@@ -0,0 +1,258 @@
+package tbdiags
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the receiver with the
+// diagnostics represented by the given JSON, which must be in the format
+// produced by MarshalJSON.
+//
+// The result consists of ordinary diagnostics, so they can be appended,
+// sorted, rendered and encoded again exactly like diagnostics produced
+// locally. Each one implements the optional interfaces, such as
+// DiagnosticFixes and DiagnosticChildren, that correspond to the properties
+// present in its JSON, and encoding the result with MarshalJSON reproduces
+// the original JSON. Numbers in attributes are decoded as json.Number so
+// that they are reproduced exactly.
+//
+// Diagnostics decoded from JSON have no cause, so CauseOf returns nil for
+// them even if they were originally created from errors.
+func (diags *Diagnostics) UnmarshalJSON(data []byte) error {
+	return diags.UnmarshalJSONWith(data, JSONOptions{})
+}
+
+// UnmarshalJSONWith is like UnmarshalJSON except that the JSON must be in
+// the format produced by MarshalJSONWith with the same options, such as
+// with zero-based line and column numbers.
+func (diags *Diagnostics) UnmarshalJSONWith(data []byte, opts JSONOptions) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var raw []jsonDiagnostic
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*diags = nil
+		return nil
+	}
+	ret := make(Diagnostics, len(raw))
+	for i, jd := range raw {
+		diag, err := jd.diagnostic(opts.Positions)
+		if err != nil {
+			return fmt.Errorf("diagnostic %d: %s", i, err)
+		}
+		ret[i] = diag
+	}
+	*diags = ret
+	return nil
+}
+
+// diagnostic returns the diagnostic that the receiver represents, with
+// positions in the given conventions, which is the inverse of
+// newJSONDiagnostic.
+func (jd jsonDiagnostic) diagnostic(opts PositionOptions) (Diagnostic, error) {
+	severity, err := ParseSeverity(jd.Severity)
+	if err != nil {
+		return nil, err
+	}
+	base := diagnosticBase{
+		severity: severity,
+		summary:  jd.Summary,
+		detail:   jd.Detail,
+		address:  jd.Address,
+		code:     jd.Code,
+		helpURL:  jd.HelpURL,
+	}
+	subject, err := jd.Subject.sourceRange(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject: %s", err)
+	}
+	context, err := jd.Context.sourceRange(opts)
+	if err != nil {
+		return nil, fmt.Errorf("invalid context: %s", err)
+	}
+	var diag Diagnostic = base
+	if subject != nil || context != nil {
+		diag = sourcedDiagnostic{
+			diagnosticBase: base,
+			subject:        subject,
+			context:        context,
+		}
+	}
+
+	if jd.ValidValues != nil {
+		diag = WithValidValues(diag, jd.ValidValues)
+	}
+	if jd.Origin != "" {
+		diag = WithOrigin(diag, jd.Origin)
+	}
+	if len(jd.Related) > 0 {
+		related := make([]RelatedInfo, len(jd.Related))
+		for i, jr := range jd.Related {
+			rng, err := jr.Range.sourceRange(opts)
+			if err != nil {
+				return nil, fmt.Errorf("invalid related range: %s", err)
+			}
+			related[i].Message = jr.Message
+			if rng != nil {
+				related[i].Range = *rng
+			}
+		}
+		diag = WithRelated(diag, related...)
+	}
+	if len(jd.Fixes) > 0 {
+		fixes := make([]SuggestedFix, len(jd.Fixes))
+		for i, jf := range jd.Fixes {
+			fixes[i] = SuggestedFix{Message: jf.Message, Edits: make([]TextEdit, len(jf.Edits))}
+			for j, je := range jf.Edits {
+				rng, err := je.Range.sourceRange(opts)
+				if err != nil {
+					return nil, fmt.Errorf("invalid fix range: %s", err)
+				}
+				if rng != nil {
+					fixes[i].Edits[j].Range = *rng
+				}
+				fixes[i].Edits[j].NewText = je.NewText
+			}
+		}
+		diag = WithFixes(diag, fixes...)
+	}
+	if jd.Emitter != nil {
+		diag = WithEmitter(diag, Emitter{Component: jd.Emitter.Component, Version: jd.Emitter.Version})
+	}
+	if jd.Category != "" {
+		category, err := parseCategory(jd.Category)
+		if err != nil {
+			return nil, err
+		}
+		diag = WithCategory(diag, category)
+	}
+	if len(jd.Tags) > 0 {
+		tags := make([]Tag, len(jd.Tags))
+		for i, name := range jd.Tags {
+			if tags[i], err = parseTag(name); err != nil {
+				return nil, err
+			}
+		}
+		diag = WithTags(diag, tags...)
+	}
+	if jd.Group != nil {
+		diag = withGroup{diag, jd.Group}
+	}
+	if jd.Timestamp != "" {
+		ts, err := time.Parse(time.RFC3339Nano, jd.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp: %s", err)
+		}
+		diag = WithTimestamp(diag, ts)
+	}
+	if jd.ReportedAt != "" {
+		diag = withReportedAt{diag, jd.ReportedAt}
+	}
+	if len(jd.Provenance) > 0 {
+		hops := make([]Hop, len(jd.Provenance))
+		for i, jh := range jd.Provenance {
+			hops[i] = Hop{Host: jh.Host, Process: jh.Process, Component: jh.Component}
+			if jh.Time != "" {
+				if hops[i].Time, err = time.Parse(time.RFC3339Nano, jh.Time); err != nil {
+					return nil, fmt.Errorf("invalid provenance time: %s", err)
+				}
+			}
+		}
+		diag = withProvenance{diag, hops}
+	}
+	if len(jd.Children) > 0 {
+		children := make(Diagnostics, len(jd.Children))
+		for i, jc := range jd.Children {
+			if children[i], err = jc.diagnostic(opts); err != nil {
+				return nil, fmt.Errorf("child %d: %s", i, err)
+			}
+		}
+		diag = WithChildren(diag, children)
+	}
+	if jd.Attributes != nil {
+		diag = WithAttributes(diag, jd.Attributes)
+	}
+	return diag, nil
+}
+
+// sourceRange returns the range that the receiver represents, with
+// positions in the given conventions, which is the inverse of newJSONRange,
+// or nil if the receiver is nil.
+func (jr *jsonRange) sourceRange(opts PositionOptions) (*SourceRange, error) {
+	if jr == nil {
+		return nil, nil
+	}
+	ret := &SourceRange{Filename: jr.Filename}
+	switch jr.Kind {
+	case "":
+		ret.Kind = SubjectFile
+	case "env":
+		ret.Kind = SubjectEnvVar
+	case "flag":
+		ret.Kind = SubjectFlag
+	case "object":
+		ret.Kind = SubjectObjectKey
+	case "archive":
+		ret.Kind = SubjectArchiveEntry
+	default:
+		return nil, fmt.Errorf("invalid kind %q", jr.Kind)
+	}
+	switch jr.Precision {
+	case "":
+		ret.Precision = PrecisionExact
+	case "line":
+		ret.Precision = PrecisionLine
+	case "file":
+		ret.Precision = PrecisionFile
+	case "none":
+		ret.Precision = PrecisionNone
+	default:
+		return nil, fmt.Errorf("invalid precision %q", jr.Precision)
+	}
+	ret.Start = jr.Start.sourcePos(opts)
+	ret.End = jr.End.sourcePos(opts)
+	return ret, nil
+}
+
+// sourcePos returns the position that the receiver represents, converted
+// from the given conventions, with zero for any fields it omits, which is
+// the inverse of newJSONPos.
+func (jp *jsonPos) sourcePos(opts PositionOptions) SourcePos {
+	if jp == nil {
+		return SourcePos{}
+	}
+	pos := SourcePos{Line: opts.LineBase.decode(jp.Line)}
+	if jp.Column != nil {
+		pos.Column = opts.ColumnBase.decode(*jp.Column)
+	}
+	if jp.Byte != nil {
+		pos.Byte = *jp.Byte
+	}
+	return pos
+}
+
+// parseCategory returns the category with the given name, as returned by
+// Category.String.
+func parseCategory(name string) (Category, error) {
+	for c := CategoryDeprecation; c <= CategorySecurity; c++ {
+		if c.String() == name {
+			return c, nil
+		}
+	}
+	return CategoryNone, fmt.Errorf("invalid category %q", name)
+}
+
+// parseTag returns the tag with the given name, as returned by Tag.String.
+func parseTag(name string) (Tag, error) {
+	for t := TagUnnecessary; t <= TagExperimental; t++ {
+		if t.String() == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid tag %q", name)
+}
@@ -0,0 +1,124 @@
+package tbdiags
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiagnosticsUnmarshalJSON(t *testing.T) {
+	rng := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 2, Column: 3, Byte: 10},
+		End:      SourcePos{Line: 2, Column: 8, Byte: 15},
+	}
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	child := Sourceless(Warning, "Child problem", "")
+	var diag Diagnostic = sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{severity: Error, summary: "Bad name", detail: "Names must be short.", code: "TB1001"},
+		subject:        &rng,
+	}
+	diag = WithFixes(diag, SuggestedFix{Message: "Shorten it", Edits: []TextEdit{{Range: rng, NewText: "x"}}})
+	diag = WithTags(diag, TagDeprecated)
+	diag = WithTimestamp(diag, ts)
+	diag = WithChildren(diag, Diagnostics{child})
+	diag = WithAttributes(diag, map[string]interface{}{"count": 12345678901234567})
+	diags := Diagnostics{diag, Sourceless(Hint, "Consider something", "")}
+
+	src, err := json.Marshal(diags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Diagnostics
+	if err := json.Unmarshal(src, &got); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("decoded %d diagnostics; want 2", len(got))
+	}
+	again, err := json.Marshal(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(src) {
+		t.Errorf("wrong result of encoding again\ngot:  %s\nwant: %s", again, src)
+	}
+
+	// The decoded diagnostics must work with everything else in the
+	// package, not only with the encoder.
+	if subject := got[0].Source().Subject; subject == nil || *subject != rng {
+		t.Errorf("wrong subject %v", subject)
+	}
+	if got, ok := TimestampOf(got[0]); !ok || !got.Equal(ts) {
+		t.Errorf("wrong timestamp %s", got)
+	}
+	if !HasTag(got[0], TagDeprecated) || len(FixesOf(got[0])) != 1 || len(ChildrenOf(got[0])) != 1 {
+		t.Errorf("decoded diagnostic is missing optional interfaces")
+	}
+	if !got.HasErrors() {
+		t.Errorf("decoded diagnostics have no errors")
+	}
+	rendered := (&Renderer{}).RenderString(got.Append(Sourceless(Error, "Local", "")))
+	for _, want := range []string{"Error [TB1001]: Bad name", "Hint: Consider something", "Error: Local"} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendering has no %q:\n%s", want, rendered)
+		}
+	}
+}
+
+func TestDiagnosticsUnmarshalJSONWith(t *testing.T) {
+	rng := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 2, Column: 3, Byte: 10},
+		End:      SourcePos{Line: 2, Column: 8, Byte: 15},
+	}
+	line := LineRange("main.tb", 4)
+	var diag Diagnostic = sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{severity: Error, summary: "Bad name"},
+		subject:        &rng,
+		context:        &line,
+	}
+	diag = WithRelated(diag, RelatedInfo{Message: "Defined here", Range: rng})
+	opts := JSONOptions{Positions: PositionOptions{LineBase: ZeroBased, ColumnBase: ZeroBased}}
+
+	src, err := Diagnostics{diag}.MarshalJSONWith(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), `"line":1,"column":2`) {
+		t.Fatalf("positions were not encoded zero-based: %s", src)
+	}
+	var got Diagnostics
+	if err := got.UnmarshalJSONWith(src, opts); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	source := got[0].Source()
+	if source.Subject == nil || *source.Subject != rng {
+		t.Errorf("wrong subject %#v", source.Subject)
+	}
+	if source.Context == nil || *source.Context != line {
+		t.Errorf("wrong context %#v", source.Context)
+	}
+	if related := RelatedOf(got[0]); len(related) != 1 || related[0].Range != rng {
+		t.Errorf("wrong related information %#v", related)
+	}
+}
+
+func TestDiagnosticsUnmarshalJSONErrors(t *testing.T) {
+	tests := map[string]string{
+		`[{"severity":"bad","summary":"x"}]`:                                 `diagnostic 0: invalid severity "bad"`,
+		`[{"severity":"error","summary":"x","subject":{"kind":"tape"}}]`:     `diagnostic 0: invalid subject: invalid kind "tape"`,
+		`[{"severity":"error","summary":"x","tags":["shiny"]}]`:              `diagnostic 0: invalid tag "shiny"`,
+		`[{"severity":"error","summary":"x","timestamp":"yesterday"}]`:       `diagnostic 0: invalid timestamp`,
+		`[{"severity":"error","summary":"x","children":[{"severity":"?"}]}]`: `diagnostic 0: child 0: invalid severity "?"`,
+		`{}`: `cannot unmarshal object`,
+	}
+	for src, want := range tests {
+		var diags Diagnostics
+		err := json.Unmarshal([]byte(src), &diags)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("wrong error for %s\ngot:  %v\nwant: %s", src, err, want)
+		}
+	}
+}
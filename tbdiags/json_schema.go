@@ -0,0 +1,60 @@
+package tbdiags
+
+import (
+	"fmt"
+)
+
+// SchemaResult is a single validation failure reported by a JSON Schema
+// validator, in the shape used by the "basic" output format of the JSON
+// Schema specification and by most validator libraries. Callers convert the
+// result type of their chosen validator to SchemaResult and then use
+// FromSchemaResults.
+type SchemaResult struct {
+	// InstanceLocation is a JSON Pointer to the part of the validated
+	// document that failed, such as "/resources/0/name". The empty string
+	// refers to the whole document.
+	InstanceLocation string
+
+	// Keyword is the schema keyword that failed, such as "required".
+	Keyword string
+
+	// Message is the validator's description of the failure.
+	Message string
+}
+
+// SchemaPositionMapper returns the source range of the value at the given
+// JSON Pointer within a validated document, or false if it is unknown.
+// Producers that parse documents with position information can supply one
+// to FromSchemaResults so that schema failures point at real source ranges.
+type SchemaPositionMapper func(instanceLocation string) (SourceRange, bool)
+
+// FromSchemaResults converts the given JSON Schema validation results into
+// error diagnostics.
+//
+// Each diagnostic's address is the result's instance location. If mapper is
+// not nil, it's used to find the subject of each diagnostic; otherwise, or
+// if the mapper doesn't know a location, the diagnostic has no source.
+func FromSchemaResults(results []SchemaResult, mapper SchemaPositionMapper) Diagnostics {
+	var diags Diagnostics
+	for _, result := range results {
+		detail := "The value does not conform to the schema."
+		if result.Keyword != "" {
+			detail = fmt.Sprintf("The value does not satisfy the schema's %q keyword.", result.Keyword)
+		}
+		diag := sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  result.Message,
+				detail:   detail,
+				address:  result.InstanceLocation,
+			},
+		}
+		if mapper != nil {
+			if rng, ok := mapper(result.InstanceLocation); ok {
+				diag.subject = &rng
+			}
+		}
+		diags = diags.Append(diag)
+	}
+	return diags
+}
@@ -0,0 +1,50 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestFromSchemaResults(t *testing.T) {
+	results := []SchemaResult{
+		{
+			InstanceLocation: "/resources/0",
+			Keyword:          "required",
+			Message:          `missing property "name"`,
+		},
+		{
+			InstanceLocation: "/version",
+			Message:          "value must be a string",
+		},
+	}
+	mapper := func(loc string) (SourceRange, bool) {
+		if loc != "/resources/0" {
+			return SourceRange{}, false
+		}
+		return SourceRange{
+			Filename: "config.json",
+			Start:    SourcePos{Line: 3, Column: 5, Byte: 20},
+			End:      SourcePos{Line: 6, Column: 6, Byte: 60},
+		}, true
+	}
+
+	diags := FromSchemaResults(results, mapper)
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2", len(diags))
+	}
+
+	got := (&Renderer{}).RenderString(diags)
+	want := `Error: missing property "name"
+  on config.json:3,5, in /resources/0
+
+The value does not satisfy the schema's "required" keyword.
+
+Error: value must be a string
+  in /version
+
+The value does not conform to the schema.
+
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
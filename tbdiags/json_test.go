@@ -0,0 +1,130 @@
+package tbdiags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiagnosticsMarshalJSON(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Warning, "Dubious thing", ""),
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  "Invalid token",
+				address:  "credentials",
+			},
+			subject: &SourceRange{
+				Filename: "TB_TOKEN",
+				Kind:     SubjectEnvVar,
+				Start:    SourcePos{Line: 1, Column: 1, Byte: 0},
+				End:      SourcePos{Line: 1, Column: 5, Byte: 4},
+			},
+		},
+	}
+
+	got, err := diags.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"severity":"warning","summary":"Dubious thing"},{"severity":"error","summary":"Invalid token","address":"credentials","subject":{"filename":"TB_TOKEN","kind":"env","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":5,"byte":4}}}]`
+	if withoutReportedAt(string(got)) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestDiagnosticsMarshalJSONLineOnly(t *testing.T) {
+	subject := LineRange("main.tb", 3)
+	diags := Diagnostics{
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  "Bad line",
+			},
+			subject: &subject,
+		},
+	}
+
+	got, err := diags.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"severity":"error","summary":"Bad line","subject":{"filename":"main.tb","precision":"line","start":{"line":3},"end":{"line":3}}}]`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestDiagnosticsMarshalJSONWith(t *testing.T) {
+	diags := Diagnostics{
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Error, summary: "Bad thing"},
+			subject: &SourceRange{
+				Filename: "main.tb",
+				Start:    SourcePos{Line: 1, Column: 1, Byte: 0},
+				End:      SourcePos{Line: 1, Column: 4, Byte: 3},
+			},
+		},
+	}
+	got, err := diags.MarshalJSONWith(JSONOptions{
+		Positions: PositionOptions{LineBase: ZeroBased, ColumnBase: ZeroBased},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"severity":"error","summary":"Bad thing","subject":{"filename":"main.tb","start":{"line":0,"column":0,"byte":0},"end":{"line":0,"column":3,"byte":3}}}]`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestDiagnosticsMarshalJSONFilePrecision(t *testing.T) {
+	rng := FileRange("main.tb")
+	diags := Diagnostics{
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Warning, summary: "Empty file"},
+			subject:        &rng,
+		},
+	}
+	got, err := diags.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"severity":"warning","summary":"Empty file","subject":{"filename":"main.tb","precision":"file"}}]`
+	if string(got) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestDiagnosticsMarshalJSONSorted(t *testing.T) {
+	a := WithValidValues(Sourceless(Error, "A", ""), []string{"x"})
+	b := Sourceless(Error, "B", "")
+	c := Sourceless(Warning, "C", "")
+
+	first, err := Diagnostics{a, b, c}.MarshalJSONWith(JSONOptions{Sort: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := Diagnostics{b, c, a}.MarshalJSONWith(JSONOptions{Sort: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("output depends on order\nfirst:  %s\nsecond: %s", first, second)
+	}
+
+	// Errors with the same StableMessage are still ordered consistently.
+	three := Diagnostics(nil).Append(errors.New("timed out after 3s"))[0]
+	five := Diagnostics(nil).Append(errors.New("timed out after 5s"))[0]
+	first, err = Diagnostics{three, five}.MarshalJSONWith(JSONOptions{Sort: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err = Diagnostics{five, three}.MarshalJSONWith(JSONOptions{Sort: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("output for errors depends on order\nfirst:  %s\nsecond: %s", first, second)
+	}
+}
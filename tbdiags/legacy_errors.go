@@ -0,0 +1,32 @@
+//go:build !tbdiags_stdliberrors
+// +build !tbdiags_stdliberrors
+
+package tbdiags
+
+import (
+	"github.com/hashicorp/errwrap"
+	"github.com/hashicorp/go-multierror"
+)
+
+// appendLegacyError appends the diagnostics for errors built using the
+// hashicorp/go-multierror and hashicorp/errwrap packages, which predate the
+// standard library's error wrapping, and returns false for any other error.
+//
+// Building with the tbdiags_stdliberrors build tag replaces this with a
+// version that recognizes no errors, so that programs that don't use those
+// packages need not depend on them.
+func appendLegacyError(diags Diagnostics, err error) (Diagnostics, bool) {
+	if multi, ok := err.(*multierror.Error); ok {
+		for _, err := range multi.Errors {
+			diags = append(diags, normalize(nativeError{err}))
+		}
+		return diags, true
+	}
+	if errwrap.ContainsType(err, Diagnostics(nil)) {
+		// If we have an errwrap wrapper with a Diagnostics hiding
+		// inside then we'll unpick it here to get access to the
+		// individual diagnostics.
+		return diags.Append(errwrap.GetType(err, Diagnostics(nil))), true
+	}
+	return diags, false
+}
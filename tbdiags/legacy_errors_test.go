@@ -0,0 +1,25 @@
+//go:build !tbdiags_stdliberrors
+// +build !tbdiags_stdliberrors
+
+package tbdiags
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+func TestDiagnosticsAppendMultierror(t *testing.T) {
+	var err error
+	err = multierror.Append(err, errors.New("first"), errors.New("second"))
+
+	var diags Diagnostics
+	diags = diags.Append(err)
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2", len(diags))
+	}
+	if got, want := diags[1].Description().Summary, "second"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+}
@@ -0,0 +1,54 @@
+package tbdiags
+
+import (
+	"fmt"
+	"sync"
+)
+
+// WarnLogger is the logging method used by SetLenientAppend, which is
+// implemented by hclog.Logger and by *slog.Logger.
+type WarnLogger interface {
+	Warn(msg string, args ...interface{})
+}
+
+var (
+	lenientAppendLogger WarnLogger
+	lenientAppendMu     sync.RWMutex
+)
+
+// SetLenientAppend enables or disables lenient mode for Diagnostics.Append
+// and Collector.Append. In lenient mode, values of types that can't be
+// converted to diagnostics are appended as warnings that describe them,
+// and logged to the given logger, rather than causing a panic. This is
+// intended for hosts of plugins, for which a mistake in a third-party
+// plugin must not crash the host. A nil logger disables lenient mode.
+//
+// A Collector with UnknownSeverity set uses that severity instead of
+// Warning, and also logs each value in lenient mode.
+//
+// Lenient mode is global to the program, so this is intended to be called
+// only by main packages, usually during startup.
+func SetLenientAppend(logger WarnLogger) {
+	lenientAppendMu.Lock()
+	lenientAppendLogger = logger
+	lenientAppendMu.Unlock()
+}
+
+// appendUnknownValue returns the diagnostic that appendValues appends for a
+// value of an unsupported type, given the severity requested by its caller,
+// or panics if there is none and lenient mode is disabled.
+func appendUnknownValue(item interface{}, severity Severity) Diagnostic {
+	lenientAppendMu.RLock()
+	logger := lenientAppendLogger
+	lenientAppendMu.RUnlock()
+	if logger == nil && severity == 0 {
+		panic(fmt.Errorf("can't construct diagnostic(s) from %T", item))
+	}
+	if logger != nil {
+		logger.Warn("unexpected value reported as a diagnostic", "type", fmt.Sprintf("%T", item))
+	}
+	if severity == 0 {
+		severity = Warning
+	}
+	return unknownValue(item, severity)
+}
@@ -0,0 +1,50 @@
+package tbdiags
+
+import (
+	"fmt"
+	"testing"
+)
+
+type recordingLogger []string
+
+func (l *recordingLogger) Warn(msg string, args ...interface{}) {
+	*l = append(*l, msg+fmt.Sprint(args...))
+}
+
+func TestSetLenientAppend(t *testing.T) {
+	var logged recordingLogger
+	SetLenientAppend(&logged)
+	defer SetLenientAppend(nil)
+
+	diags := Diagnostics(nil).Append(Sourceless(Error, "Real problem", ""), 42)
+	if len(diags) != 2 {
+		t.Fatalf("got %d diagnostics; want 2", len(diags))
+	}
+	if got := diags[1].Severity(); got != Warning {
+		t.Errorf("wrong severity %s for the unknown value", got)
+	}
+	if got, want := diags[1].Description().Summary, "Unexpected value reported as a diagnostic"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if want := "unexpected value reported as a diagnostictypeint"; len(logged) != 1 || logged[0] != want {
+		t.Errorf("wrong log entries %q; want %q", logged, want)
+	}
+
+	var c Collector
+	c.UnknownSeverity = Error
+	c.Append(struct{}{})
+	if got := c.Diagnostics(); len(got) != 1 || got[0].Severity() != Error {
+		t.Errorf("collector didn't use its UnknownSeverity: %#v", got)
+	}
+	if len(logged) != 2 {
+		t.Errorf("collector's unknown value wasn't logged")
+	}
+
+	SetLenientAppend(nil)
+	defer func() {
+		if recover() == nil {
+			t.Errorf("no panic after disabling lenient mode")
+		}
+	}()
+	Diagnostics(nil).Append(42)
+}
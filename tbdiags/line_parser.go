@@ -0,0 +1,131 @@
+package tbdiags
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// LineParser converts the line-oriented diagnostic output of an external
+// program, such as a third-party linter or compiler, into Diagnostics.
+//
+// Pattern is matched against each line of output, and its named groups
+// supply the parts of each diagnostic:
+//
+//   - "file" is the name of the file the problem is in
+//   - "line" and "col" are its line and column numbers
+//   - "severity" is a word such as "error", "warning" or "note"
+//   - "message" is the summary of the problem
+//
+// Every group is optional. For example, the following pattern matches the
+// conventional "file:line:col: severity: message" format:
+//
+//	^(?P<file>[^:]+):(?P<line>\d+):(?:(?P<col>\d+):)? (?P<severity>\w+): (?P<message>.*)$
+type LineParser struct {
+	Pattern *regexp.Regexp
+
+	// Severity is the severity of diagnostics whose severity group is
+	// missing, empty or unrecognized. The zero value means Error.
+	Severity Severity
+
+	// Positions specifies the numbering of the program's line and column
+	// numbers. The zero value means that both are numbered from one.
+	Positions PositionOptions
+
+	// KeepUnmatched, if set, reports each non-empty line that doesn't match
+	// Pattern as a sourceless diagnostic with the default severity, rather
+	// than ignoring it.
+	KeepUnmatched bool
+}
+
+// Parse reads all of the lines from r and returns the diagnostics they
+// describe. The error is non-nil only if reading from r fails, in which case
+// the diagnostics describe the lines read before the failure.
+func (p *LineParser) Parse(r io.Reader) (Diagnostics, error) {
+	var diags Diagnostics
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if diag := p.ParseLine(sc.Text()); diag != nil {
+			diags = diags.Append(diag)
+		}
+	}
+	return diags, sc.Err()
+}
+
+// ParseLine returns the diagnostic described by the given single line of
+// output, or nil if the line doesn't describe one.
+func (p *LineParser) ParseLine(line string) Diagnostic {
+	defaultSeverity := p.Severity
+	if defaultSeverity == 0 {
+		defaultSeverity = Error
+	}
+
+	match := p.Pattern.FindStringSubmatch(line)
+	if match == nil {
+		if !p.KeepUnmatched || strings.TrimSpace(line) == "" {
+			return nil
+		}
+		return Sourceless(defaultSeverity, strings.TrimSpace(line), "")
+	}
+	group := func(name string) string {
+		if i := p.Pattern.SubexpIndex(name); i > 0 {
+			return match[i]
+		}
+		return ""
+	}
+
+	severity, ok := severityFromWord(group("severity"))
+	if !ok {
+		severity = defaultSeverity
+	}
+	base := diagnosticBase{
+		severity: severity,
+		summary:  strings.TrimSpace(group("message")),
+	}
+	if base.summary == "" {
+		base.summary = strings.TrimSpace(line)
+	}
+
+	filename := group("file")
+	if filename == "" {
+		return base
+	}
+	rng := FileRange(filename)
+	if n, err := strconv.Atoi(group("line")); err == nil {
+		rng.Precision = PrecisionLine
+		rng.Start.Line = n
+		if n, err := strconv.Atoi(group("col")); err == nil {
+			rng.Precision = PrecisionExact
+			rng.Start.Column = n
+		}
+		rng.Start = p.Positions.decode(rng.Start)
+		if rng.Precision == PrecisionLine {
+			rng.Start.Column = 0
+		}
+		rng.End = rng.Start
+	}
+	return sourcedDiagnostic{
+		diagnosticBase: base,
+		subject:        &rng,
+	}
+}
+
+// severityFromWord returns the severity named by one of the words that
+// external programs conventionally use, or false if the word isn't
+// recognized.
+func severityFromWord(word string) (Severity, bool) {
+	switch strings.ToLower(word) {
+	case "fatal", "panic", "critical":
+		return Fatal, true
+	case "error", "err", "e":
+		return Error, true
+	case "warning", "warn", "w":
+		return Warning, true
+	case "hint", "note", "info", "suggestion", "h", "i":
+		return Hint, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,76 @@
+package tbdiags
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLineParser(t *testing.T) {
+	p := &LineParser{
+		Pattern: regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+):(?:(?P<col>\d+):)? (?P<severity>\w+): (?P<message>.*)$`),
+	}
+	output := strings.Join([]string{
+		"main.go:3:7: error: undefined: foo",
+		"main.go:10: warning: unused variable",
+		"util.go:1:1: note: consider renaming",
+		"",
+		"2 problems found",
+	}, "\n")
+
+	diags, err := p.Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		got = append(got, diag.Severity().String()+" "+subjectOf(diag).StartString()+" "+diag.Description().Summary)
+	}
+	want := []string{
+		"Error main.go:3,7 undefined: foo",
+		"Warning main.go:10 unused variable",
+		"Hint util.go:1,1 consider renaming",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+	if rng := subjectOf(diags[1]); rng.Precision != PrecisionLine {
+		t.Errorf("wrong precision %s for line-only position", rng.Precision)
+	}
+
+	p.KeepUnmatched = true
+	p.Severity = Warning
+	diags, _ = p.Parse(strings.NewReader(output))
+	if len(diags) != 4 {
+		t.Fatalf("got %d diagnostics, want 4", len(diags))
+	}
+	if got := diags[3]; got.Severity() != Warning || got.Description().Summary != "2 problems found" {
+		t.Errorf("wrong unmatched diagnostic %s: %s", got.Severity(), got.Description().Summary)
+	}
+}
+
+func TestLineParserSort(t *testing.T) {
+	p := &LineParser{
+		Pattern: regexp.MustCompile(`^(?P<file>[^:]+):(?P<line>\d+):(?:(?P<col>\d+):)? (?P<severity>\w+): (?P<message>.*)$`),
+	}
+	output := strings.Join([]string{
+		"main.go:10:2: error: c",
+		"main.go:3:9: error: b",
+		"main.go:7:1: error: d",
+		"main.go:3:4: error: a",
+	}, "\n")
+
+	diags, err := p.Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	diags.Sort()
+	var got []string
+	for _, diag := range diags {
+		got = append(got, subjectOf(diag).StartString())
+	}
+	want := []string{"main.go:3,4", "main.go:3,9", "main.go:7,1", "main.go:10,2"}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("wrong order\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,119 @@
+//go:build !tinygo && !tbdiags_minimal
+// +build !tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// LogSink is a Sink that writes each diagnostic it receives as a log entry
+// with structured fields to an hclog.Logger. Using a logger created with
+// hclog.LoggerOptions.JSONFormat set makes this a JSON logging bridge.
+//
+// A LogSink can optionally sample diagnostics by severity, so that
+// high-volume servers can keep every error while logging only a fraction
+// of their warnings.
+//
+// LogSink is not available when building with TinyGo or with the
+// tbdiags_minimal build tag, which exclude the parts of this package that
+// depend on hclog and its terminal handling.
+type LogSink struct {
+	logger hclog.Logger
+	rates  map[Severity]float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+var _ Sink = (*LogSink)(nil)
+
+// NewLogSink returns a LogSink that writes to the given logger.
+//
+// The rates map specifies, for each severity, the fraction of diagnostics
+// of that severity to log: 1 logs all of them, 0.01 logs approximately one
+// in every hundred, and 0 logs none. Severities that are not present in the
+// map are always logged, so a nil map disables sampling altogether.
+func NewLogSink(logger hclog.Logger, rates map[Severity]float64) *LogSink {
+	return &LogSink{
+		logger: logger,
+		rates:  rates,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Report implements Sink.
+func (s *LogSink) Report(diags Diagnostics) {
+	for _, diag := range diags {
+		sev := diag.Severity()
+		rate, sampled := s.rates[sev]
+		if sampled && !s.sample(rate) {
+			continue
+		}
+
+		desc := diag.Description()
+		args := []interface{}{"severity", sev.String()}
+		if origin := OriginOf(diag); origin != "" {
+			args = append(args, "origin", origin)
+		}
+		if emitter, ok := EmitterOf(diag); ok {
+			args = append(args, "emitter", emitter.String())
+		}
+		if category := CategoryOf(diag); category != CategoryNone {
+			args = append(args, "category", category.String())
+		}
+		if desc.Code != "" {
+			args = append(args, "code", desc.Code)
+		}
+		if desc.HelpURL != "" {
+			args = append(args, "help_url", desc.HelpURL)
+		}
+		if desc.Address != "" {
+			args = append(args, "address", desc.Address)
+		}
+		if desc.Detail != "" {
+			args = append(args, "detail", desc.Detail)
+		}
+		if subject := subjectOf(diag); subject != nil {
+			args = append(args,
+				"filename", subject.Filename,
+				"line", subject.Start.Line,
+				"column", subject.Start.Column,
+			)
+		}
+		if attrs := AttributesOf(diag); len(attrs) > 0 {
+			args = append(args, "attributes", attrs)
+		}
+		if sampled && rate < 1 {
+			// Record the rate so that consumers of the logs can scale
+			// any counts they derive from them.
+			args = append(args, "sample_rate", rate)
+		}
+
+		switch sev {
+		case Error, Fatal:
+			s.logger.Error(desc.Summary, args...)
+		case Hint:
+			s.logger.Info(desc.Summary, args...)
+		default:
+			s.logger.Warn(desc.Summary, args...)
+		}
+	}
+}
+
+func (s *LogSink) sample(rate float64) bool {
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Float64() < rate
+}
@@ -0,0 +1,67 @@
+//go:build !tinygo && !tbdiags_minimal
+// +build !tinygo,!tbdiags_minimal
+
+package tbdiags
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// hclog loggers can be used with SetLenientAppend.
+var _ WarnLogger = hclog.Logger(nil)
+
+func TestLogSink(t *testing.T) {
+	var buf bytes.Buffer
+	logger := hclog.New(&hclog.LoggerOptions{
+		Output:     &buf,
+		JSONFormat: true,
+	})
+	sink := NewLogSink(logger, map[Severity]float64{
+		Warning: 0,
+	})
+
+	sink.Report(Diagnostics{
+		Sourceless(Warning, "Dubious thing", ""),
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  "Bad thing",
+				detail:   "It went wrong.",
+			},
+			subject: &SourceRange{
+				Filename: "main.tb",
+				Start:    SourcePos{Line: 2, Column: 3, Byte: 10},
+				End:      SourcePos{Line: 2, Column: 5, Byte: 12},
+			},
+		},
+	})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("wrong number of log lines %d; want 1\n%s", len(lines), buf.String())
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"@level":   "error",
+		"@message": "Bad thing",
+		"severity": "Error",
+		"detail":   "It went wrong.",
+		"filename": "main.tb",
+		"line":     float64(2),
+		"column":   float64(3),
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("wrong %s %#v; want %#v", k, got[k], v)
+		}
+	}
+}
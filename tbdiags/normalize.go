@@ -0,0 +1,125 @@
+package tbdiags
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// A Normalizer is a function that Diagnostics.Append applies to each new
+// diagnostic, so that hygiene rules such as redacting secrets can be
+// enforced in one place rather than separately by every producer of
+// diagnostics.
+//
+// A normalizer returns either the diagnostic it was given, if no changes are
+// needed, or a new diagnostic to use in its place. Normalizers must be
+// idempotent, because a diagnostic may pass through Append more than once.
+type Normalizer func(Diagnostic) Diagnostic
+
+var (
+	normalizers   []Normalizer
+	normalizersMu sync.RWMutex
+)
+
+// RegisterNormalizer adds a normalizer that will be applied by all
+// subsequent calls to Diagnostics.Append, after any normalizers that were
+// registered previously.
+//
+// Normalizers are global to the program, so this is intended to be called
+// only by main packages, usually during startup.
+func RegisterNormalizer(n Normalizer) {
+	normalizersMu.Lock()
+	normalizers = append(normalizers, n)
+	normalizersMu.Unlock()
+}
+
+func normalize(diag Diagnostic) Diagnostic {
+	normalizersMu.RLock()
+	defer normalizersMu.RUnlock()
+	for _, n := range normalizers {
+		diag = n(diag)
+	}
+	return diag
+}
+
+// TrimSpace is a Normalizer that removes leading and trailing whitespace
+// from the summaries and details of diagnostics.
+func TrimSpace(diag Diagnostic) Diagnostic {
+	desc := diag.Description()
+	trimmed := desc
+	trimmed.Summary = strings.TrimSpace(desc.Summary)
+	trimmed.Detail = strings.TrimSpace(desc.Detail)
+	if trimmed == desc {
+		return diag
+	}
+	return overrideDescription{diag, trimmed}
+}
+
+// FixInvertedRanges is a Normalizer that corrects source ranges whose end
+// is before their start, by moving the end to the start. Unlike ClampRange,
+// it doesn't have the source code, so it can't correct ranges that extend
+// past the end of it.
+func FixInvertedRanges(diag Diagnostic) Diagnostic {
+	src := diag.Source()
+	fixed := Source{
+		Subject: fixInvertedRange(src.Subject),
+		Context: fixInvertedRange(src.Context),
+	}
+	if fixed == src {
+		return diag
+	}
+	return overrideSource{diag, fixed}
+}
+
+func fixInvertedRange(rng *SourceRange) *SourceRange {
+	if rng == nil || rng.End.Byte >= rng.Start.Byte {
+		return rng
+	}
+	ret := *rng
+	ret.End = ret.Start
+	return &ret
+}
+
+// Redact returns a Normalizer that replaces all matches of the given pattern
+// in the summaries and details of diagnostics with the given replacement,
+// which is interpreted as for regexp.Regexp.ReplaceAllString.
+func Redact(pattern *regexp.Regexp, replacement string) Normalizer {
+	return func(diag Diagnostic) Diagnostic {
+		desc := diag.Description()
+		redacted := desc
+		redacted.Summary = pattern.ReplaceAllString(desc.Summary, replacement)
+		redacted.Detail = pattern.ReplaceAllString(desc.Detail, replacement)
+		if redacted == desc {
+			return diag
+		}
+		return overrideDescription{diag, redacted}
+	}
+}
+
+// overrideDescription wraps another diagnostic to replace its description.
+type overrideDescription struct {
+	Diagnostic
+	desc Description
+}
+
+func (d overrideDescription) Description() Description {
+	return d.desc
+}
+
+func (d overrideDescription) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
+
+// overrideSource wraps another diagnostic to replace its source.
+type overrideSource struct {
+	Diagnostic
+	src Source
+}
+
+func (d overrideSource) Source() Source {
+	return d.src
+}
+
+func (d overrideSource) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
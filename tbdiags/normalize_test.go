@@ -0,0 +1,46 @@
+package tbdiags
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRegisterNormalizer(t *testing.T) {
+	defer func(old []Normalizer) { normalizers = old }(normalizers)
+	normalizers = nil
+
+	RegisterNormalizer(TrimSpace)
+	RegisterNormalizer(Redact(regexp.MustCompile(`tok_[a-z0-9]+`), "tok_REDACTED"))
+	RegisterNormalizer(FixInvertedRanges)
+
+	var diags Diagnostics
+	diags = diags.Append(
+		Sourceless(Error, "Invalid token  \n", "The token tok_abc123 was rejected."),
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Warning,
+				summary:  "Backwards range",
+			},
+			subject: &SourceRange{
+				Start: SourcePos{Line: 1, Column: 5, Byte: 4},
+				End:   SourcePos{Line: 1, Column: 1, Byte: 0},
+			},
+		},
+	)
+
+	desc := diags[0].Description()
+	if got, want := desc.Summary, "Invalid token"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := desc.Detail, "The token tok_REDACTED was rejected."; got != want {
+		t.Errorf("wrong detail %q; want %q", got, want)
+	}
+	if got, want := diags[0].Severity(), Error; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+
+	subject := diags[1].Source().Subject
+	if subject.End != subject.Start {
+		t.Errorf("range was not fixed: %#v", subject)
+	}
+}
@@ -0,0 +1,80 @@
+package tbdiags
+
+// DiagnosticOrigin is an optional interface implemented by diagnostics that
+// record which tool produced them, so that diagnostics aggregated from
+// several embedded tools can be told apart and filtered.
+//
+// Renderer prefixes the summaries of these diagnostics with their origin in
+// brackets, such as "[linter]", and Diagnostics.MarshalJSON encodes it as
+// the "origin" property.
+type DiagnosticOrigin interface {
+	Origin() string
+}
+
+// WithOrigin returns a diagnostic that is the same as the given diagnostic
+// except that it also implements DiagnosticOrigin, returning the given
+// origin, which is typically the name of a tool such as "linter".
+func WithOrigin(diag Diagnostic, origin string) Diagnostic {
+	return withOrigin{diag, origin}
+}
+
+// OriginOf returns the origin of the given diagnostic, or an empty string if
+// it doesn't implement DiagnosticOrigin.
+func OriginOf(diag Diagnostic) string {
+	var ret string
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		o, ok := diag.(DiagnosticOrigin)
+		if ok {
+			ret = o.Origin()
+		}
+		return ok
+	})
+	return ret
+}
+
+// WithOrigin returns a copy of the receiver in which each diagnostic that
+// does not already have an origin has the given origin. Diagnostics that
+// already have one keep it, so that an aggregator of aggregators preserves
+// the tool that actually produced each diagnostic.
+func (diags Diagnostics) WithOrigin(origin string) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		if OriginOf(diag) == "" {
+			diag = WithOrigin(diag, origin)
+		}
+		ret[i] = diag
+	}
+	return ret
+}
+
+// FromOrigin returns the subset of the receiver whose origin is one of the
+// given origins. The empty origin selects diagnostics that have no origin.
+func (diags Diagnostics) FromOrigin(origins ...string) Diagnostics {
+	var ret Diagnostics
+	for _, diag := range diags {
+		origin := OriginOf(diag)
+		for _, want := range origins {
+			if origin == want {
+				ret = append(ret, diag)
+				break
+			}
+		}
+	}
+	return ret
+}
+
+type withOrigin struct {
+	Diagnostic
+	origin string
+}
+
+func (d withOrigin) Origin() string {
+	return d.origin
+}
+
+func (d withOrigin) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
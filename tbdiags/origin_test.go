@@ -0,0 +1,39 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsOrigin(t *testing.T) {
+	var diags Diagnostics
+	diags = diags.Append(Diagnostics{
+		Sourceless(Error, "E102", ""),
+	}.WithOrigin("linter"))
+	diags = diags.Append(Diagnostics{
+		Sourceless(Error, "E102", ""),
+		WithOrigin(Sourceless(Warning, "Embedded", ""), "formatter"),
+	}.WithOrigin("planner"))
+
+	if got := len(diags.Deduplicate()); got != 3 {
+		t.Errorf("diagnostics from different origins were deduplicated; %d remain", got)
+	}
+	if got := diags.FromOrigin("planner"); len(got) != 1 || got[0].Description().Summary != "E102" {
+		t.Errorf("wrong planner diagnostics %#v", got)
+	}
+	if got := OriginOf(diags[2]); got != "formatter" {
+		t.Errorf("wrong origin %q for pre-existing origin; want \"formatter\"", got)
+	}
+
+	rendered := (&Renderer{}).RenderString(diags[:1])
+	if want := "Error: [linter] E102\n"; !strings.HasPrefix(rendered, want) {
+		t.Errorf("wrong rendering\ngot:\n%s\nwant prefix:\n%s", rendered, want)
+	}
+	js, err := diags[:1].MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(js), `"origin":"linter"`) {
+		t.Errorf("JSON has no origin: %s", js)
+	}
+}
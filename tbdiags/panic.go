@@ -0,0 +1,61 @@
+package tbdiags
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicInfo is the payload, available from ExtraInfo, of diagnostics
+// created by FromRecover.
+type PanicInfo struct {
+	// Value is the value that was passed to panic.
+	Value interface{}
+
+	// Stack is the stack trace of the goroutine that panicked, as
+	// formatted by runtime/debug.Stack.
+	Stack []byte
+}
+
+// FromRecover returns an error diagnostic describing a panic, given the
+// value returned by recover, or nil if the value is nil, so that commands
+// can report panics consistently rather than crashing:
+//
+//	defer func() {
+//		if diag := tbdiags.FromRecover(recover()); diag != nil {
+//			diags = diags.Append(diag)
+//		}
+//	}()
+//
+// It must be called from the deferred function itself, so that the stack
+// trace it captures is that of the panic. The trace is available from the
+// PanicInfo payload and is included by Renderer when Verbose is set. If
+// the value is an error, CauseOf returns it.
+func FromRecover(v interface{}) Diagnostic {
+	if v == nil {
+		return nil
+	}
+	info := PanicInfo{Value: v, Stack: debug.Stack()}
+	const summary = "Unexpected panic"
+	var diag Diagnostic
+	if err, ok := v.(error); ok {
+		diag = wrappedError{nativeError: nativeError{err}, summary: summary}
+	} else {
+		diag = diagnosticBase{
+			severity: Error,
+			summary:  summary,
+			detail:   fmt.Sprint(v),
+		}
+	}
+	return withCaller(WithExtraInfo(diag, info))
+}
+
+// panicInfoOf returns the PanicInfo payload of the given diagnostic, for
+// code that must build without generics and so can't use ExtraInfo.
+func panicInfoOf(diag Diagnostic) (PanicInfo, bool) {
+	for _, info := range ExtraInfos(diag) {
+		if info, ok := info.(PanicInfo); ok {
+			return info, true
+		}
+	}
+	return PanicInfo{}, false
+}
@@ -0,0 +1,60 @@
+package tbdiags
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFromRecover(t *testing.T) {
+	recoverFrom := func(f func()) (diag Diagnostic) {
+		defer func() {
+			diag = FromRecover(recover())
+		}()
+		f()
+		return nil
+	}
+
+	diag := recoverFrom(func() {
+		panic("index out of range")
+	})
+	if diag == nil {
+		t.Fatal("no diagnostic for a panic")
+	}
+	if got := diag.Severity(); got != Error {
+		t.Errorf("wrong severity %s", got)
+	}
+	if got, want := diag.Description(), (Description{Summary: "Unexpected panic", Detail: "index out of range"}); got != want {
+		t.Errorf("wrong description %#v; want %#v", got, want)
+	}
+	info, ok := panicInfoOf(diag)
+	if !ok || info.Value != "index out of range" {
+		t.Fatalf("wrong panic info %#v", info)
+	}
+	if !strings.Contains(string(info.Stack), "TestFromRecover") {
+		t.Errorf("stack doesn't include the panicking function:\n%s", info.Stack)
+	}
+
+	rendered := (&Renderer{Verbose: true}).RenderString(Diagnostics{diag})
+	if !strings.Contains(rendered, "  (panicked with stack trace:)\n    goroutine ") {
+		t.Errorf("verbose rendering has no stack trace:\n%s", rendered)
+	}
+	if rendered := (&Renderer{}).RenderString(Diagnostics{diag}); strings.Contains(rendered, "goroutine") {
+		t.Errorf("rendering has a stack trace without Verbose:\n%s", rendered)
+	}
+
+	errBoom := errors.New("boom")
+	diag = recoverFrom(func() {
+		panic(errBoom)
+	})
+	if got := CauseOf(diag); got != errBoom {
+		t.Errorf("wrong cause %#v", got)
+	}
+	if got := diag.Description().Detail; got != "boom" {
+		t.Errorf("wrong detail %q", got)
+	}
+
+	if diag := recoverFrom(func() {}); diag != nil {
+		t.Errorf("unexpected diagnostic %#v without a panic", diag)
+	}
+}
@@ -0,0 +1,96 @@
+package tbdiags
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// FindWorkspaceRoot searches the given directory and then each of its
+// ancestors in turn for one containing a file or directory with any of the
+// given marker names, returning the first one found. If no markers are
+// given then ".git" is used, which finds the root of a git work tree.
+//
+// The second return value is false if no ancestor contains a marker.
+func FindWorkspaceRoot(dir string, markers ...string) (string, bool) {
+	if len(markers) == 0 {
+		markers = []string{".git"}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	for {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// PathMode is the display mode used by a PathPolicy.
+type PathMode int
+
+const (
+	// PathRelative displays filenames relative to the policy's root
+	// directory, or absolute if that isn't possible.
+	PathRelative PathMode = iota
+
+	// PathAbsolute displays filenames as absolute paths.
+	PathAbsolute
+
+	// PathBase displays only the final element of each filename.
+	PathBase
+)
+
+// PathPolicy describes how filenames from source ranges should be displayed
+// to the user, so that producers of diagnostics can use whatever paths are
+// convenient for them and leave presentation to whatever renders the
+// diagnostics.
+//
+// The zero value of PathPolicy displays filenames relative to the current
+// working directory.
+type PathPolicy struct {
+	Mode PathMode
+
+	// Root is the directory that PathRelative paths are relative to, such
+	// as a directory returned by FindWorkspaceRoot. If empty, the current
+	// working directory is used.
+	Root string
+}
+
+// DisplayPath returns the given filename as it should be displayed under the
+// receiving policy.
+func (p PathPolicy) DisplayPath(filename string) string {
+	switch p.Mode {
+	case PathAbsolute:
+		if abs, err := filepath.Abs(filename); err == nil {
+			return abs
+		}
+		return filename
+	case PathBase:
+		return filepath.Base(filename)
+	default:
+		root := p.Root
+		if root == "" {
+			wd, err := os.Getwd()
+			if err != nil {
+				return filename
+			}
+			root = wd
+		}
+		if abs, err := filepath.Abs(filename); err == nil {
+			filename = abs
+		}
+		if rel, err := filepath.Rel(root, filename); err == nil {
+			return rel
+		}
+		return filename
+	}
+}
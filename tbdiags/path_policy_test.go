@@ -0,0 +1,61 @@
+package tbdiags
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindWorkspaceRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "workspace.tb"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := FindWorkspaceRoot(sub, "workspace.tb")
+	if !ok {
+		t.Fatalf("workspace root not found")
+	}
+	if got != root {
+		t.Errorf("wrong root\ngot:  %s\nwant: %s", got, root)
+	}
+
+	if _, ok := FindWorkspaceRoot(sub, "nonexistent.marker"); ok {
+		t.Errorf("found workspace root without a marker")
+	}
+}
+
+func TestPathPolicyDisplayPath(t *testing.T) {
+	root := filepath.FromSlash("/work/project")
+	filename := filepath.Join(root, "modules", "main.tb")
+
+	tests := map[string]struct {
+		Policy PathPolicy
+		Want   string
+	}{
+		"relative": {
+			PathPolicy{Root: root},
+			filepath.Join("modules", "main.tb"),
+		},
+		"absolute": {
+			PathPolicy{Mode: PathAbsolute, Root: root},
+			filename,
+		},
+		"base": {
+			PathPolicy{Mode: PathBase},
+			"main.tb",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.Policy.DisplayPath(filename); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
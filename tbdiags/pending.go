@@ -0,0 +1,59 @@
+package tbdiags
+
+// Pending creates and returns a diagnostic whose source isn't known yet,
+// for producers that detect a problem before the source information needed
+// to describe where it is has become available, such as while decoding a
+// configuration body whose ranges are tracked elsewhere.
+//
+// Until it's resolved by Diagnostics.ResolveSources, a pending diagnostic
+// behaves exactly like one created by Sourceless, so it's never wrong to
+// report one unresolved.
+func Pending(severity Severity, summary, detail string) Diagnostic {
+	return withCaller(pendingDiagnostic{diagnosticBase{
+		severity: severity,
+		summary:  summary,
+		detail:   detail,
+	}})
+}
+
+// IsPending returns true if the given diagnostic was created by Pending and
+// has not yet been given a subject by Diagnostics.ResolveSources.
+func IsPending(diag Diagnostic) bool {
+	if diag.Source().Subject != nil {
+		return false
+	}
+	return findDiagnostic(diag, func(diag Diagnostic) bool {
+		_, ok := diag.(pendingDiagnostic)
+		return ok
+	})
+}
+
+// ResolveSources returns a copy of the receiver in which each pending
+// diagnostic, as reported by IsPending, is given the subject returned by
+// calling the resolver with that diagnostic. If the resolver returns nil,
+// the diagnostic remains pending, so that the source information can be
+// resolved in several passes as it becomes available. Other diagnostics,
+// and the context ranges of all diagnostics, are unchanged.
+func (diags Diagnostics) ResolveSources(resolver func(Diagnostic) *SourceRange) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		if IsPending(diag) {
+			if rng := resolver(diag); rng != nil {
+				src := diag.Source()
+				src.Subject = copyRange(rng)
+				diag = overrideSource{diag, src}
+			}
+		}
+		ret[i] = diag
+	}
+	return ret
+}
+
+// pendingDiagnostic is a diagnostic created by Pending, which is marked so
+// that ResolveSources can recognize it even when it's been wrapped.
+type pendingDiagnostic struct {
+	diagnosticBase
+}
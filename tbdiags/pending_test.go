@@ -0,0 +1,58 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestDiagnosticsResolveSources(t *testing.T) {
+	body := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 4, Column: 1, Byte: 30},
+		End:      SourcePos{Line: 9, Column: 2, Byte: 95},
+	}
+	diags := Diagnostics{
+		Pending(Error, "Missing required argument", `The argument "name" is required.`),
+		WithEmitter(Pending(Warning, "Unknown block", ""), Emitter{Component: "decoder"}),
+		Pending(Error, "Unresolvable", ""),
+		Sourceless(Error, "Not pending", ""),
+	}
+	for i, diag := range diags {
+		if got, want := IsPending(diag), i < 3; got != want {
+			t.Errorf("IsPending(%d) = %t; want %t", i, got, want)
+		}
+	}
+
+	var calls []string
+	got := diags.ResolveSources(func(diag Diagnostic) *SourceRange {
+		calls = append(calls, diag.Description().Summary)
+		if diag.Description().Summary == "Unresolvable" {
+			return nil
+		}
+		return &body
+	})
+	if want := 3; len(calls) != want {
+		t.Errorf("resolver was called for %q; want only the %d pending diagnostics", calls, want)
+	}
+	for i, diag := range got {
+		subject := diag.Source().Subject
+		if resolved := i < 2; resolved != (subject != nil) {
+			t.Errorf("diagnostic %d has subject %v", i, subject)
+			continue
+		}
+		if subject != nil && *subject != body {
+			t.Errorf("diagnostic %d has subject %v; want %v", i, subject, body)
+		}
+		if diag.Severity() != diags[i].Severity() || diag.Description() != diags[i].Description() {
+			t.Errorf("diagnostic %d changed: %#v", i, diag)
+		}
+	}
+	if IsPending(got[0]) || !IsPending(got[2]) {
+		t.Errorf("wrong pending state after resolving")
+	}
+	if emitter, ok := EmitterOf(got[1]); !ok || emitter.Component != "decoder" {
+		t.Errorf("resolved diagnostic lost its emitter")
+	}
+	if diags[0].Source().Subject != nil {
+		t.Errorf("ResolveSources modified the receiver")
+	}
+}
@@ -0,0 +1,132 @@
+package tbdiags
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Templates for the permalinks of some common code hosts, for use with
+// PermalinkOptions.
+const (
+	GitHubPermalink    = `{{.Repo}}/blob/{{.Commit}}/{{.Path}}{{if .Line}}#L{{.Line}}{{if gt .EndLine .Line}}-L{{.EndLine}}{{end}}{{end}}`
+	GitLabPermalink    = `{{.Repo}}/-/blob/{{.Commit}}/{{.Path}}{{if .Line}}#L{{.Line}}{{if gt .EndLine .Line}}-{{.EndLine}}{{end}}{{end}}`
+	BitbucketPermalink = `{{.Repo}}/src/{{.Commit}}/{{.Path}}{{if .Line}}#lines-{{.Line}}{{if gt .EndLine .Line}}:{{.EndLine}}{{end}}{{end}}`
+)
+
+// PermalinkOptions configures NewPermalinker.
+type PermalinkOptions struct {
+	// Template is a text/template that produces the link, such as
+	// GitHubPermalink. It's executed with a PermalinkData.
+	Template string
+
+	// Repo is the base URL of the repository on the code host, such as
+	// "https://github.com/example/project", and Commit is the revision
+	// that the diagnostics were produced from. Links to a commit ID
+	// rather than a branch name continue to point at the right lines after
+	// the files change.
+	Repo, Commit string
+
+	// Root is the directory where the repository is checked out, which
+	// filenames are made relative to, such as a directory returned by
+	// FindWorkspaceRoot. If empty, the current working directory is used.
+	Root string
+}
+
+// PermalinkData is the data that the template of a Permalinker is executed
+// with.
+type PermalinkData struct {
+	Repo, Commit string
+
+	// Path is the filename relative to the root of the repository, with
+	// forward slashes and with each element escaped for use in a URL.
+	Path string
+
+	// Line and EndLine are the first and last lines of the range, or zero
+	// if the range identifies only a file.
+	Line, EndLine int
+}
+
+// Permalinker produces links to the source lines of diagnostics on a code
+// host, so that reports such as digests can link each diagnostic to exactly
+// the lines it's about.
+type Permalinker struct {
+	opts PermalinkOptions
+	tmpl *template.Template
+}
+
+// NewPermalinker returns a Permalinker configured by the given options, or
+// an error if the template is invalid.
+func NewPermalinker(opts PermalinkOptions) (*Permalinker, error) {
+	tmpl, err := template.New("permalink").Option("missingkey=error").Parse(opts.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid permalink template: %s", err)
+	}
+	return &Permalinker{opts: opts, tmpl: tmpl}, nil
+}
+
+// Link returns the permalink for the subject of the given diagnostic, or
+// false if it has no subject that can be linked to, such as because its
+// subject is not a file, is outside of the repository, or has
+// PrecisionNone.
+func (p *Permalinker) Link(diag Diagnostic) (string, bool) {
+	subject := subjectOf(diag)
+	if subject == nil {
+		return "", false
+	}
+	return p.RangeLink(*subject)
+}
+
+// RangeLink is like Link except that it returns the permalink for the given
+// range.
+func (p *Permalinker) RangeLink(rng SourceRange) (string, bool) {
+	if rng.Kind != SubjectFile || rng.Precision == PrecisionNone {
+		return "", false
+	}
+	path, ok := p.repoPath(rng.Filename)
+	if !ok {
+		return "", false
+	}
+	data := PermalinkData{
+		Repo:   strings.TrimSuffix(p.opts.Repo, "/"),
+		Commit: p.opts.Commit,
+		Path:   path,
+	}
+	if rng.Precision != PrecisionFile {
+		data.Line, data.EndLine = rng.Start.Line, rng.End.Line
+	}
+	var buf strings.Builder
+	if err := p.tmpl.Execute(&buf, data); err != nil {
+		return "", false
+	}
+	return buf.String(), true
+}
+
+// repoPath returns the given filename relative to the root of the
+// repository, escaped for use in a URL, or false if it's not inside the
+// repository.
+func (p *Permalinker) repoPath(filename string) (string, bool) {
+	root := p.opts.Root
+	if root == "" {
+		root = "."
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	abs, err := filepath.Abs(filename)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/"), true
+}
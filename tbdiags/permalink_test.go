@@ -0,0 +1,82 @@
+package tbdiags
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPermalinker(t *testing.T) {
+	root := filepath.FromSlash("/work/project")
+	tests := map[string]struct {
+		template string
+		rng      SourceRange
+		want     string
+	}{
+		"github range": {
+			GitHubPermalink,
+			SourceRange{Filename: filepath.Join(root, "mod", "main.tb"), Start: SourcePos{Line: 3}, End: SourcePos{Line: 5}},
+			"https://example.com/org/repo/blob/abc123/mod/main.tb#L3-L5",
+		},
+		"github single line": {
+			GitHubPermalink,
+			LineRange(filepath.Join(root, "main.tb"), 7),
+			"https://example.com/org/repo/blob/abc123/main.tb#L7",
+		},
+		"gitlab range": {
+			GitLabPermalink,
+			SourceRange{Filename: filepath.Join(root, "main.tb"), Start: SourcePos{Line: 3}, End: SourcePos{Line: 5}},
+			"https://example.com/org/repo/-/blob/abc123/main.tb#L3-5",
+		},
+		"bitbucket range": {
+			BitbucketPermalink,
+			SourceRange{Filename: filepath.Join(root, "main.tb"), Start: SourcePos{Line: 3}, End: SourcePos{Line: 5}},
+			"https://example.com/org/repo/src/abc123/main.tb#lines-3:5",
+		},
+		"whole file": {
+			GitHubPermalink,
+			FileRange(filepath.Join(root, "main.tb")),
+			"https://example.com/org/repo/blob/abc123/main.tb",
+		},
+		"escaped path": {
+			GitHubPermalink,
+			LineRange(filepath.Join(root, "my dir", "a#b.tb"), 1),
+			"https://example.com/org/repo/blob/abc123/my%20dir/a%23b.tb#L1",
+		},
+		"outside repository": {
+			GitHubPermalink,
+			LineRange(filepath.FromSlash("/elsewhere/main.tb"), 1),
+			"",
+		},
+		"not a file": {
+			GitHubPermalink,
+			SourceRange{Filename: "TB_TOKEN", Kind: SubjectEnvVar},
+			"",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := NewPermalinker(PermalinkOptions{
+				Template: test.template,
+				Repo:     "https://example.com/org/repo/",
+				Commit:   "abc123",
+				Root:     root,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rng := test.rng
+			got, ok := p.Link(sourcedDiagnostic{
+				diagnosticBase: diagnosticBase{severity: Error, summary: "Problem"},
+				subject:        &rng,
+			})
+			if got != test.want || ok != (test.want != "") {
+				t.Errorf("wrong result %q, %t; want %q", got, ok, test.want)
+			}
+		})
+	}
+
+	if _, err := NewPermalinker(PermalinkOptions{Template: "{{.Repo"}); err == nil || !strings.Contains(err.Error(), "invalid permalink template") {
+		t.Errorf("wrong error for an invalid template: %v", err)
+	}
+}
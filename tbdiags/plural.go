@@ -0,0 +1,51 @@
+package tbdiags
+
+import (
+	"fmt"
+)
+
+// CountNoun identifies a noun that this package's built-in messages count,
+// such as in the "2 errors" of an Error() string, so that a CountFormatter
+// can translate it.
+type CountNoun string
+
+const (
+	NounDiagnostic CountNoun = "diagnostic"
+	NounProblem    CountNoun = "problem"
+	NounFatalError CountNoun = "fatal error"
+	NounError      CountNoun = "error"
+	NounWarning    CountNoun = "warning"
+	NounHint       CountNoun = "hint"
+
+	// NounOther counts the items omitted from a list, as in "and 3 others".
+	NounOther CountNoun = "other"
+
+	// NounMore counts the items omitted from a list that has been cut
+	// short, as in "…and 3 more".
+	NounMore CountNoun = "more"
+
+	// NounNew counts the items that weren't present before, as in the
+	// "3 new" of a Digest.
+	NounNew CountNoun = "new"
+)
+
+// CountFormatter returns a phrase counting the given number of the given
+// noun, such as "2 errors", in the appropriate plural form and with the
+// number formatted appropriately for the reader's locale.
+type CountFormatter func(n int, noun CountNoun) string
+
+// FormatCount is the CountFormatter used for every count in the Error()
+// strings, renderers and other built-in messages of this package. It
+// defaults to EnglishCount. Applications that localize their output can
+// replace it, such as with a function that uses golang.org/x/text/message,
+// and should do so once during startup, as for MaxErrorItems.
+var FormatCount CountFormatter = EnglishCount
+
+// EnglishCount is the default CountFormatter, which formats counts in
+// English, such as "1 error" and "2 errors".
+func EnglishCount(n int, noun CountNoun) string {
+	if n == 1 || noun == NounMore || noun == NounNew {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
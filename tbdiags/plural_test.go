@@ -0,0 +1,49 @@
+package tbdiags
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEnglishCount(t *testing.T) {
+	tests := map[string]struct {
+		N    int
+		Noun CountNoun
+		Want string
+	}{
+		"singular":       {1, NounError, "1 error"},
+		"plural":         {2, NounFatalError, "2 fatal errors"},
+		"zero":           {0, NounWarning, "0 warnings"},
+		"more":           {3, NounMore, "3 more"},
+		"new":            {2, NounNew, "2 new"},
+		"one other":      {1, NounOther, "1 other"},
+		"several others": {4, NounOther, "4 others"},
+		"problems":       {5, NounProblem, "5 problems"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := EnglishCount(test.N, test.Noun); got != test.Want {
+				t.Errorf("wrong result\ngot:  %s\nwant: %s", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	defer func(orig CountFormatter) {
+		FormatCount = orig
+	}(FormatCount)
+	FormatCount = func(n int, noun CountNoun) string {
+		return fmt.Sprintf("<%d %s>", n, noun)
+	}
+
+	diags := Diagnostics{
+		Sourceless(Error, "A", ""),
+		Sourceless(Warning, "B", ""),
+	}
+	got := diags.ErrWithWarnings().Error()
+	want := "<2 problem> (<1 error>, <1 warning>):\n\n- Error: A\n- Warning: B"
+	if got != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+}
@@ -0,0 +1,64 @@
+package tbdiags
+
+// PositionBase is the number that a serialization format uses for the first
+// line or the first column of a file.
+//
+// Within this package, and in SourcePos, lines and columns are always
+// numbered from one, so conversions happen only at the boundaries with
+// other formats, as specified by PositionOptions.
+type PositionBase int
+
+const (
+	// OneBased numbers the first line or column as 1, as most editors and
+	// compilers do. This is the convention used within this package.
+	OneBased PositionBase = iota
+
+	// ZeroBased numbers the first line or column as 0, as the Language
+	// Server Protocol does.
+	ZeroBased
+)
+
+// PositionOptions specifies the position conventions of a serialization
+// format, for encoders, decoders and converters that support more than one.
+// The zero value specifies the internal convention, with lines and columns
+// numbered from one. Language Server Protocol clients need both fields to
+// be ZeroBased.
+//
+// Byte offsets are always numbered from zero and are never converted.
+type PositionOptions struct {
+	LineBase, ColumnBase PositionBase
+}
+
+// encode converts the given position in the internal convention to one in
+// the receiver's convention.
+func (opts PositionOptions) encode(pos SourcePos) SourcePos {
+	return SourcePos{
+		Line:   opts.LineBase.encode(pos.Line),
+		Column: opts.ColumnBase.encode(pos.Column),
+		Byte:   pos.Byte,
+	}
+}
+
+// decode converts the given position in the receiver's convention to one in
+// the internal convention.
+func (opts PositionOptions) decode(pos SourcePos) SourcePos {
+	return SourcePos{
+		Line:   opts.LineBase.decode(pos.Line),
+		Column: opts.ColumnBase.decode(pos.Column),
+		Byte:   pos.Byte,
+	}
+}
+
+func (b PositionBase) encode(n int) int {
+	if b == ZeroBased {
+		return n - 1
+	}
+	return n
+}
+
+func (b PositionBase) decode(n int) int {
+	if b == ZeroBased {
+		return n + 1
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+package tbdiags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Hop is one step in the journey of a diagnostic through a distributed
+// system, such as from a worker to the coordinator that aggregates its
+// diagnostics.
+type Hop struct {
+	// Host is the name of the machine, as returned by os.Hostname.
+	Host string
+
+	// Process identifies the process on that machine, such as
+	// "worker[1234]".
+	Process string
+
+	// Component is the name of the part of the system that handled the
+	// diagnostic, such as "worker".
+	Component string
+
+	// Time is when the diagnostic passed through the hop.
+	Time time.Time
+}
+
+// CurrentHop returns a Hop describing the current process, as the given
+// component, at the current time.
+func CurrentHop(component string) Hop {
+	host, _ := os.Hostname() // leave the host empty if it's unknown
+	return Hop{
+		Host:      host,
+		Process:   fmt.Sprintf("%s[%d]", filepath.Base(os.Args[0]), os.Getpid()),
+		Component: component,
+		Time:      time.Now(),
+	}
+}
+
+// String returns a description of the hop for debugging, such as
+// "worker on build-3 (worker[1234]) at 2024-03-01T09:30:00Z".
+func (h Hop) String() string {
+	var b strings.Builder
+	b.WriteString(h.Component)
+	if h.Host != "" {
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString("on " + h.Host)
+	}
+	if h.Process != "" {
+		fmt.Fprintf(&b, " (%s)", h.Process)
+	}
+	if !h.Time.IsZero() {
+		b.WriteString(" at " + h.Time.Format(time.RFC3339))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// DiagnosticProvenance is an optional interface implemented by diagnostics
+// that record the hops they took on their way through a distributed system,
+// so that a diagnostic aggregated by a coordinator can be traced back to
+// where it originated.
+//
+// Diagnostics.MarshalJSON encodes the hops as the "provenance" property, an
+// array of objects with "host", "process", "component" and "time"
+// properties, oldest first. Empty properties are omitted.
+type DiagnosticProvenance interface {
+	// Provenance returns the hops, oldest first.
+	Provenance() []Hop
+}
+
+// WithHop returns a diagnostic that is the same as the given diagnostic
+// except that it also implements DiagnosticProvenance, returning the given
+// hop after any that the given diagnostic already has.
+func WithHop(diag Diagnostic, hop Hop) Diagnostic {
+	prev := ProvenanceOf(diag)
+	hops := make([]Hop, len(prev), len(prev)+1)
+	copy(hops, prev)
+	return withProvenance{diag, append(hops, hop)}
+}
+
+// ProvenanceOf returns the hops that the given diagnostic has taken, oldest
+// first, or nil if it doesn't implement DiagnosticProvenance.
+func ProvenanceOf(diag Diagnostic) []Hop {
+	var ret []Hop
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		p, ok := diag.(DiagnosticProvenance)
+		if ok {
+			ret = p.Provenance()
+		}
+		return ok
+	})
+	return ret
+}
+
+// WithHop returns a copy of the receiver in which the given hop has been
+// added to the provenance of each diagnostic. Each process that receives or
+// forwards diagnostics in a distributed run would typically call it with the
+// result of CurrentHop.
+func (diags Diagnostics) WithHop(hop Hop) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		ret[i] = WithHop(diag, hop)
+	}
+	return ret
+}
+
+type withProvenance struct {
+	Diagnostic
+	hops []Hop
+}
+
+func (d withProvenance) Provenance() []Hop {
+	return d.hops[:len(d.hops):len(d.hops)]
+}
+
+func (d withProvenance) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,44 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProvenance(t *testing.T) {
+	worker := Hop{Host: "build-3", Process: "worker[1234]", Component: "worker", Time: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)}
+	coordinator := Hop{Host: "main", Process: "coord[1]", Component: "coordinator", Time: time.Date(2024, 3, 1, 9, 30, 1, 0, time.UTC)}
+	diags := Diagnostics{Sourceless(Error, "Build failed", "")}.WithHop(worker).WithHop(coordinator)
+
+	hops := ProvenanceOf(diags[0])
+	if len(hops) != 2 || hops[0] != worker || hops[1] != coordinator {
+		t.Fatalf("wrong provenance %#v", hops)
+	}
+
+	got := withoutReportedAt((&Renderer{Verbose: true}).RenderString(diags))
+	want := `Error: Build failed
+  (via worker on build-3 (worker[1234]) at 2024-03-01T09:30:00Z)
+  (via coordinator on main (coord[1]) at 2024-03-01T09:30:01Z)
+
+`
+	if got != want {
+		t.Errorf("wrong rendering\ngot:\n%s\nwant:\n%s", got, want)
+	}
+	if got := (&Renderer{}).RenderString(diags); strings.Contains(got, "via") {
+		t.Errorf("provenance rendered without Verbose:\n%s", got)
+	}
+
+	js, err := diags.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `"provenance":[{"host":"build-3","process":"worker[1234]","component":"worker","time":"2024-03-01T09:30:00Z"},{"host":"main","process":"coord[1]","component":"coordinator","time":"2024-03-01T09:30:01Z"}]`
+	if !strings.Contains(string(js), wantJSON) {
+		t.Errorf("JSON has wrong provenance\ngot:  %s\nwant: %s", js, wantJSON)
+	}
+
+	if hop := CurrentHop("worker"); hop.Component != "worker" || hop.Process == "" || hop.Time.IsZero() {
+		t.Errorf("incomplete current hop %#v", hop)
+	}
+}
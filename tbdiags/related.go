@@ -0,0 +1,76 @@
+package tbdiags
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RelatedInfo is a secondary location that is relevant to a diagnostic,
+// such as where a duplicated name was also declared, with a message
+// explaining its relevance.
+type RelatedInfo struct {
+	// Message explains why the range is relevant, such as "also defined
+	// here".
+	Message string
+
+	Range SourceRange
+}
+
+// DiagnosticRelated is an optional interface implemented by diagnostics that
+// have secondary locations in addition to their subject.
+//
+// Renderer renders the secondary locations after the snippet of the subject,
+// each with its message and, if the source is available, its own snippet.
+// Diagnostics.MarshalJSON encodes them as the "related" property, an array
+// of objects with "message" and "range" properties, where each range is in
+// the same form as "subject".
+type DiagnosticRelated interface {
+	Related() []RelatedInfo
+}
+
+// WithRelated returns a diagnostic that is the same as the given diagnostic
+// except that it also implements DiagnosticRelated, returning the given
+// related information after any that the given diagnostic already has.
+func WithRelated(diag Diagnostic, related ...RelatedInfo) Diagnostic {
+	all := make([]RelatedInfo, 0, len(related))
+	all = append(all, RelatedOf(diag)...)
+	all = append(all, related...)
+	return withRelated{diag, all}
+}
+
+// RelatedOf returns the related information for the given diagnostic, if
+// it implements DiagnosticRelated, or nil otherwise.
+func RelatedOf(diag Diagnostic) []RelatedInfo {
+	var ret []RelatedInfo
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		r, ok := diag.(DiagnosticRelated)
+		if ok {
+			ret = r.Related()
+		}
+		return ok
+	})
+	return ret
+}
+
+// relatedKey returns a string that identifies the related information of
+// the given diagnostic, for breaking ties when sorting.
+func relatedKey(diag Diagnostic) string {
+	var b strings.Builder
+	for _, info := range RelatedOf(diag) {
+		fmt.Fprintf(&b, "\x00%s\x00%d:%s:%d:%d", info.Message, info.Range.Kind, info.Range.Filename, info.Range.Start.Line, info.Range.Start.Byte)
+	}
+	return b.String()
+}
+
+type withRelated struct {
+	Diagnostic
+	related []RelatedInfo
+}
+
+func (d withRelated) Related() []RelatedInfo {
+	return d.related[:len(d.related):len(d.related)]
+}
+
+func (d withRelated) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,83 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRelated(t *testing.T) {
+	exact := func(line, column, length int) SourceRange {
+		return SourceRange{
+			Filename: "main.tb",
+			Start:    SourcePos{Line: line, Column: column},
+			End:      SourcePos{Line: line, Column: column + length},
+		}
+	}
+	subject := exact(3, 1, 1)
+	diag := WithRelated(sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{severity: Error, summary: "Duplicate name", detail: "Each name must be unique."},
+		subject:        &subject,
+	}, RelatedInfo{Message: "first defined here", Range: exact(1, 1, 1)})
+	diag = WithRelated(diag, RelatedInfo{Message: "used here", Range: LineRange("other.tb", 4)})
+
+	if got := len(RelatedOf(diag)); got != 2 {
+		t.Fatalf("wrong number of related locations %d; want 2", got)
+	}
+
+	r := &Renderer{
+		Paths:   PathPolicy{Mode: PathBase},
+		Sources: map[string][]byte{"main.tb": []byte("a = 1\nb = 2\na = 3\n")},
+	}
+	got := r.RenderString(Diagnostics{diag})
+	want := `Error: Duplicate name
+  on main.tb:3,1
+
+     3: a = 3
+        ^
+
+  first defined here: main.tb:1,1
+
+     1: a = 1
+        ^
+
+  used here: other.tb:4
+
+Each name must be unique.
+
+`
+	if got != want {
+		t.Errorf("wrong rendering\ngot:\n%s\nwant:\n%s", got, want)
+	}
+
+	js, err := Diagnostics{diag}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := `"related":[{"message":"first defined here","range":{"filename":"main.tb","start":{"line":1,"column":1,"byte":0},"end":{"line":1,"column":2,"byte":0}}},{"message":"used here","range":{"filename":"other.tb","precision":"line","start":{"line":4},"end":{"line":4}}}]`
+	if !strings.Contains(string(js), wantJSON) {
+		t.Errorf("JSON has wrong related information\ngot:  %s\nwant: %s", js, wantJSON)
+	}
+
+	// Diagnostics that differ only in their related information are still
+	// sorted deterministically.
+	other := WithRelated(Diagnostic(sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{severity: Error, summary: "Duplicate name", detail: "Each name must be unique."},
+		subject:        &subject,
+	}), RelatedInfo{Message: "first defined here", Range: exact(2, 1, 1)})
+	a, err := Diagnostics{diag, other}.MarshalJSONWith(JSONOptions{Sort: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Diagnostics{other, diag}.MarshalJSONWith(JSONOptions{Sort: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(a) != string(b) {
+		t.Errorf("sorted output depends on the original order\n%s\n%s", a, b)
+	}
+
+	compacted := Diagnostics{diag, other}.CompactByRange()
+	if got := len(RelatedOf(compacted[0])); len(compacted) != 1 || got != 3 {
+		t.Errorf("wrong number of related locations %d after compacting; want 3", got)
+	}
+}
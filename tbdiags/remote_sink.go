@@ -0,0 +1,242 @@
+package tbdiags
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Transport sends batches of diagnostics to a remote collector on behalf of
+// a RemoteSink. Implementations might use HTTP, as HTTPTransport does, or
+// some other RPC mechanism.
+type Transport interface {
+	// Send sends a batch of diagnostics, returning an error if the batch
+	// may not have been received, in which case it will be retried.
+	Send(ctx context.Context, diags Diagnostics) error
+}
+
+// RemoteSinkOptions configures a RemoteSink. Any zero-valued fields take the
+// default values noted in their documentation.
+type RemoteSinkOptions struct {
+	// BatchSize is the maximum number of diagnostics to send at once. When
+	// this many diagnostics are buffered they are sent immediately, without
+	// waiting for FlushInterval. Defaults to 100.
+	BatchSize int
+
+	// FlushInterval is the maximum time that diagnostics are buffered
+	// before being sent. Defaults to five seconds.
+	FlushInterval time.Duration
+
+	// MaxBuffered is the maximum number of diagnostics to buffer. If the
+	// buffer is full, the oldest diagnostics are discarded to make room
+	// for new ones. Defaults to 10000.
+	MaxBuffered int
+
+	// Component, if set, causes a hop naming this component, as returned
+	// by CurrentHop, to be added to the provenance of each diagnostic as
+	// it's reported, so that the remote collector can tell where it came
+	// from.
+	Component string
+
+	// MaxAttempts is the number of times to try sending each batch before
+	// discarding it. Defaults to five.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry of a batch, which
+	// doubles for each subsequent retry up to MaxBackoff. Defaults to one
+	// second, with a MaxBackoff of one minute.
+	Backoff, MaxBackoff time.Duration
+}
+
+// RemoteSink is a Sink that buffers the diagnostics it receives and sends
+// them in batches to a remote collector using a Transport, retrying failed
+// batches with exponential backoff.
+//
+// A RemoteSink sends diagnostics from a background goroutine, so Report
+// never blocks on the network. Call Close to send any remaining buffered
+// diagnostics and stop the background goroutine. Diagnostics reported after
+// Close are discarded and counted by Dropped.
+type RemoteSink struct {
+	transport Transport
+	opts      RemoteSinkOptions
+
+	mu      sync.Mutex
+	buf     Diagnostics
+	dropped int
+	lastErr error
+	closed  bool
+
+	sendMu sync.Mutex
+	wake   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ Sink = (*RemoteSink)(nil)
+
+// NewRemoteSink returns a RemoteSink that sends diagnostics using the given
+// transport, and starts its background goroutine.
+func NewRemoteSink(transport Transport, opts RemoteSinkOptions) *RemoteSink {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxBuffered <= 0 {
+		opts.MaxBuffered = 10000
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &RemoteSink{
+		transport: transport,
+		opts:      opts,
+		wake:      make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Report implements Sink.
+func (s *RemoteSink) Report(diags Diagnostics) {
+	if len(diags) == 0 {
+		return
+	}
+
+	if s.opts.Component != "" {
+		diags = diags.WithHop(CurrentHop(s.opts.Component))
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.dropped += len(diags)
+		s.mu.Unlock()
+		return
+	}
+	s.buf = append(s.buf, diags...)
+	if over := len(s.buf) - s.opts.MaxBuffered; over > 0 {
+		s.buf = append(Diagnostics(nil), s.buf[over:]...)
+		s.dropped += over
+	}
+	full := len(s.buf) >= s.opts.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+			// A flush is already pending
+		}
+	}
+}
+
+// Flush sends all of the currently-buffered diagnostics, returning the
+// error from the last failed batch, if any.
+func (s *RemoteSink) Flush(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	var lastErr error
+	for {
+		s.mu.Lock()
+		n := len(s.buf)
+		if n > s.opts.BatchSize {
+			n = s.opts.BatchSize
+		}
+		batch := s.buf[:n:n]
+		s.buf = s.buf[n:]
+		s.mu.Unlock()
+
+		if len(batch) == 0 {
+			return lastErr
+		}
+		err := s.send(ctx, batch)
+		if err != nil && ctx.Err() != nil {
+			// We were interrupted before using all of our attempts, so
+			// we'll put the batch back to be sent by a later flush.
+			s.mu.Lock()
+			s.buf = append(batch, s.buf...)
+			s.mu.Unlock()
+			return ctx.Err()
+		}
+		if err != nil {
+			lastErr = err
+			s.mu.Lock()
+			s.dropped += len(batch)
+			s.lastErr = err
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close stops the background goroutine and then sends any diagnostics that
+// remain buffered, giving up when the given context is cancelled.
+func (s *RemoteSink) Close(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+	s.cancel()
+	<-s.done
+	return s.Flush(ctx)
+}
+
+// Dropped returns the number of diagnostics that have been discarded so far,
+// because the buffer was full, because all attempts to send them failed or
+// because they were reported after Close, along with the most recent error from the transport.
+func (s *RemoteSink) Dropped() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped, s.lastErr
+}
+
+func (s *RemoteSink) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.opts.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.wake:
+		case <-s.ctx.Done():
+			return
+		}
+		s.Flush(s.ctx)
+	}
+}
+
+func (s *RemoteSink) send(ctx context.Context, batch Diagnostics) error {
+	backoff := s.opts.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = s.transport.Send(ctx, batch)
+		if err == nil || attempt >= s.opts.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		backoff *= 2
+		if backoff > s.opts.MaxBackoff {
+			backoff = s.opts.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package tbdiags
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRemoteSink(t *testing.T) {
+	transport := &flakyTransport{failures: 1}
+	sink := NewRemoteSink(transport, RemoteSinkOptions{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Backoff:       time.Millisecond,
+	})
+
+	sink.Report(Diagnostics{
+		Sourceless(Warning, "First", ""),
+		Sourceless(Warning, "Second", ""),
+		Sourceless(Warning, "Third", ""),
+	})
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if got, want := len(transport.received), 3; got != want {
+		t.Errorf("wrong number of diagnostics received %d; want %d", got, want)
+	}
+	if transport.attempts < 3 {
+		t.Errorf("wrong number of attempts %d; want at least 3", transport.attempts)
+	}
+	if dropped, _ := sink.Dropped(); dropped != 0 {
+		t.Errorf("%d diagnostics were dropped", dropped)
+	}
+
+	sink.Report(Diagnostics{Sourceless(Warning, "Too late", "")})
+	if got, want := len(transport.received), 3; got != want {
+		t.Errorf("diagnostic reported after Close was sent")
+	}
+	if dropped, _ := sink.Dropped(); dropped != 1 {
+		t.Errorf("%d diagnostics were dropped after Close; want 1", dropped)
+	}
+}
+
+func TestRemoteSinkGiveUp(t *testing.T) {
+	transport := &flakyTransport{failures: 100}
+	sink := NewRemoteSink(transport, RemoteSinkOptions{
+		FlushInterval: time.Hour,
+		MaxAttempts:   2,
+		Backoff:       time.Millisecond,
+	})
+
+	sink.Report(Diagnostics{Sourceless(Warning, "First", "")})
+	if err := sink.Close(context.Background()); err == nil {
+		t.Fatalf("no error")
+	}
+	dropped, err := sink.Dropped()
+	if dropped != 1 {
+		t.Errorf("wrong number of dropped diagnostics %d; want 1", dropped)
+	}
+	if err == nil {
+		t.Errorf("no last error")
+	}
+}
+
+func TestRemoteSinkComponent(t *testing.T) {
+	transport := &flakyTransport{}
+	sink := NewRemoteSink(transport, RemoteSinkOptions{Component: "worker"})
+	sink.Report(Diagnostics{Sourceless(Warning, "Slow", "")})
+	if err := sink.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	if len(transport.received) != 1 {
+		t.Fatalf("wrong number of diagnostics received %d", len(transport.received))
+	}
+	if hops := ProvenanceOf(transport.received[0]); len(hops) != 1 || hops[0].Component != "worker" {
+		t.Errorf("wrong provenance %#v", hops)
+	}
+}
+
+type flakyTransport struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	received Diagnostics
+}
+
+func (t *flakyTransport) Send(ctx context.Context, diags Diagnostics) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.attempts++
+	if t.failures > 0 {
+		t.failures--
+		return errors.New("collector unavailable")
+	}
+	t.received = append(t.received, diags...)
+	return nil
+}
@@ -0,0 +1,343 @@
+package tbdiags
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Renderer renders diagnostics as human-oriented text, such as for display
+// in a terminal.
+//
+// The zero value of Renderer renders all diagnostics without color, with
+// filenames relative to the current working directory. The additional
+// information of diagnostics that implement the optional interfaces of this
+// package, such as DiagnosticRelated, is rendered as described by each
+// interface.
+type Renderer struct {
+	// Paths decides how the filenames in source ranges are displayed.
+	Paths PathPolicy
+
+	// Color enables the use of ANSI terminal escape sequences to highlight
+	// the severity and summary of each diagnostic.
+	Color bool
+
+	// Theme chooses the escape sequences used when Color is set. If nil,
+	// ThemeDefault is used.
+	Theme *Theme
+
+	// MaxPerFile, if greater than zero, limits the number of diagnostics
+	// rendered for each file, so that a single badly-broken file can't
+	// crowd out all of the others. The number of diagnostics omitted for
+	// each file is noted after the last one rendered for that file.
+	MaxPerFile int
+
+	// FoldDetail causes only the first paragraph of each diagnostic's
+	// detail to be rendered, as returned by Description.DetailParts,
+	// followed by a note if any of the detail was omitted.
+	FoldDetail bool
+
+	// GroupByFile causes diagnostics to be rendered in sections for each
+	// file, as returned by Diagnostics.ByFile, with each section preceded
+	// by a heading. Diagnostics without a subject are rendered first, in a
+	// section headed "General".
+	GroupByFile bool
+
+	// Verbose causes additional information that is usually only of
+	// interest when debugging to be included, such as whether a
+	// diagnostic's severity was changed by a policy, when it was produced,
+	// the underlying causes of the error it was created from, which hosts
+	// and processes it passed through, the stack trace of the panic it
+	// describes and, in development mode, where in the Go source code it
+	// was reported.
+	Verbose bool
+
+	// Audience decides whether operator-only information, from
+	// diagnostics implementing DiagnosticOperatorDetail, is included.
+	// The default is AudienceUser, which excludes it.
+	Audience Audience
+
+	// SeparateDeprecations causes deprecations, as identified by
+	// IsDeprecation, to be rendered after all others, in their own
+	// section headed "Deprecations", so that they don't distract from
+	// problems that need attention sooner.
+	SeparateDeprecations bool
+
+	// Sources, if set, maps filenames to the contents of those files, as
+	// returned by SourcesFor. The line containing the start of each
+	// diagnostic's subject is then shown if its file is present, with the
+	// range underlined for ranges with PrecisionExact. When Color is also
+	// set, the range is struck through for diagnostics tagged TagDeprecated
+	// and faded for those tagged TagUnnecessary, as editors show them.
+	Sources map[string][]byte
+}
+
+const (
+	// renderWidth is the width that the renderer wraps generated text to.
+	renderWidth = 78
+
+	// maxRenderedValidValues is the maximum number of valid values that the
+	// renderer will list for a diagnostic implementing
+	// DiagnosticValidValues.
+	maxRenderedValidValues = 20
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+	ansiFaint  = "\x1b[2m"
+	ansiStrike = "\x1b[9m"
+)
+
+// snippetStyle returns the escape sequence to apply to the subject range
+// in the source snippet of the given diagnostic, which follows the
+// conventions of editors for diagnostics with some tags.
+func (r *Renderer) snippetStyle(diag Diagnostic) string {
+	switch {
+	case !r.Color:
+		return ""
+	case HasTag(diag, TagDeprecated):
+		return ansiStrike
+	case HasTag(diag, TagUnnecessary):
+		return ansiFaint
+	default:
+		return ""
+	}
+}
+
+// Render writes the given diagnostics to the given writer, in the order
+// they are given. Callers will typically want to call Diagnostics.Sort
+// first.
+func (r *Renderer) Render(w io.Writer, diags Diagnostics) error {
+	bw := bufio.NewWriter(w)
+
+	if r.SeparateDeprecations {
+		deprecations, others := diags.partitionDeprecations()
+		if len(deprecations) > 0 {
+			inner := *r
+			inner.SeparateDeprecations = false
+			inner.Render(bw, others)
+			fmt.Fprintf(bw, "--- Deprecations ---\n\n")
+			inner.Render(bw, deprecations)
+			return bw.Flush()
+		}
+	}
+
+	ids := make(fileIDCache)
+	var totals, counts map[FileID]int
+	if r.MaxPerFile > 0 {
+		totals = make(map[FileID]int)
+		counts = make(map[FileID]int)
+		for _, diag := range diags {
+			if subject := subjectOf(diag); subject != nil {
+				totals[ids.SubjectID(subject)]++
+			}
+		}
+	}
+
+	if !r.GroupByFile {
+		r.renderList(bw, diags, ids, totals, counts)
+		return bw.Flush()
+	}
+
+	for _, group := range diags.ByFile() {
+		heading := "General"
+		if !group.IsGeneral() {
+			subject := subjectOf(group.Diagnostics[0])
+			heading = subject.Kind.Describe(subject.Filename)
+			if subject.Kind == SubjectFile {
+				heading = r.Paths.DisplayPath(subject.Filename)
+			}
+		}
+		fmt.Fprintf(bw, "--- %s ---\n\n", heading)
+		r.renderList(bw, group.Diagnostics, ids, totals, counts)
+	}
+	return bw.Flush()
+}
+
+func (r *Renderer) renderList(w *bufio.Writer, diags Diagnostics, ids fileIDCache, totals, counts map[FileID]int) {
+	heading := ""
+	for _, diag := range diags {
+		// Diagnostics that belong to groups get a heading whenever the
+		// group changes. Diagnostics without a group only get one if
+		// they follow a group, so they don't appear to belong to it.
+		if group := GroupOf(diag); len(group) > 0 || heading != "" {
+			if h := groupHeading(group); h != heading {
+				fmt.Fprintf(w, "=== %s ===\n\n", h)
+				heading = h
+			}
+		}
+
+		subject := subjectOf(diag)
+		if r.MaxPerFile > 0 && subject != nil {
+			id := ids.SubjectID(subject)
+			counts[id]++
+			switch count := counts[id]; {
+			case count > r.MaxPerFile:
+				continue
+			case count == r.MaxPerFile && totals[id] > count:
+				r.renderDiagnostic(w, diag)
+				fmt.Fprintf(w, "(%s in %s)\n\n", FormatCount(totals[id]-count, NounMore), r.Paths.DisplayPath(subject.Filename))
+				continue
+			}
+		}
+		r.renderDiagnostic(w, diag)
+	}
+}
+
+// renderRelated writes one of the secondary locations of a diagnostic,
+// followed by its snippet if the source is available.
+func (r *Renderer) renderRelated(w *bufio.Writer, info RelatedInfo) {
+	rng := &info.Range
+	if rng.Precision == PrecisionNone {
+		fmt.Fprintf(w, "\n  %s\n", info.Message)
+		return
+	}
+	fmt.Fprintf(w, "\n  %s: %s\n", info.Message, rng.StartStringWith(r.Paths))
+	if rng.Kind == SubjectFile {
+		if src, ok := r.Sources[rng.Filename]; ok {
+			writeSnippet(w, rng, src, "")
+		}
+	}
+}
+
+// RenderString is like Render except that it returns the result as a string.
+func (r *Renderer) RenderString(diags Diagnostics) string {
+	var buf strings.Builder
+	r.Render(&buf, diags) // can't fail when writing to a strings.Builder
+	return buf.String()
+}
+
+func (r *Renderer) renderDiagnostic(w *bufio.Writer, diag Diagnostic) {
+	desc := diag.Description()
+	sev := diag.Severity()
+	if origin := OriginOf(diag); origin != "" {
+		desc.Summary = "[" + origin + "] " + desc.Summary
+	}
+	if r.Verbose {
+		if at := ReportedAt(diag); at != "" {
+			desc.Summary += " (reported at " + at + ")"
+		}
+	}
+	label := sev.String()
+	if category := CategoryOf(diag); category != CategoryNone {
+		label += " (" + category.String() + ")"
+	}
+	if desc.Code != "" {
+		label += " [" + desc.Code + "]"
+	}
+
+	if r.Color {
+		theme := ThemeDefault
+		if r.Theme != nil {
+			theme = *r.Theme
+		}
+		fmt.Fprintf(w, "%s%s: %s%s\n", theme.forSeverity(sev), label, desc.Summary, ansiReset)
+	} else {
+		fmt.Fprintf(w, "%s: %s\n", label, desc.Summary)
+	}
+
+	if subject := subjectOf(diag); subject != nil {
+		fmt.Fprintf(w, "  on %s", subject.StartStringWith(r.Paths))
+		if desc.Address != "" {
+			fmt.Fprintf(w, ", in %s", desc.Address)
+		}
+		w.WriteByte('\n')
+	} else if desc.Address != "" {
+		fmt.Fprintf(w, "  in %s\n", desc.Address)
+	}
+	if emitter, ok := EmitterOf(diag); ok {
+		fmt.Fprintf(w, "  (emitted by %s)\n", emitter)
+	}
+	if r.Verbose {
+		if esc, ok := EscalationOf(diag); ok {
+			change := "changed"
+			switch level := sev.Level(); {
+			case level > esc.From.Level():
+				change = "escalated"
+			case level < esc.From.Level():
+				change = "demoted"
+			}
+			fmt.Fprintf(w, "  (%s from %s by %s)\n", change, esc.From, esc.Policy)
+		}
+		if ts, ok := TimestampOf(diag); ok {
+			fmt.Fprintf(w, "  (produced at %s)\n", ts.Format(time.RFC3339))
+		}
+		if cause := CauseOf(diag); cause != nil {
+			for err := errors.Unwrap(cause); err != nil; err = errors.Unwrap(err) {
+				fmt.Fprintf(w, "  (caused by %T: %s)\n", err, err)
+			}
+		}
+		for _, hop := range ProvenanceOf(diag) {
+			fmt.Fprintf(w, "  (via %s)\n", hop)
+		}
+		if info, ok := panicInfoOf(diag); ok {
+			w.WriteString("  (panicked with stack trace:)\n")
+			writeIndented(w, strings.TrimRight(string(info.Stack), "\n")+"\n", "    ")
+		}
+	}
+	if subject := subjectOf(diag); subject != nil && subject.Kind == SubjectFile {
+		if src, ok := r.Sources[subject.Filename]; ok {
+			writeSnippet(w, subject, src, r.snippetStyle(diag))
+		}
+	}
+	for _, info := range RelatedOf(diag) {
+		r.renderRelated(w, info)
+	}
+
+	if r.FoldDetail {
+		first, rest := desc.DetailParts()
+		if first != "" {
+			fmt.Fprintf(w, "\n%s\n", first)
+		}
+		if rest != "" {
+			w.WriteString("(more detail omitted)\n")
+		}
+	} else if desc.Detail != "" {
+		fmt.Fprintf(w, "\n%s\n", desc.Detail)
+	}
+	if values := ValidValues(diag); len(values) > 0 {
+		fmt.Fprintf(w, "\n%s\n", formatValidValues(values, renderWidth, maxRenderedValidValues))
+	}
+	if fixes := FixesOf(diag); len(fixes) > 0 {
+		fmt.Fprintf(w, "\nSuggested fix: %s\n", fixes[0].Message)
+	}
+	if desc.HelpURL != "" {
+		fmt.Fprintf(w, "\nFor more information, see %s\n", desc.HelpURL)
+	}
+	if r.Audience == AudienceOperator {
+		if detail := OperatorDetail(diag); detail != "" {
+			fmt.Fprintf(w, "\nOperator detail:\n%s\n", detail)
+		}
+	}
+	w.WriteByte('\n')
+
+	if children := ChildrenOf(diag); len(children) > 0 {
+		// Each child is rendered in full, including its own children,
+		// and then indented beneath its parent.
+		var buf strings.Builder
+		cw := bufio.NewWriter(&buf)
+		for _, child := range children {
+			r.renderDiagnostic(cw, child)
+		}
+		cw.Flush()
+		writeIndented(w, buf.String(), "    ")
+	}
+}
+
+// writeIndented writes the given text with the given prefix at the start of
+// each line that isn't empty.
+func writeIndented(w *bufio.Writer, text, prefix string) {
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line != "" && line != "\n" {
+			w.WriteString(prefix)
+		}
+		w.WriteString(line)
+	}
+}
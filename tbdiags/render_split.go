@@ -0,0 +1,42 @@
+package tbdiags
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// RenderSplit writes the given diagnostics to two writers at once: the JSON
+// representation produced by Diagnostics.MarshalJSON to machineW, for
+// automation, and the human-oriented text produced by Render to humanW, so
+// that a pipeline can have both without rendering the diagnostics twice.
+//
+// The JSON is encoded one diagnostic at a time rather than all at once, so
+// that very large sets of diagnostics don't need to be held in memory in
+// both forms. RenderSplit returns the first error from either writer.
+func (r *Renderer) RenderSplit(machineW, humanW io.Writer, diags Diagnostics) error {
+	err := writeJSONStream(machineW, diags)
+	if humanErr := r.Render(humanW, diags); err == nil {
+		err = humanErr
+	}
+	return err
+}
+
+// writeJSONStream writes the same output as Diagnostics.MarshalJSON to the
+// given writer, encoding one diagnostic at a time.
+func writeJSONStream(w io.Writer, diags Diagnostics) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteByte('[')
+	for i, diag := range diags {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		src, err := json.Marshal(newJSONDiagnostic(diag, PositionOptions{}))
+		if err != nil {
+			return err
+		}
+		bw.Write(src)
+	}
+	bw.WriteByte(']')
+	return bw.Flush()
+}
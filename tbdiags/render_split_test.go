@@ -0,0 +1,48 @@
+package tbdiags
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRendererRenderSplit(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Error, "Bad thing", "It's <bad>."),
+		WithCode(Sourceless(Warning, "Odd thing", ""), "TB2001"),
+	}
+	r := &Renderer{}
+
+	var machine, human strings.Builder
+	if err := r.RenderSplit(&machine, &human, diags); err != nil {
+		t.Fatal(err)
+	}
+	wantMachine, err := diags.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := machine.String(), string(wantMachine); got != want {
+		t.Errorf("wrong machine output\ngot:  %s\nwant: %s", got, want)
+	}
+	if got, want := human.String(), r.RenderString(diags); got != want {
+		t.Errorf("wrong human output\ngot:  %s\nwant: %s", got, want)
+	}
+
+	machine.Reset()
+	if err := r.RenderSplit(&machine, &human, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := machine.String(), "[]"; got != want {
+		t.Errorf("wrong machine output for no diagnostics\ngot:  %s\nwant: %s", got, want)
+	}
+
+	if err := r.RenderSplit(failingWriter{}, &human, diags); err == nil {
+		t.Errorf("no error from failing machine writer")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
@@ -0,0 +1,134 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestRendererRender(t *testing.T) {
+	diag := func(severity Severity, summary, detail, filename string, line int) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: severity,
+				summary:  summary,
+				detail:   detail,
+			},
+			subject: &SourceRange{
+				Filename: filename,
+				Start:    SourcePos{Line: line, Column: 1},
+				End:      SourcePos{Line: line, Column: 2},
+			},
+		}
+	}
+	diags := Diagnostics{
+		Sourceless(Warning, "Dubious thing", ""),
+		diag(Error, "Bad thing", "It went wrong.", "a.tb", 1),
+		diag(Error, "Bad thing", "", "a.tb", 2),
+		diag(Error, "Bad thing", "", "a.tb", 3),
+		diag(Error, "Bad thing", "", "b.tb", 1),
+	}
+
+	t.Run("default", func(t *testing.T) {
+		r := &Renderer{}
+		got := r.RenderString(diags)
+		want := `Warning: Dubious thing
+
+Error: Bad thing
+  on a.tb:1,1
+
+It went wrong.
+
+Error: Bad thing
+  on a.tb:2,1
+
+Error: Bad thing
+  on a.tb:3,1
+
+Error: Bad thing
+  on b.tb:1,1
+
+`
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+	t.Run("max per file", func(t *testing.T) {
+		r := &Renderer{MaxPerFile: 1}
+		got := r.RenderString(diags)
+		want := `Warning: Dubious thing
+
+Error: Bad thing
+  on a.tb:1,1
+
+It went wrong.
+
+(2 more in a.tb)
+
+Error: Bad thing
+  on b.tb:1,1
+
+`
+		if got != want {
+			t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+		}
+	})
+}
+
+func TestRendererFoldDetail(t *testing.T) {
+	r := &Renderer{FoldDetail: true}
+	got := r.RenderString(Diagnostics{
+		Sourceless(Error, "Bad thing", "It went wrong.\n\nHere is a lot more about why."),
+	})
+	want := `Error: Bad thing
+
+It went wrong.
+(more detail omitted)
+
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRendererGroupByFile(t *testing.T) {
+	diag := func(summary, filename string) Diagnostic {
+		return sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{
+				severity: Error,
+				summary:  summary,
+			},
+			subject: &SourceRange{
+				Filename: filename,
+				Start:    SourcePos{Line: 1, Column: 1},
+				End:      SourcePos{Line: 1, Column: 2},
+			},
+		}
+	}
+	r := &Renderer{GroupByFile: true}
+	got := r.RenderString(Diagnostics{
+		diag("First", "a.tb"),
+		Sourceless(Warning, "Dubious thing", ""),
+		diag("Second", "b.tb"),
+		diag("Third", "a.tb"),
+	})
+	want := `--- General ---
+
+Warning: Dubious thing
+
+--- a.tb ---
+
+Error: First
+  on a.tb:1,1
+
+Error: Third
+  on a.tb:1,1
+
+--- b.tb ---
+
+Error: Second
+  on b.tb:1,1
+
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
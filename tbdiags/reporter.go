@@ -0,0 +1,112 @@
+package tbdiags
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Reporter is implemented by types that report both the progress of a
+// sequence of tasks and any diagnostics produced by those tasks, so that
+// the two can be presented together in the order they happened rather than
+// progress messages being followed by all of the diagnostics at the end.
+type Reporter interface {
+	// StartTask reports that a task with the given name has started.
+	StartTask(name string)
+
+	// EndTask reports that the task with the given name has finished.
+	EndTask(name string)
+
+	// Diag reports diagnostics produced by the tasks currently in progress.
+	Diag(diags Diagnostics)
+}
+
+// TerminalReporter is a Reporter that writes human-oriented text to a
+// terminal, rendering diagnostics as soon as they are reported.
+//
+// The end of each task is reported along with the number of errors and
+// warnings reported while it was in progress. Tasks may be nested, in which
+// case diagnostics count towards all of the tasks in progress.
+type TerminalReporter struct {
+	w        io.Writer
+	renderer *Renderer
+
+	mu    sync.Mutex
+	tasks []*reporterTask
+}
+
+type reporterTask struct {
+	name             string
+	errors, warnings int
+}
+
+var _ Reporter = (*TerminalReporter)(nil)
+
+// NewTerminalReporter returns a TerminalReporter that writes to the given
+// writer, using the given renderer for diagnostics. If the renderer is nil,
+// a zero-value Renderer is used.
+func NewTerminalReporter(w io.Writer, renderer *Renderer) *TerminalReporter {
+	if renderer == nil {
+		renderer = &Renderer{}
+	}
+	return &TerminalReporter{
+		w:        w,
+		renderer: renderer,
+	}
+}
+
+// StartTask implements Reporter.
+func (r *TerminalReporter) StartTask(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tasks = append(r.tasks, &reporterTask{name: name})
+	fmt.Fprintf(r.w, "%s...\n", name)
+}
+
+// EndTask implements Reporter.
+//
+// Ending a task that isn't in progress only writes the end message.
+func (r *TerminalReporter) EndTask(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	task := &reporterTask{name: name}
+	for i := len(r.tasks) - 1; i >= 0; i-- {
+		if r.tasks[i].name == name {
+			task = r.tasks[i]
+			r.tasks = append(r.tasks[:i], r.tasks[i+1:]...)
+			break
+		}
+	}
+
+	switch {
+	case task.errors > 0:
+		fmt.Fprintf(r.w, "%s: failed with %s\n", name, FormatCount(task.errors, NounError))
+	case task.warnings > 0:
+		fmt.Fprintf(r.w, "%s: done with %s\n", name, FormatCount(task.warnings, NounWarning))
+	default:
+		fmt.Fprintf(r.w, "%s: done\n", name)
+	}
+}
+
+// Diag implements Reporter.
+func (r *TerminalReporter) Diag(diags Diagnostics) {
+	if len(diags) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, diag := range diags {
+		for _, task := range r.tasks {
+			if diag.Severity().isError() {
+				task.errors++
+			} else {
+				task.warnings++
+			}
+		}
+	}
+	r.renderer.Render(r.w, diags)
+}
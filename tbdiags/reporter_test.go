@@ -0,0 +1,36 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerminalReporter(t *testing.T) {
+	var buf strings.Builder
+	r := NewTerminalReporter(&buf, nil)
+
+	r.StartTask("Loading configuration")
+	r.EndTask("Loading configuration")
+	r.StartTask("Validating")
+	r.Diag(Diagnostics{Sourceless(Warning, "Dubious thing", "")})
+	r.EndTask("Validating")
+	r.StartTask("Applying")
+	r.Diag(Diagnostics{Sourceless(Error, "Bad thing", "")})
+	r.EndTask("Applying")
+
+	got := buf.String()
+	want := `Loading configuration...
+Loading configuration: done
+Validating...
+Warning: Dubious thing
+
+Validating: done with 1 warning
+Applying...
+Error: Bad thing
+
+Applying: failed with 1 error
+`
+	if got != want {
+		t.Errorf("wrong output\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
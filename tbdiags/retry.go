@@ -0,0 +1,133 @@
+package tbdiags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DiagnosticRetryable is an optional interface implemented by diagnostics
+// that can say whether the problem they describe is transient, such that
+// retrying the operation that produced them might succeed.
+type DiagnosticRetryable interface {
+	Retryable() bool
+}
+
+// MarkRetryable returns a diagnostic that is the same as the given
+// diagnostic except that it also implements DiagnosticRetryable, returning
+// true.
+func MarkRetryable(diag Diagnostic) Diagnostic {
+	return retryableDiagnostic{diag}
+}
+
+// IsRetryable returns true if the given diagnostic describes a transient
+// problem. That is the case for diagnostics that implement
+// DiagnosticRetryable and return true, and for diagnostics created by
+// Diagnostics.Append or Wrap from an error that is, or wraps, an error with a
+// Temporary or Timeout method returning true, such as many errors from the
+// net package.
+func IsRetryable(diag Diagnostic) bool {
+	var ret bool
+	found := findDiagnostic(diag, func(diag Diagnostic) bool {
+		r, ok := diag.(DiagnosticRetryable)
+		if ok {
+			ret = r.Retryable()
+		}
+		return ok
+	})
+	if found {
+		return ret
+	}
+
+	err := CauseOf(diag)
+	if err == nil {
+		return false
+	}
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// RetryPolicy controls how Retry retries an operation.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times to call the operation,
+	// including the first. Defaults to three.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, which doubles for each
+	// subsequent retry up to MaxBackoff. Defaults to one second, with a
+	// MaxBackoff of thirty seconds.
+	Backoff, MaxBackoff time.Duration
+}
+
+// Retry calls the given operation, and calls it again for as long as all of
+// the errors in its diagnostics are retryable, as decided by IsRetryable,
+// until it either returns no errors, returns an error that isn't retryable,
+// runs out of attempts, or the given context is cancelled.
+//
+// The result is the diagnostics from the last attempt, preceded by a
+// warning for each earlier attempt that failed, which includes the errors
+// that caused it to be retried.
+func Retry(ctx context.Context, policy RetryPolicy, op func() Diagnostics) Diagnostics {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = time.Second
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = 30 * time.Second
+	}
+
+	var warnings Diagnostics
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		diags := op()
+		if !diags.HasErrors() || attempt >= policy.MaxAttempts || !allErrorsRetryable(diags) {
+			return warnings.Append(diags)
+		}
+
+		warnings = warnings.Append(Sourceless(
+			Warning,
+			fmt.Sprintf("Attempt %d of %d failed", attempt, policy.MaxAttempts),
+			fmt.Sprintf("The operation will be retried after %s because of a transient problem: %s", backoff, diags.Err()),
+		))
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return warnings.Append(diags)
+		}
+		backoff *= 2
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
+
+func allErrorsRetryable(diags Diagnostics) bool {
+	for _, diag := range diags {
+		if diag.Severity().isError() && !IsRetryable(diag) {
+			return false
+		}
+	}
+	return true
+}
+
+type retryableDiagnostic struct {
+	Diagnostic
+}
+
+func (d retryableDiagnostic) Retryable() bool {
+	return true
+}
+
+func (d retryableDiagnostic) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,86 @@
+package tbdiags
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}
+	transient := Diagnostics{MarkRetryable(Sourceless(Error, "Connection refused", ""))}
+
+	t.Run("succeeds after retry", func(t *testing.T) {
+		calls := 0
+		diags := Retry(context.Background(), policy, func() Diagnostics {
+			calls++
+			if calls < 2 {
+				return transient
+			}
+			return nil
+		})
+		if calls != 2 {
+			t.Errorf("operation called %d times; want 2", calls)
+		}
+		if diags.HasErrors() || len(diags) != 1 {
+			t.Fatalf("wrong diagnostics\n%s", diags.ErrWithWarnings())
+		}
+		if got, want := diags[0].Description().Summary, "Attempt 1 of 3 failed"; got != want {
+			t.Errorf("wrong warning %q; want %q", got, want)
+		}
+	})
+	t.Run("gives up", func(t *testing.T) {
+		calls := 0
+		diags := Retry(context.Background(), policy, func() Diagnostics {
+			calls++
+			return transient
+		})
+		if calls != 3 {
+			t.Errorf("operation called %d times; want 3", calls)
+		}
+		if len(diags) != 3 || !diags.HasErrors() {
+			t.Errorf("wrong diagnostics\n%s", diags.ErrWithWarnings())
+		}
+	})
+	t.Run("permanent error", func(t *testing.T) {
+		calls := 0
+		Retry(context.Background(), policy, func() Diagnostics {
+			calls++
+			return Diagnostics{Sourceless(Error, "Invalid configuration", "")}
+		})
+		if calls != 1 {
+			t.Errorf("operation called %d times; want 1", calls)
+		}
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	var diags Diagnostics
+	diags = diags.Append(
+		fmt.Errorf("dialing: %w", timeoutError{}),
+		fmt.Errorf("plain"),
+	)
+	if !IsRetryable(diags[0]) {
+		t.Errorf("wrapped timeout error is not retryable")
+	}
+	if IsRetryable(diags[1]) {
+		t.Errorf("plain error is retryable")
+	}
+	if !IsRetryable(WithOrigin(diags[0], "dialer")) {
+		t.Errorf("timeout error with an origin is not retryable")
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string {
+	return "i/o timeout"
+}
+
+func (timeoutError) Timeout() bool {
+	return true
+}
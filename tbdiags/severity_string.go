@@ -8,19 +8,29 @@ func _() {
 	var x [1]struct{}
 	_ = x[Error-69]
 	_ = x[Warning-87]
+	_ = x[Hint-72]
+	_ = x[Fatal-70]
 }
 
 const (
-	_Severity_name_0 = "Error"
-	_Severity_name_1 = "Warning"
+	_Severity_name_0 = "ErrorFatal"
+	_Severity_name_1 = "Hint"
+	_Severity_name_2 = "Warning"
+)
+
+var (
+	_Severity_index_0 = [...]uint8{0, 5, 10}
 )
 
 func (i Severity) String() string {
 	switch {
-	case i == 69:
-		return _Severity_name_0
-	case i == 87:
+	case 69 <= i && i <= 70:
+		i -= 69
+		return _Severity_name_0[_Severity_index_0[i]:_Severity_index_0[i+1]]
+	case i == 72:
 		return _Severity_name_1
+	case i == 87:
+		return _Severity_name_2
 	default:
 		return "Severity(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -5,8 +5,13 @@ type simpleWarning string
 var _ Diagnostic = simpleWarning("")
 
 // SimpleWarning constructs a simple (summary-only) warning diagnostic.
+//
+// SimpleWarning keeps its original signature so that existing callers
+// continue to compile. Callers that want to give the warning a detail can
+// migrate to SimpleWarningDetail, such as replacing SimpleWarning(msg) with
+// SimpleWarningDetail(msg, detail).
 func SimpleWarning(msg string) Diagnostic {
-	return simpleWarning(msg)
+	return withCaller(simpleWarning(msg))
 }
 
 func (e simpleWarning) Severity() Severity {
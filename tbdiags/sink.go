@@ -0,0 +1,13 @@
+package tbdiags
+
+// Sink is implemented by types that receive diagnostics as they are
+// produced, so that they can be recorded or reported somewhere other than
+// the usual return path.
+type Sink interface {
+	// Report delivers the given diagnostics to the sink.
+	//
+	// Implementations must be safe to call concurrently, and must not
+	// modify or retain the given slice itself, although they may retain
+	// the diagnostics it contains.
+	Report(diags Diagnostics)
+}
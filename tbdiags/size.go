@@ -0,0 +1,36 @@
+package tbdiags
+
+// These are rough estimates of the fixed memory overhead of a diagnostic and
+// of a source range, beyond the strings they refer to, used by
+// EstimatedSize.
+const (
+	diagnosticOverhead  = 96
+	sourceRangeOverhead = 80
+)
+
+// EstimatedSize returns a rough estimate of the number of bytes of memory
+// retained by the diagnostics in the receiver, including their descriptions
+// and source ranges.
+//
+// The estimate is intended for enforcing limits on memory use, such as with
+// Collector.MaxSize, and is not exact: it can't see any additional data held
+// by diagnostic implementations outside of this package.
+func (diags Diagnostics) EstimatedSize() int {
+	size := 0
+	for _, diag := range diags {
+		size += estimatedSize(diag)
+	}
+	return size
+}
+
+func estimatedSize(diag Diagnostic) int {
+	desc := diag.Description()
+	size := diagnosticOverhead + len(desc.Summary) + len(desc.Detail) + len(desc.Address)
+	src := diag.Source()
+	for _, rng := range []*SourceRange{src.Subject, src.Context} {
+		if rng != nil {
+			size += sourceRangeOverhead + len(rng.Filename)
+		}
+	}
+	return size
+}
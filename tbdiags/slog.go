@@ -0,0 +1,191 @@
+//go:build go1.21
+// +build go1.21
+
+package tbdiags
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// FromRecords converts structured log records into diagnostics, so that
+// warnings logged by embedded libraries can be shown to the user as real
+// diagnostics.
+//
+// Records at slog.LevelError or above become errors, and all others become
+// warnings. The record message becomes the summary, and the following
+// attributes are recognized:
+//
+//   - "detail" and "address" populate the corresponding Description fields.
+//   - "file" or "filename", along with optional "line" and "column",
+//     populate the subject range. If there is no "line" attribute then
+//     the range has PrecisionFile, and otherwise if there is no "column"
+//     attribute then it has PrecisionLine.
+//
+// Any other attributes are appended to the detail as "key=value" lines.
+//
+// The "line" and "column" attributes are expected to be numbered from one.
+// Use FromRecordsWith for records that follow other conventions.
+func FromRecords(records []slog.Record) Diagnostics {
+	return FromRecordsWith(records, PositionOptions{})
+}
+
+// FromRecordsWith is like FromRecords except that the "line" and "column"
+// attributes are interpreted according to the given options.
+func FromRecordsWith(records []slog.Record, opts PositionOptions) Diagnostics {
+	var diags Diagnostics
+	for _, record := range records {
+		diags = diags.Append(fromRecord(record, opts))
+	}
+	return diags
+}
+
+func fromRecord(record slog.Record, opts PositionOptions) Diagnostic {
+	severity := Warning
+	if record.Level >= slog.LevelError {
+		severity = Error
+	}
+
+	var detail, address string
+	var subject *SourceRange
+	hasLine, hasColumn := false, false
+	var extra []string
+	record.Attrs(func(attr slog.Attr) bool {
+		switch attr.Key {
+		case "detail":
+			detail = attr.Value.String()
+		case "address":
+			address = attr.Value.String()
+		case "file", "filename":
+			if subject == nil {
+				subject = &SourceRange{}
+			}
+			subject.Filename = attr.Value.String()
+		case "line", "column":
+			if subject == nil {
+				subject = &SourceRange{}
+			}
+			var n int
+			switch attr.Value.Kind() {
+			case slog.KindInt64:
+				n = int(attr.Value.Int64())
+			case slog.KindUint64:
+				n = int(attr.Value.Uint64())
+			default:
+				fmt.Sscan(attr.Value.String(), &n)
+			}
+			if attr.Key == "line" {
+				subject.Start.Line = n
+				hasLine = true
+			} else {
+				subject.Start.Column = n
+				hasColumn = true
+			}
+		default:
+			extra = append(extra, fmt.Sprintf("%s=%s", attr.Key, attr.Value))
+		}
+		return true
+	})
+	if len(extra) > 0 {
+		if detail != "" {
+			detail += "\n\n"
+		}
+		detail += strings.Join(extra, "\n")
+	}
+
+	base := diagnosticBase{
+		severity: severity,
+		summary:  record.Message,
+		detail:   detail,
+		address:  address,
+	}
+	if subject == nil || subject.Filename == "" {
+		return base
+	}
+	if !hasLine {
+		rng := FileRange(subject.Filename)
+		return sourcedDiagnostic{
+			diagnosticBase: base,
+			subject:        &rng,
+		}
+	}
+	subject.Start = opts.decode(subject.Start)
+	if !hasColumn {
+		subject.Start.Column = 0
+		subject.Precision = PrecisionLine
+	}
+	subject.End = subject.Start
+	return sourcedDiagnostic{
+		diagnosticBase: base,
+		subject:        subject,
+	}
+}
+
+// ToRecords converts diagnostics into structured log records, which is the
+// inverse of FromRecords, so that diagnostics can be passed to a
+// slog.Handler along with a program's other logs.
+//
+// Fatal diagnostics and errors become records at slog.LevelError, warnings
+// at slog.LevelWarn and hints at slog.LevelInfo. The summary becomes the
+// message, and the detail, address and subject become the attributes that
+// FromRecords recognizes, with a "column" attribute only for subjects with
+// PrecisionExact. The time of each record is the diagnostic's timestamp, if
+// it has one, or otherwise zero, which handlers omit.
+//
+// The "line" and "column" attributes are numbered from one. Use
+// ToRecordsWith for other conventions.
+func ToRecords(diags Diagnostics) []slog.Record {
+	return ToRecordsWith(diags, PositionOptions{})
+}
+
+// ToRecordsWith is like ToRecords except that the "line" and "column"
+// attributes are numbered according to the given options.
+func ToRecordsWith(diags Diagnostics, opts PositionOptions) []slog.Record {
+	if diags == nil {
+		return nil
+	}
+	ret := make([]slog.Record, len(diags))
+	for i, diag := range diags {
+		ret[i] = toRecord(diag, opts)
+	}
+	return ret
+}
+
+func toRecord(diag Diagnostic, opts PositionOptions) slog.Record {
+	level := slog.LevelWarn
+	switch diag.Severity() {
+	case Fatal, Error:
+		level = slog.LevelError
+	case Hint:
+		level = slog.LevelInfo
+	}
+	var ts time.Time
+	if t, ok := TimestampOf(diag); ok {
+		ts = t
+	}
+
+	desc := diag.Description()
+	record := slog.NewRecord(ts, level, desc.Summary, 0)
+	if desc.Detail != "" {
+		record.AddAttrs(slog.String("detail", desc.Detail))
+	}
+	if desc.Address != "" {
+		record.AddAttrs(slog.String("address", desc.Address))
+	}
+	subject := subjectOf(diag)
+	if subject == nil || subject.Kind != SubjectFile {
+		return record
+	}
+	record.AddAttrs(slog.String("file", subject.Filename))
+	if subject.Precision == PrecisionFile {
+		return record
+	}
+	start := opts.encode(subject.Start)
+	record.AddAttrs(slog.Int("line", start.Line))
+	if subject.Precision == PrecisionExact {
+		record.AddAttrs(slog.Int("column", start.Column))
+	}
+	return record
+}
@@ -0,0 +1,140 @@
+//go:build go1.21
+// +build go1.21
+
+package tbdiags
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestFromRecords(t *testing.T) {
+	warn := slog.NewRecord(time.Now(), slog.LevelWarn, "Deprecated setting", 0)
+	warn.AddAttrs(
+		slog.String("file", "main.tb"),
+		slog.Int("line", 4),
+		slog.String("column", "2"),
+		slog.String("setting", "legacy_mode"),
+	)
+	fail := slog.NewRecord(time.Now(), slog.LevelError, "Connection failed", 0)
+	fail.AddAttrs(slog.String("detail", "The server did not respond."))
+
+	diags := FromRecords([]slog.Record{warn, fail})
+	if len(diags) != 2 {
+		t.Fatalf("wrong number of diagnostics %d; want 2", len(diags))
+	}
+
+	if got, want := diags[0].Severity(), Warning; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	desc := diags[0].Description()
+	if got, want := desc.Summary, "Deprecated setting"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := desc.Detail, "setting=legacy_mode"; got != want {
+		t.Errorf("wrong detail %q; want %q", got, want)
+	}
+	subject := diags[0].Source().Subject
+	if subject == nil {
+		t.Fatalf("no subject")
+	}
+	if got, want := subject.StartString(), "main.tb:4,2"; got != want {
+		t.Errorf("wrong subject %q; want %q", got, want)
+	}
+
+	if got, want := diags[1].Severity(), Error; got != want {
+		t.Errorf("wrong severity %s; want %s", got, want)
+	}
+	if got, want := diags[1].Description().Detail, "The server did not respond."; got != want {
+		t.Errorf("wrong detail %q; want %q", got, want)
+	}
+}
+
+func TestFromRecordsWith(t *testing.T) {
+	record := slog.NewRecord(time.Time{}, slog.LevelWarn, "Dubious thing", 0)
+	record.AddAttrs(
+		slog.String("file", "main.tb"),
+		slog.Int("line", 0),
+		slog.Int("column", 4),
+	)
+	diags := FromRecordsWith([]slog.Record{record}, PositionOptions{LineBase: ZeroBased, ColumnBase: ZeroBased})
+	subject := diags[0].Source().Subject
+	if got, want := subject.Start, (SourcePos{Line: 1, Column: 5}); got != want {
+		t.Errorf("wrong position\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestFromRecordsFileOnly(t *testing.T) {
+	record := slog.NewRecord(time.Time{}, slog.LevelWarn, "Dubious file", 0)
+	record.AddAttrs(slog.String("file", "main.tb"))
+	diags := FromRecords([]slog.Record{record})
+	if got, want := *diags[0].Source().Subject, FileRange("main.tb"); got != want {
+		t.Errorf("wrong subject\ngot:  %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestToRecords(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rng := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 4, Column: 2, Byte: 30},
+		End:      SourcePos{Line: 4, Column: 9, Byte: 37},
+	}
+	line := LineRange("main.tb", 7)
+	diags := Diagnostics{
+		WithTimestamp(sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Error, summary: "Bad value", detail: "Values must be short.", address: "var.a"},
+			subject:        &rng,
+		}, ts),
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Warning, summary: "Dubious line"},
+			subject:        &line,
+		},
+		Sourceless(Hint, "Consider something", ""),
+	}
+
+	records := ToRecords(diags)
+	if len(records) != 3 {
+		t.Fatalf("wrong number of records %d; want 3", len(records))
+	}
+	if got, want := records[0].Level, slog.LevelError; got != want {
+		t.Errorf("wrong level %s; want %s", got, want)
+	}
+	if !records[0].Time.Equal(ts) {
+		t.Errorf("wrong time %s; want %s", records[0].Time, ts)
+	}
+	if got, want := records[2].Level, slog.LevelInfo; got != want {
+		t.Errorf("wrong level %s; want %s", got, want)
+	}
+
+	// Converting back reproduces the diagnostics, except for the hint,
+	// which FromRecords can only represent as a warning.
+	back := FromRecords(records)
+	for i, diag := range diags[:2] {
+		if got, want := back[i].Description(), diag.Description(); got != want {
+			t.Errorf("wrong description %d\ngot:  %#v\nwant: %#v", i, got, want)
+		}
+		got, want := *back[i].Source().Subject, *diag.Source().Subject
+		got.Start.Byte, got.End.Byte, want.Start.Byte, want.End.Byte = 0, 0, 0, 0
+		want.End = want.Start
+		if got != want {
+			t.Errorf("wrong subject %d\ngot:  %#v\nwant: %#v", i, got, want)
+		}
+	}
+
+	zero := ToRecordsWith(diags[:1], PositionOptions{LineBase: ZeroBased, ColumnBase: ZeroBased})
+	var line0, col0 int64
+	zero[0].Attrs(func(attr slog.Attr) bool {
+		switch attr.Key {
+		case "line":
+			line0 = attr.Value.Int64()
+		case "column":
+			col0 = attr.Value.Int64()
+		}
+		return true
+	})
+	if line0 != 3 || col0 != 1 {
+		t.Errorf("wrong zero-based position %d,%d; want 3,1", line0, col0)
+	}
+}
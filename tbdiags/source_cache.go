@@ -0,0 +1,187 @@
+package tbdiags
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SourceCache loads and caches the contents of source files, for
+// long-lived programs, such as language servers, that show excerpts of the
+// same files repeatedly. Its SourcesFor method is a replacement for the
+// SourcesFor function that uses the cache.
+//
+// Each call to Load checks the modification time and size of the file and
+// reads it again if either has changed, so that excerpts reflect the
+// current contents of the file rather than a stale copy. Changes that
+// preserve both, such as edits within the resolution of the file system's
+// timestamps, can't be detected that way, so programs that watch files for
+// changes should also call Invalidate when they're notified of one.
+//
+// A SourceCache is safe for concurrent use. The zero value is an empty
+// cache ready to use.
+type SourceCache struct {
+	// MaxFileSize, if positive, is the size in bytes above which LoadLines
+	// and SourcesFor don't read a file entirely, but only the lines that
+	// are needed. This bounds the memory used to show excerpts of very
+	// large inputs, such as log files. Such files are never cached.
+	MaxFileSize int64
+
+	mu      sync.Mutex
+	entries map[string]sourceCacheEntry
+}
+
+type sourceCacheEntry struct {
+	modTime time.Time
+	size    int64
+	src     []byte
+}
+
+// Load returns the contents of the file with the given name, from the cache
+// if the file hasn't changed since it was cached, or otherwise by reading
+// it. Callers must not modify the result.
+func (c *SourceCache) Load(filename string) ([]byte, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		c.Invalidate(filename)
+		return nil, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[filename]
+	c.mu.Unlock()
+	if ok && entry.size == info.Size() && entry.modTime.Equal(info.ModTime()) {
+		return entry.src, nil
+	}
+
+	// If the file changes again between being examined and being read, the
+	// entry will be stale, but its modification time will then differ
+	// from the file's so the next call will read it again.
+	src, err := os.ReadFile(filename)
+	if err != nil {
+		c.Invalidate(filename)
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[string]sourceCacheEntry)
+	}
+	c.entries[filename] = sourceCacheEntry{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		src:     src,
+	}
+	c.mu.Unlock()
+	return src, nil
+}
+
+// Invalidate removes the given files from the cache, so that they will be
+// read again by the next call to Load, or removes all files if none are
+// given.
+func (c *SourceCache) Invalidate(filenames ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(filenames) == 0 {
+		c.entries = nil
+		return
+	}
+	for _, filename := range filenames {
+		delete(c.entries, filename)
+	}
+}
+
+// maxWindowLineSize is the maximum number of bytes of each line that
+// LoadLines keeps from a file larger than MaxFileSize, so that a file
+// consisting of one enormous line doesn't defeat the limit.
+const maxWindowLineSize = 4096
+
+// LoadLines is like Load except that a file larger than MaxFileSize is read
+// only as far as the last of the given line numbers, and isn't cached. In
+// the contents returned for such a file, only the given lines are present,
+// and each of the others is empty, so that the lines keep their numbers.
+func (c *SourceCache) LoadLines(filename string, lines map[int]bool) ([]byte, error) {
+	if c.MaxFileSize <= 0 {
+		return c.Load(filename)
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() <= c.MaxFileSize {
+		return c.Load(filename)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return windowSource(f, lines)
+}
+
+// SourcesFor is like the SourcesFor function using the receiver's Load
+// method as the loader, except that files larger than MaxFileSize are read
+// with LoadLines, keeping only the lines that the subject, context and
+// related ranges of the diagnostics start on.
+func (c *SourceCache) SourcesFor(diags Diagnostics) (map[string][]byte, Diagnostics) {
+	lines := make(map[string]map[int]bool)
+	for _, diag := range diags {
+		for _, rng := range snippetRanges(diag) {
+			if rng.Kind != SubjectFile {
+				continue
+			}
+			if lines[rng.Filename] == nil {
+				lines[rng.Filename] = make(map[int]bool)
+			}
+			lines[rng.Filename][rng.Start.Line] = true
+		}
+	}
+	return SourcesFor(diags, func(filename string) ([]byte, error) {
+		return c.LoadLines(filename, lines[filename])
+	})
+}
+
+// windowSource reads the given source up to the last of the given line
+// numbers, returning a copy of it in which only those lines are present,
+// truncated to maxWindowLineSize bytes, and all others are empty.
+func windowSource(r io.Reader, lines map[int]bool) ([]byte, error) {
+	last := 0
+	for line := range lines {
+		if line > last {
+			last = line
+		}
+	}
+
+	var ret bytes.Buffer
+	br := bufio.NewReader(r)
+	for line := 1; line <= last; line++ {
+		keep := lines[line]
+		var kept []byte
+		for {
+			chunk, err := br.ReadSlice('\n')
+			if keep && len(kept) < maxWindowLineSize {
+				n := len(chunk)
+				if room := maxWindowLineSize - len(kept); n > room {
+					n = room
+				}
+				kept = append(kept, bytes.TrimSuffix(chunk[:n], []byte("\n"))...)
+			}
+			if err == bufio.ErrBufferFull {
+				continue
+			}
+			if err == io.EOF {
+				ret.Write(kept)
+				return ret.Bytes(), nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		ret.Write(kept)
+		ret.WriteByte('\n')
+	}
+	return ret.Bytes(), nil
+}
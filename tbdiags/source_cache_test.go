@@ -0,0 +1,159 @@
+package tbdiags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSourceCache(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "main.tb")
+	modTime := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	write := func(content string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(filename, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load := func(cache *SourceCache, want string) {
+		t.Helper()
+		got, err := cache.Load(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("wrong content %q; want %q", got, want)
+		}
+	}
+
+	var cache SourceCache
+	write("name = \"a\"\n", modTime)
+	load(&cache, "name = \"a\"\n")
+
+	// A change with a new modification time is noticed.
+	write("name = \"b\"\n", modTime.Add(time.Second))
+	load(&cache, "name = \"b\"\n")
+
+	// A change that preserves the size and modification time isn't, until
+	// the file is invalidated.
+	write("name = \"c\"\n", modTime.Add(time.Second))
+	load(&cache, "name = \"b\"\n")
+	cache.Invalidate(filename)
+	load(&cache, "name = \"c\"\n")
+
+	write("name = \"d\"\n", modTime.Add(time.Second))
+	cache.Invalidate()
+	load(&cache, "name = \"d\"\n")
+
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Load(filename); !os.IsNotExist(err) {
+		t.Errorf("wrong error %v for a removed file", err)
+	}
+}
+
+func TestSourceCacheLoadLines(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "build.log")
+	var content strings.Builder
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&content, "line %d of the build log\n", i)
+	}
+	if err := os.WriteFile(filename, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &SourceCache{MaxFileSize: 1024}
+	src, err := cache.LoadLines(filename, map[int]bool{20: true, 500: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(src) >= content.Len()/10 {
+		t.Errorf("read %d bytes of a %d byte file", len(src), content.Len())
+	}
+	got := strings.Split(string(src), "\n")
+	for line, want := range map[int]string{20: "line 20 of the build log", 21: "", 500: "line 500 of the build log"} {
+		if got := got[line-1]; got != want {
+			t.Errorf("wrong line %d %q; want %q", line, got, want)
+		}
+	}
+	if !strings.HasSuffix(string(src), "\nline 500 of the build log\n") {
+		t.Errorf("didn't stop after the last line needed")
+	}
+
+	// Files within the limit are read entirely.
+	cache.MaxFileSize = int64(content.Len())
+	if src, _ := cache.LoadLines(filename, map[int]bool{20: true}); string(src) != content.String() {
+		t.Errorf("file within the limit was not read entirely")
+	}
+}
+
+func TestSourceCacheSourcesForLargeFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "build.log")
+	var content strings.Builder
+	for i := 1; i <= 1000; i++ {
+		fmt.Fprintf(&content, "line %d of the build log\n", i)
+	}
+	if err := os.WriteFile(filename, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(line int) *SourceRange {
+		return &SourceRange{
+			Filename: filename,
+			Start:    SourcePos{Line: line, Column: 1},
+			End:      SourcePos{Line: line, Column: 5},
+		}
+	}
+	diags := Diagnostics{sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{severity: Error, summary: "Build failed"},
+		subject:        at(500),
+		context: &SourceRange{
+			Filename: filename,
+			Start:    SourcePos{Line: 20, Column: 1},
+			End:      SourcePos{Line: 500, Column: 5},
+		},
+	}}
+
+	cache := &SourceCache{MaxFileSize: 1024}
+	sources, loadDiags := cache.SourcesFor(diags)
+	if len(loadDiags) != 0 {
+		t.Fatal(loadDiags.Err())
+	}
+	src := sources[filename]
+	if len(src) >= content.Len()/10 {
+		t.Errorf("read %d bytes of a %d byte file", len(src), content.Len())
+	}
+	for line, want := range map[int]string{20: "line 20 of the build log", 21: "", 500: "line 500 of the build log"} {
+		if got, _ := sourceLine(src, line); got != want {
+			t.Errorf("wrong line %d %q; want %q", line, got, want)
+		}
+	}
+	if !strings.HasSuffix(string(src), "\nline 500 of the build log\n") {
+		t.Errorf("didn't stop after the last line needed")
+	}
+
+	// Files within the limit are read entirely.
+	cache.MaxFileSize = int64(content.Len())
+	sources, _ = cache.SourcesFor(diags)
+	if got := string(sources[filename]); got != content.String() {
+		t.Errorf("file within the limit was not read entirely")
+	}
+}
+
+func TestWindowSource(t *testing.T) {
+	long := strings.Repeat("x", 3*maxWindowLineSize)
+	got, err := windowSource(strings.NewReader("a\n"+long+"\nc"), map[int]bool{2: true, 3: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "\n" + long[:maxWindowLineSize] + "\nc"; string(got) != want {
+		t.Errorf("wrong result of %d bytes; want %d bytes", len(got), len(want))
+	}
+}
@@ -2,34 +2,203 @@ package tbdiags
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 )
 
 type SourceRange struct {
 	Filename   string
 	Start, End SourcePos
+
+	// Kind is the kind of subject the range refers to. The zero value is
+	// SubjectFile, for ranges within files. For other kinds, Filename is
+	// instead the name of the subject, such as the name of an environment
+	// variable, and Start and End are positions within its value.
+	Kind SubjectKind
+
+	// Precision describes which fields of Start and End are meaningful.
+	// The zero value is PrecisionExact.
+	Precision Precision
+}
+
+// Precision describes how precisely a SourceRange identifies its location,
+// so that consumers can tell which of its positions' fields are meaningful
+// and which are just zero values.
+type Precision int
+
+const (
+	// PrecisionExact means that all fields of Start and End are meaningful.
+	PrecisionExact Precision = iota
+
+	// PrecisionLine means that only the Line fields of Start and End are
+	// meaningful, for producers that know only which line a problem is on.
+	PrecisionLine
+
+	// PrecisionFile means that only Filename is meaningful, for producers
+	// that know only which file a problem is in. Renderers should mark
+	// the whole file rather than any particular part of it.
+	PrecisionFile
+
+	// PrecisionNone means that no part of the range is meaningful, for
+	// producers that must supply a range but don't know the location of a
+	// problem. Consumers treat such a range as if there were no range.
+	PrecisionNone
+)
+
+// String returns the name of the precision, such as "line".
+func (p Precision) String() string {
+	switch p {
+	case PrecisionExact:
+		return "exact"
+	case PrecisionLine:
+		return "line"
+	case PrecisionFile:
+		return "file"
+	case PrecisionNone:
+		return "none"
+	default:
+		return fmt.Sprintf("Precision(%d)", int(p))
+	}
+}
+
+// subjectOf returns the subject range of the given diagnostic, or nil if
+// it has none or if its subject has PrecisionNone.
+func subjectOf(diag Diagnostic) *SourceRange {
+	subject := diag.Source().Subject
+	if subject == nil || subject.Precision == PrecisionNone {
+		return nil
+	}
+	return subject
+}
+
+// FileRange returns a SourceRange for the whole of the given file, with
+// PrecisionFile.
+func FileRange(filename string) SourceRange {
+	return SourceRange{
+		Filename:  filename,
+		Precision: PrecisionFile,
+	}
+}
+
+// LineRange returns a SourceRange covering the whole of the given line of
+// the given file, with PrecisionLine.
+func LineRange(filename string, line int) SourceRange {
+	pos := SourcePos{Line: line}
+	return SourceRange{
+		Filename:  filename,
+		Start:     pos,
+		End:       pos,
+		Precision: PrecisionLine,
+	}
+}
+
+// SubjectKind describes what sort of thing a SourceRange refers to, so that
+// diagnostics about things that aren't files, such as environment variables
+// or command line flags, don't need to pretend to be about a file.
+type SubjectKind int
+
+const (
+	// SubjectFile is for ranges within files, identified by their path.
+	SubjectFile SubjectKind = iota
+
+	// SubjectEnvVar is for ranges within the value of an environment
+	// variable, identified by its name, such as "TB_TOKEN".
+	SubjectEnvVar
+
+	// SubjectFlag is for ranges within the value of a command line flag,
+	// identified by its name, such as "-config".
+	SubjectFlag
+
+	// SubjectObjectKey is for ranges within an object in a remote store,
+	// such as an S3 bucket, identified by its key.
+	SubjectObjectKey
+
+	// SubjectArchiveEntry is for ranges within an entry of an archive such
+	// as a zip or tar file, created using ArchiveEntryRange. Filename is
+	// the archive path and the entry name separated by "!", and the Byte
+	// fields of Start and End are offsets within the archive itself.
+	SubjectArchiveEntry
+)
+
+// String returns the name of the kind, such as "env", as used in the JSON
+// representation of diagnostics.
+func (k SubjectKind) String() string {
+	switch k {
+	case SubjectFile:
+		return "file"
+	case SubjectEnvVar:
+		return "env"
+	case SubjectFlag:
+		return "flag"
+	case SubjectObjectKey:
+		return "object"
+	case SubjectArchiveEntry:
+		return "archive"
+	default:
+		return fmt.Sprintf("SubjectKind(%d)", int(k))
+	}
 }
 
+// Describe returns a user-facing description of the subject with the given
+// name, such as "environment variable TB_TOKEN". For SubjectFile the name
+// is returned verbatim.
+func (k SubjectKind) Describe(name string) string {
+	switch k {
+	case SubjectEnvVar:
+		return "environment variable " + name
+	case SubjectFlag:
+		return "flag " + name
+	case SubjectObjectKey:
+		return "object " + name
+	default:
+		return name
+	}
+}
+
+// SourcePos is a position within a source file.
+//
+// Line and Column are numbered from one, and Column counts characters
+// rather than bytes. Byte is the offset in bytes from the start of the file,
+// numbered from zero. Serialization formats that use other conventions
+// convert at their boundaries, as specified by PositionOptions.
 type SourcePos struct {
 	Line, Column, Byte int
 }
 
 // StartString returns a string representation of the start of the range,
 // including the filename and the line and column numbers.
+//
+// The filename is shown relative to the current working directory where
+// possible, so it's less verbose in the common case. Use StartStringWith
+// to choose a different PathPolicy.
 func (r SourceRange) StartString() string {
-	filename := r.Filename
-
-	// We'll try to relative-ize our filename here so it's less verbose
-	// in the common case of being in the current working directory. If not,
-	// we'll just show the full path.
-	wd, err := os.Getwd()
-	if err == nil {
-		relFn, err := filepath.Rel(wd, filename)
-		if err == nil {
-			filename = relFn
+	return r.StartStringWith(PathPolicy{})
+}
+
+// StartStringWith is like StartString except that the filename is displayed
+// according to the given PathPolicy.
+//
+// For ranges whose Kind is not SubjectFile the result is just a description
+// of the subject, since line and column numbers are rarely meaningful for
+// such subjects, except that archive entries also include their offset
+// within the archive if it's known. For ranges with PrecisionLine the
+// column is omitted, for ranges with PrecisionFile the result is just the
+// filename, and for ranges with PrecisionNone the result is empty.
+func (r SourceRange) StartStringWith(policy PathPolicy) string {
+	switch {
+	case r.Precision == PrecisionNone:
+		return ""
+	case r.Kind == SubjectArchiveEntry:
+		archive, entry := splitArchiveEntry(r.Filename)
+		name := policy.DisplayPath(archive) + "!" + entry
+		if r.Start.Byte < 0 {
+			return name
 		}
+		return fmt.Sprintf("%s (byte %d)", name, r.Start.Byte)
+	case r.Kind != SubjectFile:
+		return r.Kind.Describe(r.Filename)
+	case r.Precision == PrecisionFile:
+		return policy.DisplayPath(r.Filename)
+	case r.Precision == PrecisionLine:
+		return fmt.Sprintf("%s:%d", policy.DisplayPath(r.Filename), r.Start.Line)
 	}
-
-	return fmt.Sprintf("%s:%d,%d", filename, r.Start.Line, r.Start.Column)
+	return fmt.Sprintf("%s:%d,%d", policy.DisplayPath(r.Filename), r.Start.Line, r.Start.Column)
 }
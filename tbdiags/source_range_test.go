@@ -0,0 +1,21 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestSubjectKindString(t *testing.T) {
+	tests := map[SubjectKind]string{
+		SubjectFile:         "file",
+		SubjectEnvVar:       "env",
+		SubjectFlag:         "flag",
+		SubjectObjectKey:    "object",
+		SubjectArchiveEntry: "archive",
+		SubjectKind(99):     "SubjectKind(99)",
+	}
+	for kind, want := range tests {
+		if got := kind.String(); got != want {
+			t.Errorf("wrong name for %d: got %q, want %q", int(kind), got, want)
+		}
+	}
+}
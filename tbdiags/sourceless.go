@@ -5,9 +5,41 @@ package tbdiags
 // caused by or relate to the environment where Terraform is running rather
 // than to the provided configuration.
 func Sourceless(severity Severity, summary, detail string) Diagnostic {
-	return diagnosticBase{
+	return withCaller(diagnosticBase{
 		severity: severity,
 		summary:  summary,
 		detail:   detail,
-	}
+	})
+}
+
+// New is a synonym for Sourceless, for producers that think of it as the
+// general way to create a diagnostic.
+func New(severity Severity, summary, detail string) Diagnostic {
+	return withCaller(diagnosticBase{
+		severity: severity,
+		summary:  summary,
+		detail:   detail,
+	})
+}
+
+// SimpleError creates and returns an error diagnostic with no source
+// location information, as for Sourceless.
+func SimpleError(summary, detail string) Diagnostic {
+	return withCaller(diagnosticBase{
+		severity: Error,
+		summary:  summary,
+		detail:   detail,
+	})
+}
+
+// SimpleWarningDetail creates and returns a warning diagnostic with no
+// source location information, as for Sourceless. It's the counterpart of
+// SimpleError for warnings that need a detail, which SimpleWarning can't
+// have.
+func SimpleWarningDetail(summary, detail string) Diagnostic {
+	return withCaller(diagnosticBase{
+		severity: Warning,
+		summary:  summary,
+		detail:   detail,
+	})
 }
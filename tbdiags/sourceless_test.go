@@ -0,0 +1,32 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestSourcelessConstructors(t *testing.T) {
+	tests := map[string]struct {
+		diag     Diagnostic
+		severity Severity
+	}{
+		"Sourceless":          {Sourceless(Warning, "Summary", "Detail."), Warning},
+		"New":                 {New(Hint, "Summary", "Detail."), Hint},
+		"SimpleError":         {SimpleError("Summary", "Detail."), Error},
+		"SimpleWarningDetail": {SimpleWarningDetail("Summary", "Detail."), Warning},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := test.diag.Severity(); got != test.severity {
+				t.Errorf("wrong severity %s; want %s", got, test.severity)
+			}
+			desc := test.diag.Description()
+			if desc.Summary != "Summary" || desc.Detail != "Detail." {
+				t.Errorf("wrong description %#v", desc)
+			}
+			if src := test.diag.Source(); src.Subject != nil || src.Context != nil {
+				t.Errorf("unexpected source %#v", src)
+			}
+		})
+	}
+}
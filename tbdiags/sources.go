@@ -0,0 +1,172 @@
+package tbdiags
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// SourcesFor loads the contents of each file referenced by the subject,
+// context or related ranges of the given diagnostics, using the given
+// loader, so that they can be given to Renderer.Sources in one call.
+//
+// Each file is loaded only once. Files that fail to load are omitted from
+// the result and reported in the returned diagnostics as warnings, since
+// failing to show source code shouldn't prevent showing the diagnostics
+// themselves. Programs that render diagnostics repeatedly can use the
+// SourcesFor method of a SourceCache instead, to avoid reading unchanged
+// files again.
+func SourcesFor(diags Diagnostics, loader func(filename string) ([]byte, error)) (map[string][]byte, Diagnostics) {
+	seen := make(map[string]bool)
+	var filenames []string
+	for _, diag := range diags {
+		for _, rng := range snippetRanges(diag) {
+			if rng.Kind != SubjectFile || rng.Precision == PrecisionNone || seen[rng.Filename] {
+				continue
+			}
+			seen[rng.Filename] = true
+			filenames = append(filenames, rng.Filename)
+		}
+	}
+	sort.Strings(filenames)
+
+	var loadDiags Diagnostics
+	ret := make(map[string][]byte, len(filenames))
+	for _, filename := range filenames {
+		src, err := loader(filename)
+		if err != nil {
+			loadDiags = loadDiags.Append(Sourceless(
+				Warning,
+				"Failed to read source file",
+				fmt.Sprintf("Source code from %s can't be shown: %s.", filename, err),
+			))
+			continue
+		}
+		ret[filename] = src
+	}
+	return ret, loadDiags
+}
+
+// snippetRanges returns the ranges of the given diagnostic that the
+// Renderer can show snippets for, which are its subject, context and
+// related ranges.
+func snippetRanges(diag Diagnostic) []*SourceRange {
+	var ret []*SourceRange
+	if subject := subjectOf(diag); subject != nil {
+		ret = append(ret, subject)
+	}
+	if context := diag.Source().Context; context != nil {
+		ret = append(ret, context)
+	}
+	for _, info := range RelatedOf(diag) {
+		info := info
+		ret = append(ret, &info.Range)
+	}
+	return ret
+}
+
+// writeSnippet writes the line of the given source that contains the start
+// of the given range, followed for PrecisionExact ranges by a line of
+// carets under the part of it that the range covers. It writes nothing if
+// the line doesn't exist in the source.
+//
+// If style is not empty, it's an ANSI escape sequence that is applied to the
+// part of the line that a PrecisionExact range covers.
+//
+// The range is clamped to the source with ClampRange first, and the carets
+// never extend past the end of the line, so that a range that doesn't match
+// the source can't produce garbage.
+func writeSnippet(w *bufio.Writer, rng *SourceRange, src []byte, style string) {
+	if rng.Precision != PrecisionExact && rng.Precision != PrecisionLine {
+		return
+	}
+	clamped := ClampRange(*rng, src)
+	rng = &clamped
+	line, ok := sourceLine(src, rng.Start.Line)
+	if !ok {
+		return
+	}
+
+	prefix := fmt.Sprintf("  %4d: ", rng.Start.Line)
+	if rng.Precision != PrecisionExact {
+		fmt.Fprintf(w, "\n%s%s\n", prefix, line)
+		return
+	}
+	if style != "" {
+		fmt.Fprintf(w, "\n%s%s\n", prefix, styleColumns(line, rng, style))
+	} else {
+		fmt.Fprintf(w, "\n%s%s\n", prefix, line)
+	}
+
+	// Columns count characters, so we count runes to find where the
+	// carets go, copying any tabs so that they line up.
+	var indent strings.Builder
+	col := 1
+	for _, r := range line {
+		if col >= rng.Start.Column {
+			break
+		}
+		if r == '\t' {
+			indent.WriteByte('\t')
+		} else {
+			indent.WriteByte(' ')
+		}
+		col++
+	}
+	rest := utf8.RuneCountInString(line) - (col - 1)
+	width := rest
+	if rng.End.Line == rng.Start.Line && rng.End.Column > rng.Start.Column && rng.End.Column-rng.Start.Column < rest {
+		width = rng.End.Column - rng.Start.Column
+	}
+	if width < 1 {
+		width = 1
+	}
+	fmt.Fprintf(w, "%s%s%s\n", strings.Repeat(" ", len(prefix)), indent.String(), strings.Repeat("^", width))
+}
+
+// styleColumns returns the given line, which is the first line of the given
+// range, with the part of it that the range covers wrapped in the given
+// ANSI escape sequence and a reset.
+func styleColumns(line string, rng *SourceRange, style string) string {
+	var buf strings.Builder
+	col := 1
+	styled := false
+	for _, r := range line {
+		if col == rng.Start.Column {
+			buf.WriteString(style)
+			styled = true
+		}
+		if styled && rng.End.Line == rng.Start.Line && col == rng.End.Column {
+			buf.WriteString(ansiReset)
+			styled = false
+		}
+		buf.WriteRune(r)
+		col++
+	}
+	if styled {
+		buf.WriteString(ansiReset)
+	}
+	return buf.String()
+}
+
+// sourceLine returns the given line of the given source, numbered from one,
+// without its line terminator.
+func sourceLine(src []byte, line int) (string, bool) {
+	if line < 1 {
+		return "", false
+	}
+	for n := 1; n < line; n++ {
+		i := bytes.IndexByte(src, '\n')
+		if i < 0 {
+			return "", false
+		}
+		src = src[i+1:]
+	}
+	if i := bytes.IndexByte(src, '\n'); i >= 0 {
+		src = src[:i]
+	}
+	return string(bytes.TrimSuffix(src, []byte("\r"))), true
+}
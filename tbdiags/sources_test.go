@@ -0,0 +1,137 @@
+package tbdiags
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSourcesFor(t *testing.T) {
+	rng := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 2, Column: 9, Byte: 18},
+		End:      SourcePos{Line: 2, Column: 14, Byte: 23},
+	}
+	missing := LineRange("missing.tb", 1)
+	diags := Diagnostics{
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Error, summary: "Unknown variable", detail: "There is no variable named \"color\"."},
+			subject:        &rng,
+		},
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Warning, summary: "Also main"},
+			subject:        &rng,
+		},
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Warning, summary: "Missing"},
+			subject:        &missing,
+		},
+	}
+
+	var loaded []string
+	sources, loadDiags := SourcesFor(diags, func(filename string) ([]byte, error) {
+		loaded = append(loaded, filename)
+		if filename == "main.tb" {
+			return []byte("name = \"a\"\nshade = color.red\n"), nil
+		}
+		return nil, os.ErrNotExist
+	})
+	if got := fmt.Sprint(loaded); got != "[main.tb missing.tb]" {
+		t.Errorf("wrong files loaded %s", got)
+	}
+	if len(loadDiags) != 1 || loadDiags[0].Severity() != Warning {
+		t.Errorf("wrong load diagnostics %#v", loadDiags)
+	}
+
+	got := (&Renderer{Sources: sources}).RenderString(diags[:1])
+	want := `Error: Unknown variable
+  on main.tb:2,9
+
+     2: shade = color.red
+                ^^^^^
+
+There is no variable named "color".
+
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSourcesForRelated(t *testing.T) {
+	diag := WithRelated(
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Error, summary: "Duplicate variable"},
+			subject: &SourceRange{
+				Filename: "main.tb",
+				Start:    SourcePos{Line: 1, Column: 1, Byte: 0},
+				End:      SourcePos{Line: 1, Column: 5, Byte: 4},
+			},
+		},
+		RelatedInfo{
+			Message: "Previous definition",
+			Range: SourceRange{
+				Filename: "vars.tb",
+				Start:    SourcePos{Line: 2, Column: 1, Byte: 8},
+				End:      SourcePos{Line: 2, Column: 5, Byte: 12},
+			},
+		},
+	)
+	files := map[string]string{
+		"main.tb": "name = \"a\"\n",
+		"vars.tb": "x = 1\nname = \"b\"\n",
+	}
+	sources, loadDiags := SourcesFor(Diagnostics{diag}, func(filename string) ([]byte, error) {
+		return []byte(files[filename]), nil
+	})
+	if len(loadDiags) != 0 {
+		t.Fatal(loadDiags.Err())
+	}
+
+	got := (&Renderer{Sources: sources}).RenderString(Diagnostics{diag})
+	want := `Error: Duplicate variable
+  on main.tb:1,1
+
+     1: name = "a"
+        ^^^^
+
+  Previous definition: vars.tb:2,1
+
+     2: name = "b"
+        ^^^^
+
+`
+	if got != want {
+		t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteSnippetClamped(t *testing.T) {
+	src := []byte("name = \"a\"\n")
+	tests := map[string]SourceRange{
+		"past the end of the source": {
+			Filename: "main.tb",
+			Start:    SourcePos{Line: 1, Column: 8, Byte: 7},
+			End:      SourcePos{Line: 1, Column: 40, Byte: 39},
+		},
+		"past the end of the line": {
+			Filename: "main.tb",
+			Start:    SourcePos{Line: 1, Column: 8, Byte: 7},
+			End:      SourcePos{Line: 1, Column: 40, Byte: 11},
+		},
+	}
+	for name, rng := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf strings.Builder
+			w := bufio.NewWriter(&buf)
+			writeSnippet(w, &rng, src, "")
+			w.Flush()
+			want := "\n     1: name = \"a\"\n               ^^^\n"
+			if got := buf.String(); got != want {
+				t.Errorf("wrong result\ngot:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package tbdiags
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxErrorSummaryLen, if greater than zero, is the maximum length of the
+// summary of a diagnostic created by Diagnostics.Append from a native error.
+// Longer error messages are split into a summary and a detail using
+// SplitMessage, so that wrapped third-party errors don't produce summaries
+// too long for compact renderings.
+//
+// Zero, the default, means that the whole error message is always used as
+// the summary. As with MaxErrorItems, this should be set only during
+// program startup.
+var MaxErrorSummaryLen int
+
+// SplitMessage heuristically splits a long message into a summary of at most
+// maxLen bytes and a detail.
+//
+// Messages no longer than maxLen are returned unchanged as the summary with
+// an empty detail. Otherwise, the message is split at the first colon or
+// end of sentence if that leaves a short enough summary. If there is no
+// such split point, the summary is the start of the message shortened at a
+// word boundary and marked with an ellipsis, and the detail is the entire
+// message.
+func SplitMessage(msg string, maxLen int) (summary, detail string) {
+	msg = strings.TrimSpace(msg)
+	if maxLen <= 0 || len(msg) <= maxLen {
+		return msg, ""
+	}
+
+	if i := firstSplitPoint(msg); i > 0 && i <= maxLen {
+		summary = strings.TrimSpace(msg[:i])
+		detail = strings.TrimSpace(msg[i+1:])
+		if detail != "" {
+			return summary, detail
+		}
+	}
+
+	// No good split point, so we'll truncate instead.
+	return truncateAtWord(msg, maxLen), msg
+}
+
+// truncateAtWord returns the given message unchanged if it is no longer
+// than maxLen bytes, or otherwise the start of it shortened at a word
+// boundary and marked with an ellipsis, such that the result is no longer
+// than maxLen bytes where possible. It returns an empty string if maxLen is
+// not positive.
+func truncateAtWord(msg string, maxLen int) string {
+	if len(msg) <= maxLen {
+		return msg
+	}
+	if maxLen <= 0 {
+		return ""
+	}
+	cut := maxLen - len("…")
+	if cut < 1 {
+		cut = 1
+	}
+	if cut > len(msg) {
+		cut = len(msg)
+	}
+	for cut > 0 && !utf8.RuneStart(msg[cut]) {
+		cut--
+	}
+	if space := strings.LastIndexByte(msg[:cut], ' '); space > 0 {
+		cut = space
+	}
+	return strings.TrimSpace(msg[:cut]) + "…"
+}
+
+// firstSplitPoint returns the index of the first colon or full stop that is
+// followed by whitespace in the given message, or -1 if there is none.
+func firstSplitPoint(msg string) int {
+	for i := 0; i < len(msg)-1; i++ {
+		switch msg[i] {
+		case ':', '.':
+			if next := msg[i+1]; next == ' ' || next == '\n' || next == '\t' {
+				return i
+			}
+		}
+	}
+	return -1
+}
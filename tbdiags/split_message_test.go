@@ -0,0 +1,67 @@
+package tbdiags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSplitMessage(t *testing.T) {
+	tests := map[string]struct {
+		Msg             string
+		MaxLen          int
+		Summary, Detail string
+	}{
+		"short": {
+			"file not found",
+			20,
+			"file not found", "",
+		},
+		"disabled": {
+			"failed to load module: file not found",
+			0,
+			"failed to load module: file not found", "",
+		},
+		"colon": {
+			"failed to load module: open modules/foo/main.tb: no such file or directory",
+			40,
+			"failed to load module", "open modules/foo/main.tb: no such file or directory",
+		},
+		"sentence": {
+			"The request was rejected. The server is not accepting connections right now.",
+			40,
+			"The request was rejected", "The server is not accepting connections right now.",
+		},
+		"truncated": {
+			"a very long message without any good place to split it at all",
+			20,
+			"a very long…", "a very long message without any good place to split it at all",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			summary, detail := SplitMessage(test.Msg, test.MaxLen)
+			if summary != test.Summary {
+				t.Errorf("wrong summary\ngot:  %q\nwant: %q", summary, test.Summary)
+			}
+			if detail != test.Detail {
+				t.Errorf("wrong detail\ngot:  %q\nwant: %q", detail, test.Detail)
+			}
+		})
+	}
+}
+
+func TestAppendLongError(t *testing.T) {
+	defer func(old int) { MaxErrorSummaryLen = old }(MaxErrorSummaryLen)
+	MaxErrorSummaryLen = 30
+
+	var diags Diagnostics
+	diags = diags.Append(errors.New("failed to install provider: checksum mismatch for the downloaded package"))
+	desc := diags[0].Description()
+	if got, want := desc.Summary, "failed to install provider"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+	if got, want := desc.Detail, "checksum mismatch for the downloaded package"; got != want {
+		t.Errorf("wrong detail %q; want %q", got, want)
+	}
+}
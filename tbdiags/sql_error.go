@@ -0,0 +1,186 @@
+package tbdiags
+
+import (
+	"bufio"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// QueryError converts an error returned by database/sql or a database driver
+// for the given query into diagnostics, so that query-validation tools can
+// point at the part of the query that caused the problem. The filename is
+// used as the Filename of any source ranges, such as "report.sql" or
+// "<query>". QueryError returns nil if err is nil.
+//
+// QueryError recognizes the error types of drivers by the names of their
+// fields, to avoid depending on any particular driver:
+//
+//   - "Message", "Detail" and "Hint" string fields, as in the PostgreSQL
+//     drivers lib/pq and pgx, give the summary and detail of the
+//     diagnostic.
+//   - A "Position" field, either an integer or a string containing one,
+//     gives the one-based character offset in the query where the problem
+//     was found, which becomes the diagnostic's subject.
+//   - A "Code" string field gives the SQLSTATE code, which is noted in the
+//     detail.
+//
+// Otherwise, a message ending "at line N", as MySQL reports syntax errors,
+// gives the line of the query that the problem is on. The detail of a
+// diagnostic with a subject includes the relevant line of the query,
+// because the query is usually not a file that a renderer can show.
+func QueryError(err error, query, filename string) Diagnostics {
+	if err == nil {
+		return nil
+	}
+
+	fields := driverErrorFields(err)
+	summary, detail := SplitMessage(FormatError(err), MaxErrorSummaryLen)
+	if msg := fields["Message"]; msg != "" {
+		summary, detail = msg, ""
+	}
+	var extra []string
+	if s := fields["Detail"]; s != "" {
+		extra = append(extra, s)
+	}
+	if s := fields["Hint"]; s != "" {
+		extra = append(extra, "Hint: "+s)
+	}
+	if s := fields["Code"]; s != "" {
+		extra = append(extra, "SQLSTATE "+s)
+	}
+	if len(extra) > 0 {
+		if detail != "" {
+			detail += "\n\n"
+		}
+		detail += strings.Join(extra, "\n")
+	}
+
+	base := diagnosticBase{
+		severity: Error,
+		summary:  summary,
+		detail:   detail,
+	}
+	var subject *SourceRange
+	if pos, convErr := strconv.Atoi(fields["Position"]); convErr == nil && pos > 0 {
+		subject = queryPositionRange(query, filename, pos)
+	} else if m := queryLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		rng := LineRange(filename, line)
+		subject = &rng
+	}
+	if subject == nil {
+		return Diagnostics{base}
+	}
+
+	var snippet strings.Builder
+	w := bufio.NewWriter(&snippet)
+	writeSnippet(w, subject, []byte(query), "")
+	w.Flush()
+	if snippet.Len() > 0 {
+		if base.detail != "" {
+			base.detail += "\n"
+		}
+		base.detail += "The problem is here in the query:\n" + strings.TrimPrefix(snippet.String(), "\n")
+		base.detail = strings.TrimSuffix(base.detail, "\n")
+	}
+	return Diagnostics{sourcedDiagnostic{
+		diagnosticBase: base,
+		subject:        subject,
+	}}
+}
+
+// queryLinePattern matches the end of MySQL's syntax error messages, which
+// give only the line of the query that the problem is on.
+var queryLinePattern = regexp.MustCompile(`at line (\d+)$`)
+
+// driverErrorFields returns the recognized fields of the first error in the
+// given error's chain that has any, as strings.
+func driverErrorFields(err error) map[string]string {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				break
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		ret := make(map[string]string)
+		for _, name := range []string{"Message", "Detail", "Hint", "Code", "Position"} {
+			f := v.FieldByName(name)
+			switch {
+			case !f.IsValid():
+			case f.Kind() == reflect.String:
+				ret[name] = f.String()
+			case name != "Position":
+			case f.Kind() >= reflect.Int && f.Kind() <= reflect.Int64:
+				ret[name] = strconv.FormatInt(f.Int(), 10)
+			case f.Kind() >= reflect.Uint && f.Kind() <= reflect.Uint64:
+				ret[name] = strconv.FormatUint(f.Uint(), 10)
+			}
+		}
+		if ret["Message"] != "" || ret["Position"] != "" {
+			return ret
+		}
+	}
+	return nil
+}
+
+// queryPositionRange returns the range of the token that starts at the
+// given one-based character offset in the query, or nil if the offset is
+// beyond the end of the query.
+func queryPositionRange(query, filename string, pos int) *SourceRange {
+	start := SourcePos{Line: 1, Column: 1}
+	found := false
+	chars := 0
+	for i, r := range query {
+		if chars == pos-1 {
+			start.Byte = i
+			found = true
+			break
+		}
+		chars++
+		if r == '\n' {
+			start.Line++
+			start.Column = 1
+		} else {
+			start.Column++
+		}
+	}
+	if !found {
+		return nil
+	}
+
+	// The range covers the whole word at the position, such as a table
+	// name, or just the single character there if it's not part of a word.
+	end := start
+	word := isQueryWordChar(firstRune(query[start.Byte:]))
+	for _, r := range query[start.Byte:] {
+		if r == '\n' || (end.Byte > start.Byte && (!word || !isQueryWordChar(r))) {
+			break
+		}
+		end.Byte += utf8.RuneLen(r)
+		end.Column++
+	}
+	return &SourceRange{
+		Filename: filename,
+		Start:    start,
+		End:      end,
+	}
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func isQueryWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
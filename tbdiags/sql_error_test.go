@@ -0,0 +1,96 @@
+package tbdiags
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// pqError has the shape of the error type of the lib/pq PostgreSQL driver,
+// whose position is a string.
+type pqError struct {
+	Severity string
+	Code     string
+	Message  string
+	Detail   string
+	Hint     string
+	Position string
+}
+
+func (e *pqError) Error() string {
+	return "pq: " + e.Message
+}
+
+// pgError has the shape of the error type of the pgx PostgreSQL driver,
+// whose position is an integer.
+type pgError struct {
+	Code     string
+	Message  string
+	Position int32
+}
+
+func (e *pgError) Error() string {
+	return e.Message
+}
+
+func TestQueryError(t *testing.T) {
+	query := "SELECT id\nFROM usres\nWHERE id = 1"
+
+	tests := map[string]struct {
+		Err     error
+		Summary string
+		Detail  string
+		Subject string
+	}{
+		"lib/pq": {
+			&pqError{Code: "42P01", Message: `relation "usres" does not exist`, Hint: "Check the table name.", Position: "16"},
+			`relation "usres" does not exist`,
+			"Hint: Check the table name.\nSQLSTATE 42P01\nThe problem is here in the query:\n     2: FROM usres\n             ^^^^^",
+			"query.sql:2,6",
+		},
+		"pgx wrapped": {
+			fmt.Errorf("running report: %w", &pgError{Message: "syntax error at or near \"=\"", Position: 31}),
+			`syntax error at or near "="`,
+			"The problem is here in the query:\n     3: WHERE id = 1\n                 ^",
+			"query.sql:3,10",
+		},
+		"mysql": {
+			errors.New("Error 1064: You have an error in your SQL syntax near 'usres' at line 2"),
+			"Error 1064: You have an error in your SQL syntax near 'usres' at line 2",
+			"The problem is here in the query:\n     2: FROM usres",
+			"query.sql:2",
+		},
+		"no position": {
+			errors.New("connection refused"),
+			"connection refused",
+			"",
+			"",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			diags := QueryError(test.Err, query, "query.sql")
+			if len(diags) != 1 {
+				t.Fatalf("got %d diagnostics, want 1", len(diags))
+			}
+			desc := diags[0].Description()
+			if desc.Summary != test.Summary {
+				t.Errorf("wrong summary\ngot:  %s\nwant: %s", desc.Summary, test.Summary)
+			}
+			if desc.Detail != test.Detail {
+				t.Errorf("wrong detail\ngot:\n%s\nwant:\n%s", desc.Detail, test.Detail)
+			}
+			var subject string
+			if rng := subjectOf(diags[0]); rng != nil {
+				subject = rng.StartString()
+			}
+			if subject != test.Subject {
+				t.Errorf("wrong subject\ngot:  %s\nwant: %s", subject, test.Subject)
+			}
+		})
+	}
+
+	if diags := QueryError(nil, query, "query.sql"); diags != nil {
+		t.Errorf("unexpected diagnostics for nil error")
+	}
+}
@@ -0,0 +1,75 @@
+package tbdiags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	uuidPattern     = regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`)
+	hostPortPattern = regexp.MustCompile(`((?:\d{1,3}\.){3}\d{1,3}|localhost|\[[0-9a-fA-F:]+\]):\d{1,5}\b`)
+	durationPattern = regexp.MustCompile(`\b(?:\d+(?:\.\d+)?(?:ns|us|µs|ms|s|m|h))+\b`)
+	tempPathPattern = regexp.MustCompile(tempPathRegexp())
+)
+
+func tempPathRegexp() string {
+	dirs := []string{"/tmp", "/var/folders", filepath.ToSlash(os.TempDir())}
+	for i, dir := range dirs {
+		dirs[i] = regexp.QuoteMeta(strings.TrimSuffix(dir, "/"))
+	}
+	return `(?:` + strings.Join(dirs, "|") + `)/[^\s:"']*`
+}
+
+// StableMessage returns the given message with volatile tokens that are
+// likely to differ between runs, such as temporary file paths, network
+// ports, UUIDs and durations, replaced by fixed placeholders. The result is
+// for matching messages against each other, not for display.
+func StableMessage(msg string) string {
+	msg = uuidPattern.ReplaceAllString(msg, "<uuid>")
+	msg = tempPathPattern.ReplaceAllString(msg, "<tmp>")
+	msg = hostPortPattern.ReplaceAllString(msg, "$1:<port>")
+	msg = durationPattern.ReplaceAllString(msg, "<duration>")
+	return msg
+}
+
+// MatchKey returns a string that identifies the given diagnostic for the
+// purposes of recognizing the same problem reported more than once, such as
+// when deduplicating or comparing against a baseline.
+//
+// For diagnostics created from native errors, the summary and detail are
+// normalized using StableMessage, because such messages often include
+// details that vary between runs. The original text is still used for
+// display.
+func MatchKey(diag Diagnostic) string {
+	desc := diag.Description()
+	summary, detail := desc.Summary, desc.Detail
+	if CauseOf(diag) != nil {
+		summary = StableMessage(summary)
+		detail = StableMessage(detail)
+	}
+
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%s", diag.Severity(), OriginOf(diag), desc.Address, summary, detail)
+	if subject := diag.Source().Subject; subject != nil {
+		key += fmt.Sprintf("\x00%d:%s:%d:%d", subject.Kind, subject.Filename, subject.Start.Line, subject.Start.Byte)
+	}
+	return key
+}
+
+// Deduplicate returns a copy of the receiver without any diagnostics that
+// have the same MatchKey as an earlier diagnostic.
+func (diags Diagnostics) Deduplicate() Diagnostics {
+	var ret Diagnostics
+	seen := make(map[string]bool)
+	for _, diag := range diags {
+		key := MatchKey(diag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		ret = append(ret, diag)
+	}
+	return ret
+}
@@ -0,0 +1,48 @@
+package tbdiags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStableMessage(t *testing.T) {
+	tests := map[string]string{
+		"dial tcp 127.0.0.1:54321: connection refused":        "dial tcp 127.0.0.1:<port>: connection refused",
+		"request 3f2b8c1e-9d4a-4b6e-8f0a-1c2d3e4f5a6b failed": "request <uuid> failed",
+		"timed out after 1m30.5s":                             "timed out after <duration>",
+		"open /tmp/tbcheck123456/main.tb: permission denied":  "open <tmp>: permission denied",
+		"nothing volatile here":                               "nothing volatile here",
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			if got := StableMessage(input); got != want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsDeduplicate(t *testing.T) {
+	var diags Diagnostics
+	diags = diags.Append(
+		errors.New("dial tcp 127.0.0.1:54321: connection refused"),
+		errors.New("dial tcp 127.0.0.1:54399: connection refused"),
+		Sourceless(Warning, "Port 8080 is in use", ""),
+		Sourceless(Warning, "Port 8081 is in use", ""),
+	)
+
+	got := diags.Deduplicate()
+	if len(got) != 3 {
+		t.Fatalf("wrong number of diagnostics %d; want 3", len(got))
+	}
+	if got, want := got[0].Description().Summary, "dial tcp 127.0.0.1:54321: connection refused"; got != want {
+		t.Errorf("wrong summary %q; want %q", got, want)
+	}
+
+	// Errors are still recognized after being wrapped.
+	wrapped := Diagnostics{WithOrigin(diags[0], "dialer"), WithOrigin(diags[1], "dialer")}
+	if got := len(wrapped.Deduplicate()); got != 1 {
+		t.Errorf("wrong number of wrapped diagnostics %d; want 1", got)
+	}
+}
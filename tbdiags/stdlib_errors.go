@@ -0,0 +1,11 @@
+//go:build tbdiags_stdliberrors
+// +build tbdiags_stdliberrors
+
+package tbdiags
+
+// appendLegacyError is the tbdiags_stdliberrors version of the adapter for
+// the hashicorp/go-multierror and hashicorp/errwrap packages, which
+// recognizes no errors so that those packages are not dependencies.
+func appendLegacyError(diags Diagnostics, err error) (Diagnostics, bool) {
+	return diags, false
+}
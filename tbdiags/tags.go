@@ -0,0 +1,121 @@
+package tbdiags
+
+// Tag is a semantic label for a diagnostic that renderers and filters can
+// act on without understanding the diagnostic itself, like the diagnostic
+// tags of the Language Server Protocol.
+type Tag int
+
+const (
+	// TagUnnecessary marks diagnostics about code that is unused or has no
+	// effect, which editors typically render faded out.
+	TagUnnecessary Tag = iota + 1
+
+	// TagDeprecated marks diagnostics about uses of deprecated features,
+	// which editors typically render struck through.
+	TagDeprecated
+
+	// TagExperimental marks diagnostics about uses of features that are
+	// not yet covered by compatibility promises.
+	TagExperimental
+)
+
+// String returns the name of the tag, such as "unnecessary".
+func (t Tag) String() string {
+	switch t {
+	case TagUnnecessary:
+		return "unnecessary"
+	case TagDeprecated:
+		return "deprecated"
+	case TagExperimental:
+		return "experimental"
+	default:
+		return ""
+	}
+}
+
+// DiagnosticTags is an optional interface implemented by diagnostics that
+// are labeled with tags. Diagnostics.MarshalJSON encodes the names of the
+// tags, such as "unnecessary", as the "tags" property.
+type DiagnosticTags interface {
+	Tags() []Tag
+}
+
+// WithTags returns a diagnostic that is the same as the given diagnostic
+// except that it also implements DiagnosticTags, returning the given tags
+// after any that the given diagnostic already has. Each tag appears only
+// once in the result.
+func WithTags(diag Diagnostic, tags ...Tag) Diagnostic {
+	all := make([]Tag, 0, len(tags))
+	all = append(all, TagsOf(diag)...)
+	for _, tag := range tags {
+		if !containsTag(all, tag) {
+			all = append(all, tag)
+		}
+	}
+	return withTags{diag, all}
+}
+
+func containsTag(tags []Tag, tag Tag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TagsOf returns the tags of the given diagnostic, if it implements
+// DiagnosticTags, or nil otherwise.
+func TagsOf(diag Diagnostic) []Tag {
+	var ret []Tag
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		t, ok := diag.(DiagnosticTags)
+		if ok {
+			ret = t.Tags()
+		}
+		return ok
+	})
+	return ret
+}
+
+// HasTag returns true if the given diagnostic is labeled with the given tag.
+func HasTag(diag Diagnostic, tag Tag) bool {
+	return containsTag(TagsOf(diag), tag)
+}
+
+// Tagged returns the subset of the receiver that is labeled with the given
+// tag, preserving their order.
+func (diags Diagnostics) Tagged(tag Tag) Diagnostics {
+	var ret Diagnostics
+	for _, diag := range diags {
+		if HasTag(diag, tag) {
+			ret = append(ret, diag)
+		}
+	}
+	return ret
+}
+
+// tagNames returns the names of the given tags, or nil if there are none.
+func tagNames(tags []Tag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	ret := make([]string, len(tags))
+	for i, tag := range tags {
+		ret[i] = tag.String()
+	}
+	return ret
+}
+
+type withTags struct {
+	Diagnostic
+	tags []Tag
+}
+
+func (d withTags) Tags() []Tag {
+	return d.tags[:len(d.tags):len(d.tags)]
+}
+
+func (d withTags) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
@@ -0,0 +1,48 @@
+package tbdiags
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	rng := SourceRange{
+		Filename: "main.tb",
+		Start:    SourcePos{Line: 1, Column: 5, Byte: 4},
+		End:      SourcePos{Line: 1, Column: 8, Byte: 7},
+	}
+	unused := WithTags(sourcedDiagnostic{
+		diagnosticBase: diagnosticBase{severity: Hint, summary: "Unused variable"},
+		subject:        &rng,
+	}, TagUnnecessary)
+	old := WithTags(WithTags(Sourceless(Warning, "Old feature", ""), TagDeprecated), TagExperimental, TagDeprecated)
+	diags := Diagnostics{unused, old, Sourceless(Error, "Plain", "")}
+
+	if got, want := TagsOf(old), []Tag{TagDeprecated, TagExperimental}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong tags\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got, want := TagsOf(WithTags(Sourceless(Warning, "Old feature", ""), TagDeprecated, TagDeprecated)), []Tag{TagDeprecated}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong tags for duplicate input\ngot:  %#v\nwant: %#v", got, want)
+	}
+	if got := diags.Tagged(TagUnnecessary); len(got) != 1 || got[0].Description().Summary != "Unused variable" {
+		t.Errorf("wrong tagged diagnostics %#v", got)
+	}
+
+	src, err := diags.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), `"tags":["deprecated","experimental"]`) {
+		t.Errorf("tags missing from JSON: %s", src)
+	}
+
+	r := &Renderer{
+		Color:   true,
+		Sources: map[string][]byte{"main.tb": []byte("let abc = 1\n")},
+	}
+	got := r.RenderString(Diagnostics{unused})
+	if want := "let " + ansiFaint + "abc" + ansiReset + " = 1"; !strings.Contains(got, want) {
+		t.Errorf("unnecessary code not faded\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package tbdiags
+
+// Theme is the set of ANSI terminal escape sequences that Renderer uses to
+// highlight the severity and summary of each diagnostic when Color is set.
+//
+// Each field is written before the highlighted text, which is then followed
+// by a sequence that resets all attributes. Users can customize one of the
+// predefined themes by copying it and changing some of its fields.
+type Theme struct {
+	Fatal, Error, Warning, Hint string
+}
+
+// These are the predefined themes, which can also be selected by name using
+// ThemeByName.
+var (
+	// ThemeDefault uses bold red for errors, bold yellow for warnings and
+	// bold cyan for hints, which suit terminals with dark backgrounds.
+	ThemeDefault = Theme{
+		Fatal:   ansiBold + ansiRed,
+		Error:   ansiBold + ansiRed,
+		Warning: ansiBold + ansiYellow,
+		Hint:    ansiBold + ansiCyan,
+	}
+
+	// ThemeLightTerminal uses darker colors that remain legible on
+	// terminals with light backgrounds.
+	ThemeLightTerminal = Theme{
+		Fatal:   ansiBold + "\x1b[38;5;124m",
+		Error:   ansiBold + "\x1b[38;5;124m",
+		Warning: ansiBold + "\x1b[38;5;130m",
+		Hint:    ansiBold + "\x1b[38;5;25m",
+	}
+
+	// ThemeColorblindSafe uses orange for errors and blue for warnings,
+	// which remain distinguishable with the common forms of color vision
+	// deficiency, unlike red and yellow or red and green.
+	ThemeColorblindSafe = Theme{
+		Fatal:   ansiBold + "\x1b[4m\x1b[38;5;208m",
+		Error:   ansiBold + "\x1b[38;5;208m",
+		Warning: ansiBold + "\x1b[38;5;39m",
+		Hint:    ansiBold + "\x1b[38;5;250m",
+	}
+
+	// ThemeMonochromeBold uses no colors, distinguishing severities using
+	// only bold and underlined text.
+	ThemeMonochromeBold = Theme{
+		Fatal:   ansiBold + "\x1b[4m",
+		Error:   ansiBold + "\x1b[4m",
+		Warning: ansiBold,
+		Hint:    "",
+	}
+)
+
+// ThemeByName returns the predefined theme with the given name, which is one
+// of "default", "light-terminal", "colorblind-safe" or "monochrome-bold", or
+// false if there is no such theme.
+func ThemeByName(name string) (Theme, bool) {
+	switch name {
+	case "default":
+		return ThemeDefault, true
+	case "light-terminal":
+		return ThemeLightTerminal, true
+	case "colorblind-safe":
+		return ThemeColorblindSafe, true
+	case "monochrome-bold":
+		return ThemeMonochromeBold, true
+	default:
+		return Theme{}, false
+	}
+}
+
+// forSeverity returns the escape sequence for the given severity.
+func (t Theme) forSeverity(sev Severity) string {
+	switch sev {
+	case Fatal:
+		return t.Fatal
+	case Error:
+		return t.Error
+	case Hint:
+		return t.Hint
+	default:
+		return t.Warning
+	}
+}
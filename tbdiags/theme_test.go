@@ -0,0 +1,46 @@
+package tbdiags
+
+import (
+	"testing"
+)
+
+func TestRendererTheme(t *testing.T) {
+	diags := Diagnostics{Sourceless(Warning, "Dubious thing", "")}
+
+	tests := map[string]struct {
+		Theme *Theme
+		Want  string
+	}{
+		"default": {
+			nil,
+			"\x1b[1m\x1b[33mWarning: Dubious thing\x1b[0m\n\n",
+		},
+		"monochrome-bold": {
+			&ThemeMonochromeBold,
+			"\x1b[1mWarning: Dubious thing\x1b[0m\n\n",
+		},
+		"custom": {
+			&Theme{Warning: "<w>"},
+			"<w>Warning: Dubious thing\x1b[0m\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := (&Renderer{Color: true, Theme: test.Theme}).RenderString(diags)
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestThemeByName(t *testing.T) {
+	for _, name := range []string{"default", "light-terminal", "colorblind-safe", "monochrome-bold"} {
+		if _, ok := ThemeByName(name); !ok {
+			t.Errorf("no theme named %q", name)
+		}
+	}
+	if _, ok := ThemeByName("neon"); ok {
+		t.Errorf("found a theme that doesn't exist")
+	}
+}
@@ -0,0 +1,113 @@
+package tbdiags
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Thresholds describes the maximum number of diagnostics of various kinds
+// that a policy will tolerate, such as for a CI check that allows up to ten
+// warnings but no errors.
+type Thresholds struct {
+	// Severity maps severities to the maximum number of diagnostics of
+	// that severity that are allowed. Severities that are not present in
+	// the map are not limited.
+	Severity map[Severity]int
+
+	// Code maps diagnostic codes to the maximum number of diagnostics with
+	// that code that are allowed, such as to tolerate a known backlog of
+	// one kind of problem without tolerating any new kinds. A code
+	// qualified by an origin, such as "linter/E102", counts only the
+	// diagnostics from that origin, while a bare code counts those from
+	// any origin. Codes that are not present in the map are not limited.
+	Code map[string]int
+
+	// Tag maps tags to the maximum number of diagnostics labeled with that
+	// tag that are allowed, such as to allow no more uses of deprecated
+	// features than there are today. Tags that are not present in the map
+	// are not limited.
+	Tag map[Tag]int
+}
+
+// ExceedsThresholds checks the receiver against the given thresholds,
+// returning true if any of them are exceeded along with error diagnostics
+// that explain which ones, suitable for reporting to the user. The
+// explanations are for severities first, most severe first, then for codes
+// and then for tags.
+func (diags Diagnostics) ExceedsThresholds(t Thresholds) (bool, Diagnostics) {
+	severityCounts := make(map[Severity]int)
+	codeCounts := make(map[string]int)
+	tagCounts := make(map[Tag]int)
+	for _, diag := range diags {
+		severityCounts[diag.Severity()]++
+		for code := range t.Code {
+			if matchesCode(diag, code) {
+				codeCounts[code]++
+			}
+		}
+		for _, tag := range TagsOf(diag) {
+			tagCounts[tag]++
+		}
+	}
+
+	// We'll check the thresholds in a consistent order so that the result
+	// is deterministic.
+	severities := make([]Severity, 0, len(t.Severity))
+	for sev := range t.Severity {
+		severities = append(severities, sev)
+	}
+	sort.Slice(severities, func(i, j int) bool {
+		if a, b := severities[i].Level(), severities[j].Level(); a != b {
+			return a > b
+		}
+		return severities[i] < severities[j]
+	})
+	codes := make([]string, 0, len(t.Code))
+	for code := range t.Code {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	tags := make([]Tag, 0, len(t.Tag))
+	for tag := range t.Tag {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i] < tags[j]
+	})
+
+	var ret Diagnostics
+	for _, sev := range severities {
+		max, count := t.Severity[sev], severityCounts[sev]
+		if count <= max {
+			continue
+		}
+		ret = ret.Append(Sourceless(
+			Error,
+			fmt.Sprintf("Too many %s diagnostics", sev),
+			fmt.Sprintf("Found %s with severity %s, but the limit is %d.", FormatCount(count, NounDiagnostic), sev, max),
+		))
+	}
+	for _, code := range codes {
+		max, count := t.Code[code], codeCounts[code]
+		if count <= max {
+			continue
+		}
+		ret = ret.Append(Sourceless(
+			Error,
+			fmt.Sprintf("Too many %s diagnostics", code),
+			fmt.Sprintf("Found %s with code %s, but the limit is %d.", FormatCount(count, NounDiagnostic), code, max),
+		))
+	}
+	for _, tag := range tags {
+		max, count := t.Tag[tag], tagCounts[tag]
+		if count <= max {
+			continue
+		}
+		ret = ret.Append(Sourceless(
+			Error,
+			fmt.Sprintf("Too many %s diagnostics", tag),
+			fmt.Sprintf("Found %s tagged %s, but the limit is %d.", FormatCount(count, NounDiagnostic), tag, max),
+		))
+	}
+	return len(ret) > 0, ret
+}
@@ -0,0 +1,93 @@
+package tbdiags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticsExceedsThresholds(t *testing.T) {
+	diags := Diagnostics{
+		Sourceless(Warning, "First warning", ""),
+		Sourceless(Warning, "Second warning", ""),
+		Sourceless(Error, "Only error", ""),
+	}
+
+	t.Run("within", func(t *testing.T) {
+		exceeded, explain := diags.ExceedsThresholds(Thresholds{
+			Severity: map[Severity]int{
+				Warning: 2,
+				Error:   1,
+			},
+		})
+		if exceeded {
+			t.Errorf("thresholds exceeded: %s", explain.Err())
+		}
+	})
+	t.Run("exceeded", func(t *testing.T) {
+		exceeded, explain := diags.ExceedsThresholds(Thresholds{
+			Severity: map[Severity]int{
+				Warning: 1,
+			},
+		})
+		if !exceeded {
+			t.Fatalf("thresholds not exceeded")
+		}
+		want := "Too many Warning diagnostics: Found 2 diagnostics with severity Warning, but the limit is 1."
+		if got := explain.Err().Error(); got != want {
+			t.Errorf("wrong explanation\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("most severe first", func(t *testing.T) {
+		_, explain := diags.ExceedsThresholds(Thresholds{
+			Severity: map[Severity]int{
+				Warning: 0,
+				Error:   0,
+			},
+		})
+		var got []string
+		for _, diag := range explain {
+			got = append(got, diag.Description().Summary)
+		}
+		if want := []string{"Too many Error diagnostics", "Too many Warning diagnostics"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("wrong explanations\ngot:  %#v\nwant: %#v", got, want)
+		}
+	})
+	t.Run("codes and tags", func(t *testing.T) {
+		diags := Diagnostics{
+			WithCode(Sourceless(Warning, "Old syntax", ""), "TB1001"),
+			WithTags(WithCode(Sourceless(Warning, "Old syntax", ""), "TB1001"), TagDeprecated),
+			WithTags(Sourceless(Warning, "Old function", ""), TagDeprecated),
+			WithCode(Sourceless(Warning, "Unused variable", ""), "TB2001"),
+		}
+		exceeded, explain := diags.ExceedsThresholds(Thresholds{
+			Code: map[string]int{"TB1001": 1, "TB2001": 1},
+			Tag:  map[Tag]int{TagDeprecated: 1, TagUnnecessary: 0},
+		})
+		if !exceeded {
+			t.Fatalf("thresholds not exceeded")
+		}
+		want := "2 problems:\n\n" +
+			"- Too many TB1001 diagnostics: Found 2 diagnostics with code TB1001, but the limit is 1.\n" +
+			"- Too many deprecated diagnostics: Found 2 diagnostics tagged deprecated, but the limit is 1."
+		if got := explain.Err().Error(); got != want {
+			t.Errorf("wrong explanation\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+	t.Run("codes from origins", func(t *testing.T) {
+		diags := Diagnostics{
+			WithOrigin(WithCode(Sourceless(Warning, "Unused import", ""), "E102"), "linter"),
+			WithOrigin(WithCode(Sourceless(Warning, "Replacing resource", ""), "E102"), "planner"),
+			WithOrigin(WithCode(Sourceless(Warning, "Replacing resource", ""), "E102"), "planner"),
+		}
+		exceeded, explain := diags.ExceedsThresholds(Thresholds{
+			Code: map[string]int{"linter/E102": 1, "planner/E102": 1, "E102": 3},
+		})
+		if !exceeded {
+			t.Fatalf("thresholds not exceeded")
+		}
+		want := "Too many planner/E102 diagnostics: Found 2 diagnostics with code planner/E102, but the limit is 1."
+		if got := explain.Err().Error(); got != want {
+			t.Errorf("wrong explanation\ngot:  %s\nwant: %s", got, want)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package tbdiags
+
+import (
+	"time"
+)
+
+// DiagnosticTimestamp is an optional interface implemented by diagnostics
+// that record when they were produced, for long-running programs where
+// that's not obvious from the context in which they're reported.
+// Diagnostics.MarshalJSON encodes the time as the "timestamp" property, in
+// RFC 3339 format.
+type DiagnosticTimestamp interface {
+	Timestamp() time.Time
+}
+
+// WithTimestamp returns a diagnostic that is the same as the given
+// diagnostic except that it also implements DiagnosticTimestamp, returning
+// the given time.
+func WithTimestamp(diag Diagnostic, t time.Time) Diagnostic {
+	return withTimestamp{diag, t}
+}
+
+// Timestamped returns the given diagnostic with the current time as its
+// timestamp, as for WithTimestamp.
+func Timestamped(diag Diagnostic) Diagnostic {
+	return WithTimestamp(diag, time.Now())
+}
+
+// TimestampOf returns the time that the given diagnostic was produced, or
+// false if it doesn't implement DiagnosticTimestamp.
+func TimestampOf(diag Diagnostic) (time.Time, bool) {
+	var ret time.Time
+	found := findDiagnostic(diag, func(diag Diagnostic) bool {
+		ts, ok := diag.(DiagnosticTimestamp)
+		if ok {
+			ret = ts.Timestamp()
+		}
+		return ok
+	})
+	return ret, found
+}
+
+type withTimestamp struct {
+	Diagnostic
+	timestamp time.Time
+}
+
+func (d withTimestamp) Timestamp() time.Time {
+	return d.timestamp
+}
+
+func (d withTimestamp) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
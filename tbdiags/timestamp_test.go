@@ -0,0 +1,45 @@
+package tbdiags
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimestamps(t *testing.T) {
+	at := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	diag := WithTimestamp(Sourceless(Warning, "Slow response", ""), at)
+
+	if got, ok := TimestampOf(diag); !ok || !got.Equal(at) {
+		t.Errorf("wrong timestamp %s", got)
+	}
+	if _, ok := TimestampOf(Sourceless(Warning, "Other", "")); ok {
+		t.Errorf("found a timestamp on a diagnostic without one")
+	}
+
+	src, err := Diagnostics{diag}.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), `"timestamp":"2024-03-01T09:30:00Z"`) {
+		t.Errorf("timestamp missing from JSON: %s", src)
+	}
+
+	got := withoutReportedAt((&Renderer{Verbose: true}).RenderString(Diagnostics{diag}))
+	if want := "Warning: Slow response\n  (produced at 2024-03-01T09:30:00Z)\n\n"; got != want {
+		t.Errorf("wrong rendering\ngot:  %q\nwant: %q", got, want)
+	}
+
+	c := &Collector{
+		Timestamp: true,
+		now:       func() time.Time { return at.Add(time.Hour) },
+	}
+	c.Report(Diagnostics{diag, Sourceless(Error, "Failed", "")})
+	collected := c.Diagnostics()
+	if got, _ := TimestampOf(collected[0]); !got.Equal(at) {
+		t.Errorf("collector replaced an existing timestamp with %s", got)
+	}
+	if got, _ := TimestampOf(collected[1]); !got.Equal(at.Add(time.Hour)) {
+		t.Errorf("wrong collector timestamp %s", got)
+	}
+}
@@ -0,0 +1,93 @@
+package tbdiags
+
+import (
+	"sort"
+	"strings"
+)
+
+// DiagnosticValidValues is an optional interface implemented by diagnostics
+// that can list the valid alternatives to an invalid value, such as the
+// allowed values of an enumeration. Diagnostics.MarshalJSON encodes the
+// values as the "valid_values" property, an array of strings.
+type DiagnosticValidValues interface {
+	ValidValues() []string
+}
+
+// WithValidValues returns a diagnostic that is the same as the given
+// diagnostic except that it also implements DiagnosticValidValues,
+// returning the given values.
+//
+// Producers should use this rather than listing the values in the detail,
+// so that renderers can present the list consistently and machine-readable
+// formats can include it as structured data.
+func WithValidValues(diag Diagnostic, values []string) Diagnostic {
+	return withValidValues{diag, values}
+}
+
+// ValidValues returns the valid values attached to the given diagnostic, or
+// nil if it doesn't implement DiagnosticValidValues.
+func ValidValues(diag Diagnostic) []string {
+	var ret []string
+	findDiagnostic(diag, func(diag Diagnostic) bool {
+		vv, ok := diag.(DiagnosticValidValues)
+		if ok {
+			ret = vv.ValidValues()
+		}
+		return ok
+	})
+	return ret
+}
+
+type withValidValues struct {
+	Diagnostic
+	values []string
+}
+
+func (d withValidValues) ValidValues() []string {
+	return d.values
+}
+
+func (d withValidValues) wrappedDiagnostic() Diagnostic {
+	return d.Diagnostic
+}
+
+// formatValidValues renders a sorted list of valid values, wrapped to the
+// given width and truncated after the given number of values.
+func formatValidValues(values []string, width, max int) string {
+	sorted := make([]string, len(values))
+	copy(sorted, values)
+	sort.Strings(sorted)
+
+	var more int
+	if max > 0 && len(sorted) > max {
+		more = len(sorted) - max
+		sorted = sorted[:max]
+	}
+
+	var buf strings.Builder
+	lineLen := 0
+	write := func(word string) {
+		if lineLen > 0 && lineLen+1+len(word) > width {
+			buf.WriteString("\n  ")
+			lineLen = 2
+		} else if lineLen > 0 {
+			buf.WriteByte(' ')
+			lineLen++
+		}
+		buf.WriteString(word)
+		lineLen += len(word)
+	}
+
+	write("Valid values:")
+	for i, value := range sorted {
+		if i < len(sorted)-1 || more > 0 {
+			value += ","
+		}
+		write(value)
+	}
+	if more > 0 {
+		write("and")
+		write(FormatCount(more, NounOther))
+	}
+	return buf.String()
+}
@@ -0,0 +1,61 @@
+package tbdiags
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFormatValidValues(t *testing.T) {
+	tests := map[string]struct {
+		Values     []string
+		Width, Max int
+		Want       string
+	}{
+		"short": {
+			[]string{"b", "c", "a"},
+			78, 20,
+			"Valid values: a, b, c",
+		},
+		"wrapped": {
+			[]string{"alpha", "bravo", "charlie", "delta"},
+			30, 20,
+			"Valid values: alpha, bravo,\n  charlie, delta",
+		},
+		"truncated": {
+			[]string{"d", "c", "b", "a"},
+			78, 2,
+			"Valid values: a, b, and 2 others",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := formatValidValues(test.Values, test.Width, test.Max)
+			if got != test.Want {
+				t.Errorf("wrong result\ngot:  %q\nwant: %q", got, test.Want)
+			}
+		})
+	}
+}
+
+func TestWithValidValuesJSON(t *testing.T) {
+	diags := Diagnostics{
+		WithValidValues(
+			Sourceless(Error, "Invalid mode", `The mode "fast" is not supported.`),
+			[]string{"quick", "thorough"},
+		),
+	}
+
+	got, err := diags.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"severity":"error","summary":"Invalid mode","detail":"The mode \"fast\" is not supported.","valid_values":["quick","thorough"]}]`
+	if withoutReportedAt(string(got)) != want {
+		t.Errorf("wrong result\ngot:  %s\nwant: %s", got, want)
+	}
+
+	if got, want := fmt.Sprint(ValidValues(diags[0])), "[quick thorough]"; got != want {
+		t.Errorf("wrong valid values %s; want %s", got, want)
+	}
+}
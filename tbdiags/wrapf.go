@@ -0,0 +1,29 @@
+package tbdiags
+
+import (
+	"fmt"
+)
+
+// Wrapf returns a copy of the receiver in which the summary of each
+// diagnostic is prefixed with the result of formatting the arguments as for
+// fmt.Sprintf, followed by a colon, in the same way that fmt.Errorf is often
+// used to add context to an error as it's returned up the call stack. For
+// example:
+//
+//	diags = diags.Append(loadModule(path).Wrapf("loading module %s", name))
+//
+// Everything else about the diagnostics, including their severities, source
+// ranges and causes, is unchanged.
+func (diags Diagnostics) Wrapf(format string, args ...interface{}) Diagnostics {
+	if diags == nil {
+		return nil
+	}
+	prefix := fmt.Sprintf(format, args...)
+	ret := make(Diagnostics, len(diags))
+	for i, diag := range diags {
+		desc := diag.Description()
+		desc.Summary = prefix + ": " + desc.Summary
+		ret[i] = overrideDescription{diag, desc}
+	}
+	return ret
+}
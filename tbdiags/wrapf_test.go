@@ -0,0 +1,46 @@
+package tbdiags
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDiagnosticsWrapf(t *testing.T) {
+	cause := errors.New("file not found")
+	rng := SourceRange{Filename: "main.tb", Start: SourcePos{Line: 1, Column: 1}, End: SourcePos{Line: 1, Column: 5, Byte: 4}}
+	diags := Diagnostics{
+		sourcedDiagnostic{
+			diagnosticBase: diagnosticBase{severity: Warning, summary: "Deprecated argument", detail: "Use x instead."},
+			subject:        &rng,
+		},
+	}.Append(cause)
+
+	got := diags.Wrapf("loading module %q", "network")
+	want := []string{
+		`loading module "network": Deprecated argument`,
+		`loading module "network": file not found`,
+	}
+	for i, diag := range got {
+		if got := diag.Description().Summary; got != want[i] {
+			t.Errorf("wrong summary %d\ngot:  %s\nwant: %s", i, got, want[i])
+		}
+		if diag.Severity() != diags[i].Severity() {
+			t.Errorf("diagnostic %d has severity %s; want %s", i, diag.Severity(), diags[i].Severity())
+		}
+		if diag.Source() != diags[i].Source() {
+			t.Errorf("diagnostic %d has a different source", i)
+		}
+	}
+	if got[0].Description().Detail != "Use x instead." {
+		t.Errorf("wrong detail %q", got[0].Description().Detail)
+	}
+	if !errors.Is(CauseOf(got[1]), cause) {
+		t.Errorf("wrapped diagnostic lost its cause")
+	}
+	if diags[0].Description().Summary != "Deprecated argument" {
+		t.Errorf("Wrapf modified the receiver")
+	}
+	if Diagnostics(nil).Wrapf("context") != nil {
+		t.Errorf("Wrapf of nil is not nil")
+	}
+}
@@ -0,0 +1,25 @@
+package tbdiags
+
+// diagnosticWrapper is implemented by the types in this package that wrap
+// another diagnostic to add or override some of its behavior, so that the
+// accessors for the optional diagnostic interfaces can look through them.
+type diagnosticWrapper interface {
+	wrappedDiagnostic() Diagnostic
+}
+
+// findDiagnostic calls fn with the given diagnostic and then with each
+// diagnostic it wraps in turn, stopping and returning true at the first for
+// which fn returns true.
+func findDiagnostic(diag Diagnostic, fn func(Diagnostic) bool) bool {
+	for diag != nil {
+		if fn(diag) {
+			return true
+		}
+		wrapper, ok := diag.(diagnosticWrapper)
+		if !ok {
+			return false
+		}
+		diag = wrapper.wrappedDiagnostic()
+	}
+	return false
+}